@@ -0,0 +1,237 @@
+package access
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// DisableTrigger is a trigger to use for a command that disables a command in a guild.
+const DisableTrigger = "disablecommand"
+
+// EnableTrigger is a trigger to use for a command that enables a disabled command.
+const EnableTrigger = "enablecommand"
+
+// AllowTrigger is a trigger to use for a command that allows a command in the current channel,
+// so that it can only be used in allowed channels.
+const AllowTrigger = "allowcommand"
+
+// DisallowTrigger is a trigger to use for a command that stops allowing a command in the current channel.
+const DisallowTrigger = "disallowcommand"
+
+// RulesTrigger is a trigger to use for a command that lists the rules of a guild.
+const RulesTrigger = "commandrules"
+
+// Triggers are the triggers of the commands returned by Commands. These commands can't be disabled,
+// so that admins can always change the rules.
+var Triggers = []string{DisableTrigger, EnableTrigger, AllowTrigger, DisallowTrigger, RulesTrigger}
+
+// Guard has the commands that let admins change which commands can be used in their guild, and
+// a Filter that enforces it.
+type Guard struct {
+	triggers map[string]bool // Triggers of the bot's commands, which are the only ones rules can be made for.
+	mutex    sync.Mutex      // Lock when reading and then writing rules, or when using triggers.
+}
+
+// SetCommands replaces the commands that admins can make rules for. Rules of commands that
+// were removed are kept, in case they're added back.
+func (g *Guard) SetCommands(commands []command.Command) {
+	triggers := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		triggers[cmd.Trigger] = true
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	g.triggers = triggers
+}
+
+// Commands returns commands that let admins disable and enable commands, allow commands in channels, and list the rules.
+func (g *Guard) Commands() []command.Command {
+	return []command.Command{
+		{
+			Trigger: DisableTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "command", Description: "Trigger of the command to disable"},
+			},
+			Exec:      g.disable,
+			Help:      "Stop a command from being used in this server.",
+			HelpInput: "<command>",
+		},
+		{
+			Trigger: EnableTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "command", Description: "Trigger of the command to enable"},
+			},
+			Exec:      g.enable,
+			Help:      "Let a disabled command be used in this server again.",
+			HelpInput: "<command>",
+		},
+		{
+			Trigger: AllowTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "command", Description: "Trigger of the command, or " + AllCommands},
+			},
+			Exec:      g.allow,
+			Help:      "Allow a command, or " + AllCommands + " commands, in this channel. Once a command is allowed in a channel, it can only be used in allowed channels.",
+			HelpInput: "<command|" + AllCommands + ">",
+		},
+		{
+			Trigger: DisallowTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "command", Description: "Trigger of the command, or " + AllCommands},
+			},
+			Exec:      g.disallow,
+			Help:      "Stop allowing a command, or " + AllCommands + " commands, in this channel. A command that isn't allowed in any channel can be used anywhere.",
+			HelpInput: "<command|" + AllCommands + ">",
+		},
+		{
+			Trigger: RulesTrigger,
+			Exec:    g.list,
+			Help:    "List the commands that are disabled in this server, and the channels commands are allowed in.",
+		},
+	}
+}
+
+// Filter returns an error that explains why the command with trigger can't be used in conversation, or nil if it can.
+// Direct messages, which have no guild, can use every command.
+func (g *Guard) Filter(storage *storage.Storage, conversation service.Conversation, trigger string) error {
+	if conversation.GuildID == "" || slices.Contains(Triggers, trigger) {
+		return nil
+	}
+
+	rules, err := GuildRules(*storage, conversation.Guild())
+	if err != nil {
+		return err
+	}
+	return rules.Check(trigger, conversation.ConversationID)
+}
+
+// change runs update on the rules of the guild of sender, for the command with trigger, then replies with reply.
+// Only admins can change rules, and only for the bot's commands or, when all is true, AllCommands.
+func (g *Guard) change(sender service.Conversation, trigger string, all bool, storage *storage.Storage, sink func(service.Conversation, service.Message) error, update func(*Rules) string) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can change which commands can be used."})
+	}
+
+	if sender.GuildID == "" {
+		return sink(sender, service.Message{Title: "Error", Description: "Commands can only be changed in a server."})
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	switch {
+	case slices.Contains(Triggers, trigger):
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s can't be changed, so admins can always change rules.", trigger)})
+	case !g.triggers[trigger] && !(all && trigger == AllCommands):
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("There's no command %s.", trigger)})
+	}
+
+	rules, err := GuildRules(*storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	reply := update(&rules)
+	if err := SetGuildRules(*storage, sender.Guild(), rules); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Description: reply})
+}
+
+// disable disables a command in the guild of sender.
+func (g *Guard) disable(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	trigger := strings.TrimSpace(msg[0].(string))
+	return g.change(sender, trigger, false, storage, sink, func(rules *Rules) string {
+		if !slices.Contains(rules.Disabled, trigger) {
+			rules.Disabled = append(rules.Disabled, trigger)
+		}
+		return fmt.Sprintf("%s is disabled in this server.", trigger)
+	})
+}
+
+// enable enables a disabled command in the guild of sender.
+func (g *Guard) enable(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	trigger := strings.TrimSpace(msg[0].(string))
+	return g.change(sender, trigger, false, storage, sink, func(rules *Rules) string {
+		rules.Disabled = slices.DeleteFunc(rules.Disabled, func(disabled string) bool { return disabled == trigger })
+		return fmt.Sprintf("%s is enabled in this server.", trigger)
+	})
+}
+
+// allow allows a command in the channel of sender.
+func (g *Guard) allow(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	trigger := strings.TrimSpace(msg[0].(string))
+	return g.change(sender, trigger, true, storage, sink, func(rules *Rules) string {
+		if !slices.Contains(rules.Channels[trigger], sender.ConversationID) {
+			rules.Channels[trigger] = append(rules.Channels[trigger], sender.ConversationID)
+		}
+		return fmt.Sprintf("%s can be used in this channel, and only in channels it's allowed in.", describe(trigger))
+	})
+}
+
+// disallow stops allowing a command in the channel of sender.
+func (g *Guard) disallow(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	trigger := strings.TrimSpace(msg[0].(string))
+	return g.change(sender, trigger, true, storage, sink, func(rules *Rules) string {
+		channels := slices.DeleteFunc(rules.Channels[trigger], func(channel string) bool { return channel == sender.ConversationID })
+		if len(channels) == 0 {
+			delete(rules.Channels, trigger)
+			return fmt.Sprintf("%s isn't allowed in any channel, so it can be used anywhere.", describe(trigger))
+		}
+
+		rules.Channels[trigger] = channels
+		return fmt.Sprintf("%s is no longer allowed in this channel.", describe(trigger))
+	})
+}
+
+// list lists the rules of the guild of sender.
+func (g *Guard) list(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	rules, err := GuildRules(*storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	if len(rules.Disabled) == 0 && len(rules.Channels) == 0 {
+		return sink(sender, service.Message{Title: "Command rules", Description: "Every command can be used in every channel."})
+	}
+
+	message := service.Message{Title: "Command rules"}
+	if len(rules.Disabled) > 0 {
+		disabled := slices.Sorted(slices.Values(rules.Disabled))
+		message.Fields = append(message.Fields, service.MessageField{Field: "Disabled", Value: strings.Join(disabled, ", ")})
+	}
+
+	triggers := make([]string, 0, len(rules.Channels))
+	for trigger := range rules.Channels {
+		triggers = append(triggers, trigger)
+	}
+	sort.Strings(triggers)
+
+	for _, trigger := range triggers {
+		mentions := []string{}
+		for _, channel := range rules.Channels[trigger] {
+			mentions = append(mentions, fmt.Sprintf("<#%s>", channel))
+		}
+		message.Fields = append(message.Fields, service.MessageField{
+			Field: fmt.Sprintf("%s only in", describe(trigger)),
+			Value: strings.Join(mentions, ", "),
+		})
+	}
+	return sink(sender, message)
+}
+
+// describe returns how trigger is written in replies, where AllCommands is every command.
+func describe(trigger string) string {
+	if trigger == AllCommands {
+		return "Every command"
+	}
+	return trigger
+}
@@ -0,0 +1,88 @@
+package access
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// testGuard returns a Guard that can make rules for "render" and "define", and storage for its commands.
+func testGuard() (*Guard, *storage.Storage) {
+	guard := &Guard{}
+	guard.SetCommands(append(guard.Commands(), command.Command{Trigger: "render"}, command.Command{Trigger: "define"}))
+	return guard, commandtest.Storage()
+}
+
+func TestDisableCommand(t *testing.T) {
+	guard, store := testGuard()
+	admin := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "bots", GuildID: "0", Admin: true}
+	member := admin
+	member.Admin = false
+	otherGuild := admin
+	otherGuild.GuildID = "1"
+
+	if reply := commandtest.Reply(t, guard.Commands(), store, member, commandtest.User, DisableTrigger, "render"); !strings.Contains(reply.Description, "Only admins") {
+		t.Errorf("Expected only admins to disable commands, got %+v", reply)
+	}
+	for _, trigger := range []string{"missing", AllCommands, DisableTrigger} {
+		if reply := commandtest.Reply(t, guard.Commands(), store, admin, commandtest.User, DisableTrigger, trigger); reply.Title != "Error" {
+			t.Errorf("Expected %s not to be disabled, got %+v", trigger, reply)
+		}
+	}
+
+	commandtest.Reply(t, guard.Commands(), store, admin, commandtest.User, DisableTrigger, "render")
+	if err := guard.Filter(store, member, "render"); err == nil {
+		t.Errorf("Expected render to be disabled")
+	}
+	if err := guard.Filter(store, otherGuild, "render"); err != nil {
+		t.Errorf("Expected render to only be disabled in its guild, got %v", err)
+	}
+	if err := guard.Filter(store, service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "dm"}, "render"); err != nil {
+		t.Errorf("Expected direct messages to use every command, got %v", err)
+	}
+
+	commandtest.Reply(t, guard.Commands(), store, admin, commandtest.User, EnableTrigger, "render")
+	if err := guard.Filter(store, member, "render"); err != nil {
+		t.Errorf("Expected render to be enabled, got %v", err)
+	}
+}
+
+func TestAllowCommand(t *testing.T) {
+	guard, store := testGuard()
+	bots := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "bots", GuildID: "0", Admin: true}
+	general := bots
+	general.ConversationID = "general"
+
+	commandtest.Reply(t, guard.Commands(), store, bots, commandtest.User, AllowTrigger, AllCommands)
+	commandtest.Reply(t, guard.Commands(), store, general, commandtest.User, AllowTrigger, "define")
+	for _, test := range []struct {
+		conversation service.Conversation
+		trigger      string
+		allowed      bool
+	}{
+		{bots, "render", true},
+		{general, "render", false},
+		{general, "define", true},
+		{bots, "define", false},
+		{general, AllowTrigger, true},
+	} {
+		if err := guard.Filter(store, test.conversation, test.trigger); (err == nil) != test.allowed {
+			t.Errorf("Expected %s in %s to be allowed: %v, got %v", test.trigger, test.conversation.ConversationID, test.allowed, err)
+		}
+	}
+
+	rules := commandtest.Reply(t, guard.Commands(), store, general, commandtest.User, RulesTrigger)
+	if len(rules.Fields) != 2 || rules.Fields[0].Field != "Every command only in" || rules.Fields[1].Value != "<#general>" {
+		t.Errorf("Unexpected rules %+v", rules)
+	}
+
+	commandtest.Reply(t, guard.Commands(), store, general, commandtest.User, DisallowTrigger, "define")
+	if err := guard.Filter(store, bots, "define"); err != nil {
+		t.Errorf("Expected define to use the channels of every command, got %v", err)
+	}
+}
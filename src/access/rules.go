@@ -0,0 +1,64 @@
+// Package access lets a guild's admins disable commands, or only allow them in some channels.
+package access
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// RulesKey is the guild storage key of a guild's rules, stored as JSON.
+const RulesKey = "command_rules"
+
+// AllCommands is used instead of a trigger for channels that every command is allowed in.
+const AllCommands = "all"
+
+// Rules decide which commands can be used in a guild, and in which channels.
+type Rules struct {
+	Disabled []string            // Triggers of commands that can't be used in the guild.
+	Channels map[string][]string // IDs of the only channels a command can be used in, by trigger. Commands without their own channels use those of AllCommands.
+}
+
+// Check returns an error that explains why the command with trigger can't be used in the channel with channelID,
+// or nil if it can.
+func (r Rules) Check(trigger string, channelID string) error {
+	if slices.Contains(r.Disabled, trigger) {
+		return fmt.Errorf("%s is disabled in this server", trigger)
+	}
+
+	channels, ok := r.Channels[trigger]
+	if !ok {
+		channels = r.Channels[AllCommands]
+	}
+
+	if len(channels) == 0 || slices.Contains(channels, channelID) {
+		return nil
+	}
+
+	mentions := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		mentions = append(mentions, fmt.Sprintf("<#%s>", channel))
+	}
+	return fmt.Errorf("%s can only be used in %s", trigger, strings.Join(mentions, ", "))
+}
+
+// GuildRules returns the rules of guild.
+func GuildRules(store storage.Storage, guild service.Guild) (Rules, error) {
+	rules := Rules{}
+	if err := storage.GetGuildJSON(store, guild, RulesKey, &rules); err != nil {
+		return Rules{}, fmt.Errorf("rules of %s: %w", guild.GuildID, err)
+	}
+
+	if rules.Channels == nil {
+		rules.Channels = map[string][]string{}
+	}
+	return rules, nil
+}
+
+// SetGuildRules replaces the rules of guild.
+func SetGuildRules(store storage.Storage, guild service.Guild, rules Rules) error {
+	return storage.SetGuildJSON(store, guild, RulesKey, rules)
+}
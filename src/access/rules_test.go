@@ -0,0 +1,64 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+func TestCheck(t *testing.T) {
+	rules := Rules{
+		Disabled: []string{"render"},
+		Channels: map[string][]string{AllCommands: {"bots"}, "define": {"bots", "words"}},
+	}
+
+	for _, test := range []struct {
+		trigger string
+		channel string
+		allowed bool
+	}{
+		{"render", "bots", false},
+		{"echo", "bots", true},
+		{"echo", "general", false},
+		{"define", "words", true},
+		{"define", "general", false},
+	} {
+		if err := rules.Check(test.trigger, test.channel); (err == nil) != test.allowed {
+			t.Errorf("Expected %s in %s to be allowed: %v, got %v", test.trigger, test.channel, test.allowed, err)
+		}
+	}
+
+	if err := rules.Check("define", "general"); err == nil || err.Error() != "define can only be used in <#bots>, <#words>" {
+		t.Errorf("Expected the allowed channels to be given, got %v", err)
+	}
+
+	if err := (Rules{}).Check("render", "general"); err != nil {
+		t.Errorf("Expected every command to be allowed without rules, got %v", err)
+	}
+}
+
+func TestGuildRules(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+	guild := service.Guild{ServiceID: "demo", GuildID: "0"}
+
+	rules, err := GuildRules(store, guild)
+	if err != nil || rules.Channels == nil {
+		t.Fatalf("Expected empty rules that can be changed, got %+v, %v", rules, err)
+	}
+
+	rules.Channels["define"] = []string{"words"}
+	if err := SetGuildRules(store, guild, rules); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err = GuildRules(store, guild)
+	if err != nil || len(rules.Channels["define"]) != 1 {
+		t.Errorf("Expected the rules to be stored, got %+v, %v", rules, err)
+	}
+
+	if rules, _ := GuildRules(store, service.Guild{ServiceID: "demo", GuildID: "1"}); len(rules.Channels) != 0 {
+		t.Errorf("Expected rules to only apply to their guild")
+	}
+}
@@ -3,6 +3,7 @@ package command
 
 import (
 	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/session"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
 )
 
@@ -13,31 +14,58 @@ type Command struct {
 	Help       string                                                                                                                             // What this command does.
 	HelpInput  string                                                                                                                             // Arguments following the trigger.
 	Exec       func(service.Conversation, service.User, []interface{}, *storage.Storage, func(service.Conversation, service.Message) error) error // The command's processing. The last parameter sends a reply, and is expected to be used at least once (if the command is unsuccessful, report an error).
-	observers  []service.Sender
+	Continue   session.Handler                                                                                                                    // Receives the user's next message when Exec or Continue starts a session with this command's trigger.
+	router     service.Router
+}
+
+// A Listener receives messages that don't start with a trigger, such as answers to a game.
+// Most messages aren't for a Listener, so it only uses the last parameter to reply to those that are.
+type Listener func(service.Conversation, service.User, string, *storage.Storage, func(service.Conversation, service.Message) error) error
+
+// A Filter decides whether the command with a trigger can be used in a conversation, such as when a guild
+// has disabled it. It returns an error that explains why the command can't be used, or nil if it can.
+type Filter func(storage *storage.Storage, conversation service.Conversation, trigger string) error
+
+// A GuildCommander has commands that only some guilds have, such as commands added by a guild's admins.
+// A guild's commands can't replace the bot's commands, so one with the same trigger is ignored.
+type GuildCommander interface {
+	GuildCommands(storage *storage.Storage, guild service.Guild) []Command // Commands of guild, matched after the bot's commands.
+	SlashCommands(storage *storage.Storage, guild service.Guild) []Command // Commands of guild that are also slash commands.
 }
 
 // A Parameter captures input to a command.
 type Parameter struct {
-	Type        string
-	Name        string
-	Description string
+	Type        string // "string", "int", "bool", "user" or "role".
+	Name        string // Name of the slash command option.
+	Description string // Description of the slash command option.
 }
 
 // AddSender will append a sender that output messages are routed to.
 func (c *Command) AddSender(sender service.Sender) {
-	c.observers = append(c.observers, sender)
+	c.router.AddSender(sender)
 }
 
 // RouteByID routes a message to an observer of this Bot with the same ID() as
 // conversation.ServiceID.
 func (c *Command) RouteByID(conversation service.Conversation, msg service.Message) error {
-	for _, observer := range c.observers {
-		if observer.ID() == conversation.ServiceID {
-			err := observer.SendMessage(conversation, msg)
-			if err != nil {
-				return err
+	return c.router.Route(conversation, msg)
+}
+
+// RouteToSession sends text from user to the command of their session in conversation, if they have one.
+// Services use it before matching triggers. Returns true if text was for a session.
+// A session of a command that filter doesn't allow in conversation is forgotten. A nil filter allows every command.
+func RouteToSession(commands []Command, filter Filter, conversation service.Conversation, user service.User, text string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) (bool, error) {
+	handler := func(trigger string) (session.Handler, bool) {
+		if filter != nil && filter(storage, conversation, trigger) != nil {
+			return nil, false
+		}
+
+		for _, cmd := range commands {
+			if cmd.Trigger == trigger && cmd.Continue != nil {
+				return cmd.Continue, true
 			}
 		}
+		return nil, false
 	}
-	return nil
+	return session.Route(handler, conversation, user, text, storage, sink)
 }
@@ -0,0 +1,55 @@
+// Package commandtest helps test packages that make commands, by running a command by its trigger
+// and collecting what it replied with.
+package commandtest
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// User runs commands in tests that don't need a particular user.
+var User = service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+
+// Storage returns empty storage, as commands are given it.
+func Storage() *storage.Storage {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+	return &store
+}
+
+// Exec runs the command of commands with trigger, as user in conversation, returning its replies.
+// The test fails if there's no command with trigger, or it returns an error.
+func Exec(t testing.TB, commands []command.Command, store *storage.Storage, conversation service.Conversation, user service.User, trigger string, words ...interface{}) []service.Message {
+	t.Helper()
+	for _, cmd := range commands {
+		if cmd.Trigger != trigger {
+			continue
+		}
+
+		replies := []service.Message{}
+		sink := func(_ service.Conversation, msg service.Message) error {
+			replies = append(replies, msg)
+			return nil
+		}
+		if err := cmd.Exec(conversation, user, words, store, sink); err != nil {
+			t.Fatal(err)
+		}
+		return replies
+	}
+
+	t.Fatalf("No command with trigger %s", trigger)
+	return nil
+}
+
+// Reply runs a command like Exec, returning its first reply, or an empty message if it didn't reply.
+func Reply(t testing.TB, commands []command.Command, store *storage.Storage, conversation service.Conversation, user service.User, trigger string, words ...interface{}) service.Message {
+	t.Helper()
+	if replies := Exec(t, commands, store, conversation, user, trigger, words...); len(replies) > 0 {
+		return replies[0]
+	}
+	return service.Message{}
+}
@@ -0,0 +1,203 @@
+package command
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// DailyHistoryPrefix starts the guild storage key of the words a DailyConfig's command has chosen,
+// which is followed by its trigger.
+const DailyHistoryPrefix = "daily_"
+
+// dailyMutex is locked when reading and then writing the words a DailyConfig's command has chosen.
+var dailyMutex sync.Mutex
+
+// DailyConfig can be turned into a command that chooses a word, such as a word of the day, and gives it to
+// another command, such as a dictionary lookup. Schedule the command to post the word every day.
+type DailyConfig struct {
+	Trigger   string   // Word which triggers this command to activate.
+	Target    string   // Trigger of the configured command that the chosen word is given to.
+	Words     []string // Words to choose from.
+	WordsFile string   // A file with a word on each line, relative to the configuration directory. Used with Words.
+	WordsURL  string   // A url of a text file with a word on each line. Used with Words and WordsFile.
+	History   int      // How many of the latest words aren't chosen again. When 0, words aren't repeated until every word has been chosen.
+	Title     string   // Shown above the message of Target, such as "Word of the day".
+	Help      string   // Help message to display.
+}
+
+// Command returns a Command from a config, which gives a word to the command in commands with
+// the trigger Target, retrieving WordsURL using getter.
+func (d DailyConfig) Command(getter HTMLGetter, commands []Command) (Command, error) {
+	index := slices.IndexFunc(commands, func(cmd Command) bool { return cmd.Trigger == d.Target })
+	if index == -1 {
+		return Command{}, fmt.Errorf("%s: there is no command %q", d.Trigger, d.Target)
+	}
+	target := commands[index]
+
+	if len(d.Words) == 0 && d.WordsFile == "" && d.WordsURL == "" {
+		return Command{}, fmt.Errorf("%s: Words, WordsFile or WordsURL is required", d.Trigger)
+	}
+
+	curry := func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return d.onMessage(sender, user, storage, sink, getter, target)
+	}
+
+	return Command{
+		Trigger: d.Trigger,
+		Exec:    curry,
+		Help:    d.Help,
+	}, nil
+}
+
+// onMessage chooses a word, and runs target with it.
+func (d DailyConfig) onMessage(
+	sender service.Conversation,
+	user service.User,
+	storage *storage.Storage,
+	sink func(service.Conversation, service.Message) error,
+	getter HTMLGetter,
+	target Command,
+) error {
+	words, err := d.words(getter)
+	if err != nil || len(words) == 0 {
+		return sink(sender, service.Message{
+			Title:       "Error",
+			Description: "An error occurred when reading the list of words.",
+		})
+	}
+
+	word := d.choose(words, []string{})
+	if storage != nil {
+		word = d.next(*storage, sender.Guild(), words)
+	}
+
+	types := make([]string, 0, len(target.Parameters))
+	for _, parameter := range target.Parameters {
+		types = append(types, parameter.Type)
+	}
+
+	input, err := service.ParseInput(service.ParserBasic(), strings.Fields(word), types)
+	if err != nil {
+		return sink(sender, service.Message{
+			Title:       "Error",
+			Description: fmt.Sprintf("\"%s\" can't be given to %s.", word, target.Trigger),
+		})
+	}
+
+	titled := func(conversation service.Conversation, msg service.Message) error {
+		if d.Title != "" && msg.Author.Name == "" {
+			msg.Author.Name = d.Title
+		}
+		return sink(conversation, msg)
+	}
+	return target.Exec(sender, user, input, storage, titled)
+}
+
+// choose returns a random word of words that isn't in history. Once every word is in history,
+// any word other than the latest can be chosen.
+func (d DailyConfig) choose(words []string, history []string) string {
+	candidates := []string{}
+	for _, word := range words {
+		if !slices.Contains(history, word) {
+			candidates = append(candidates, word)
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, word := range words {
+			if len(words) == 1 || word != history[len(history)-1] {
+				candidates = append(candidates, word)
+			}
+		}
+	}
+	return candidates[rand.Intn(len(candidates))]
+}
+
+// next chooses a word of words for guild, adding it to the words chosen in guild.
+func (d DailyConfig) next(store storage.Storage, guild service.Guild, words []string) string {
+	dailyMutex.Lock()
+	defer dailyMutex.Unlock()
+
+	history := d.history(store, guild)
+	word := d.choose(words, history)
+	history = append(history, word)
+	if d.History > 0 && len(history) > d.History {
+		history = history[len(history)-d.History:]
+	} else if d.History == 0 && len(history) > len(words) {
+		// Every word has been chosen, so history starts again from the latest word.
+		history = history[len(history)-1:]
+	}
+
+	// Without history a word may be repeated sooner, which isn't worth failing for.
+	if err := storage.SetGuildJSON(store, guild, DailyHistoryPrefix+d.Trigger, history); err != nil {
+		slog.Warn("unable to save chosen words", "trigger", d.Trigger, "guild", guild.GuildID, "error", err)
+	}
+	return word
+}
+
+// history returns the words chosen in guild, oldest first.
+func (d DailyConfig) history(store storage.Storage, guild service.Guild) []string {
+	history := []string{}
+	if err := storage.GetGuildJSON(store, guild, DailyHistoryPrefix+d.Trigger, &history); err != nil {
+		slog.Warn("unable to read chosen words", "trigger", d.Trigger, "guild", guild.GuildID, "error", err)
+		return []string{}
+	}
+	return history
+}
+
+// words returns Words, followed by the words in WordsFile and WordsURL.
+func (d DailyConfig) words(getter HTMLGetter) ([]string, error) {
+	words := append([]string{}, d.Words...)
+	if d.WordsFile != "" {
+		file, err := os.Open(d.WordsFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		words = append(words, readWords(file)...)
+	}
+
+	if d.WordsURL != "" {
+		_, reader, err := getter(d.WordsURL)
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		words = append(words, readWords(reader)...)
+	}
+	return words, nil
+}
+
+// readWords returns each line of reader that isn't empty.
+func readWords(reader io.Reader) []string {
+	words := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if word := strings.TrimSpace(scanner.Text()); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// GetDailyConfigs retrieves an array of DailyConfig by parsing JSON from a reader.
+func GetDailyConfigs(reader io.Reader) ([]DailyConfig, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var config []DailyConfig
+	return config, json.Unmarshal(bytes, &config)
+}
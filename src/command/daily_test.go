@@ -0,0 +1,139 @@
+package command
+
+import (
+	"io"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// define is a command that replies with the word it's given.
+var define = Command{
+	Trigger:    "define",
+	Parameters: []Parameter{{Type: "string"}},
+	Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return sink(sender, service.Message{Title: msg[0].(string)})
+	},
+}
+
+// execDaily runs cmd, returning the message it sent.
+func execDaily(t *testing.T, cmd Command, store *storage.Storage) service.Message {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0", GuildID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	if err := cmd.Exec(testConversation, testSender, []interface{}{}, store, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	return resultMessage
+}
+
+func TestDailyNoRepeats(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+
+	words := []string{"kumusta", "salamat", "paalam"}
+	config := DailyConfig{Trigger: "wotd", Target: "define", Words: words, Title: "Word of the day"}
+	cmd, err := config.Command(nil, []Command{define})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	latest := ""
+	for round := 0; round < 3; round++ {
+		chosen := map[string]bool{}
+		for range words {
+			msg := execDaily(t, cmd, &store)
+			if msg.Author.Name != "Word of the day" {
+				t.Errorf("Expected the title to be shown, got %+v", msg)
+			}
+
+			if chosen[msg.Title] || msg.Title == latest {
+				t.Errorf("Expected %s not to be repeated in round %d", msg.Title, round)
+			}
+			chosen[msg.Title] = true
+			latest = msg.Title
+		}
+	}
+}
+
+func TestDailyHistory(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+
+	config := DailyConfig{Trigger: "wotd", Target: "define", Words: []string{"a", "b", "c"}, History: 1}
+	cmd, err := config.Command(nil, []Command{define})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	previous := ""
+	for i := 0; i < 20; i++ {
+		word := execDaily(t, cmd, &store).Title
+		if word == previous {
+			t.Fatalf("Expected %s not to be chosen twice in a row", word)
+		}
+		previous = word
+	}
+
+	if history := config.history(store, service.Guild{ServiceID: "", GuildID: "0"}); len(history) != 1 {
+		t.Errorf("Expected history to be limited to 1 word, got %v", history)
+	}
+}
+
+func TestDailyWordSources(t *testing.T) {
+	wordsFile := path.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(wordsFile, []byte("\nfile\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	getter := func(url string) (string, io.ReadCloser, error) {
+		return url, io.NopCloser(strings.NewReader("url\n")), nil
+	}
+
+	config := DailyConfig{Words: []string{"list"}, WordsFile: wordsFile, WordsURL: "https://example.com/words.txt"}
+	words, err := config.words(getter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(words, ",") != "list,file,url" {
+		t.Errorf("Unexpected words %v", words)
+	}
+}
+
+func TestDailyErrors(t *testing.T) {
+	if _, err := (DailyConfig{Target: "missing", Words: []string{"a"}}).Command(nil, []Command{define}); err == nil {
+		t.Errorf("Expected a missing target to be rejected")
+	}
+
+	if _, err := (DailyConfig{Target: "define"}).Command(nil, []Command{define}); err == nil {
+		t.Errorf("Expected a config without words to be rejected")
+	}
+
+	cmd, err := DailyConfig{Target: "define", WordsFile: path.Join(t.TempDir(), "missing.txt")}.Command(nil, []Command{define})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg := execDaily(t, cmd, nil); msg.Title != "Error" {
+		t.Errorf("Expected a missing file to be reported, got %+v", msg)
+	}
+}
+
+func TestGetDailyConfigs(t *testing.T) {
+	configs, err := GetDailyConfigs(strings.NewReader(`[{"Trigger": "wotd", "Target": "define", "Words": ["kumusta"]}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 1 || configs[0].Target != "define" || configs[0].Words[0] != "kumusta" {
+		t.Errorf("Unexpected configs %+v", configs)
+	}
+}
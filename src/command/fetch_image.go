@@ -0,0 +1,79 @@
+package command
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+
+	// Formats that fetched images can be in.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// defaultImageMaxBytes is the largest image that is downloaded, when a config doesn't set a limit.
+const defaultImageMaxBytes = 8 << 20
+
+// defaultImageMaxSide is how wide or tall an image can be before it's scaled down, when a config doesn't set a limit.
+const defaultImageMaxSide = 1024
+
+// maxImagePixels is the most pixels an image can have before it's decoded, so a small file can't use a lot of memory.
+const maxImagePixels = 50_000_000
+
+// fetchImage retrieves the PNG, JPEG, GIF or WebP image at imageURL using getter.
+// Images larger than maxBytes aren't decoded, and images wider or taller than maxSide are scaled down to fit.
+func fetchImage(getter HTMLGetter, imageURL string, maxBytes int, maxSide int) (image.Image, error) {
+	_, reader, err := getter(imageURL)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, int64(maxBytes)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxBytes)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("image is %dx%d, which has too many pixels", config.Width, config.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return downscale(img, maxSide), nil
+}
+
+// downscale returns img scaled down so it's no wider or taller than maxSide, keeping its shape.
+// A smaller image is returned unchanged.
+func downscale(img image.Image, maxSide int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxSide <= 0 || (width <= maxSide && height <= maxSide) {
+		return img
+	}
+
+	if width >= height {
+		height = max(height*maxSide/width, 1)
+		width = maxSide
+	} else {
+		width = max(width*maxSide/height, 1)
+		height = maxSide
+	}
+
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Over, nil)
+	return scaled
+}
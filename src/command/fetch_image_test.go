@@ -0,0 +1,92 @@
+package command
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"testing"
+)
+
+// imageGetter returns a HTMLGetter that returns data on any input.
+func imageGetter(data []byte) HTMLGetter {
+	return func(url string) (string, io.ReadCloser, error) {
+		return url, io.NopCloser(bytes.NewReader(data)), nil
+	}
+}
+
+// encoded returns img encoded by encode.
+func encoded(t *testing.T, img image.Image, encode func(io.Writer, image.Image) error) []byte {
+	buffer := bytes.Buffer{}
+	if err := encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestFetchImageFormats(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 2))
+
+	// A 1x1 lossless WebP.
+	webp, err := base64.StdEncoding.DecodeString("UklGRhoAAABXRUJQVlA4TA0AAAAvAAAAEAcQERGIiP4HAA==")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	formats := map[string][]byte{
+		"png":  encoded(t, img, png.Encode),
+		"jpeg": encoded(t, img, func(w io.Writer, i image.Image) error { return jpeg.Encode(w, i, nil) }),
+		"gif":  encoded(t, img, func(w io.Writer, i image.Image) error { return gif.Encode(w, i, nil) }),
+		"webp": webp,
+	}
+
+	for format, data := range formats {
+		fetched, err := fetchImage(imageGetter(data), "https://example.com/image", defaultImageMaxBytes, defaultImageMaxSide)
+		if err != nil {
+			t.Errorf("%s: %s", format, err)
+		} else if fetched.Bounds().Dx() == 0 {
+			t.Errorf("%s: expected an image", format)
+		}
+	}
+}
+
+func TestFetchImageTooLarge(t *testing.T) {
+	data := encoded(t, image.NewRGBA(image.Rect(0, 0, 100, 100)), png.Encode)
+	if _, err := fetchImage(imageGetter(data), "https://example.com/image", len(data)-1, defaultImageMaxSide); err == nil {
+		t.Errorf("Expected an image with too many bytes to be rejected")
+	}
+
+	if _, err := fetchImage(imageGetter(data), "https://example.com/image", len(data), defaultImageMaxSide); err != nil {
+		t.Errorf("Expected an image of exactly the limit to be fetched, got %s", err)
+	}
+}
+
+func TestFetchImageNotAnImage(t *testing.T) {
+	if _, err := fetchImage(imageGetter([]byte("<html></html>")), "https://example.com/image", defaultImageMaxBytes, defaultImageMaxSide); err == nil {
+		t.Errorf("Expected a webpage to be rejected")
+	}
+}
+
+func TestDownscale(t *testing.T) {
+	cases := []struct {
+		width, height    int
+		maxSide          int
+		expectW, expectH int
+	}{
+		{400, 200, 100, 100, 50},
+		{200, 400, 100, 50, 100},
+		{50, 20, 100, 50, 20},
+		{1000, 1, 100, 100, 1},
+		{400, 200, 0, 400, 200},
+	}
+
+	for _, c := range cases {
+		scaled := downscale(image.NewRGBA(image.Rect(0, 0, c.width, c.height)), c.maxSide)
+		if scaled.Bounds().Dx() != c.expectW || scaled.Bounds().Dy() != c.expectH {
+			t.Errorf("Expected %dx%d scaled to %d to be %dx%d, got %v", c.width, c.height, c.maxSide, c.expectW, c.expectH, scaled.Bounds())
+		}
+	}
+}
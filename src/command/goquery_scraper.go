@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
+	"log/slog"
 	"strings"
 
 	"math"
@@ -18,24 +18,33 @@ import (
 
 // GoQueryScraperConfig can be turned into a scraper that uses GoQuery.
 type GoQueryScraperConfig struct {
-	Title         string          // When sending a post, what should the title be.
-	Trigger       string          // Word which triggers this command to activate.
-	Parameters    []Parameter     // How to capture words.
-	TitleSelector SelectorCapture // The output message's title.
-	ErrorURL      string          // A url to show only when there is an error.
-	URL           string          // A url to scrape from, can contain one "%s" which is replaced with the first capture group.
-	URLSuffix     string          // When adding a URL to a message, this string is appended. This is useful for including referral links.
-	ReplySelector SelectorCapture // The output message's body text.
-	Fields        []GoQueryFieldCapture
-	Help          string // Help message to display.
-	HelpInput     string // Help message to display for input following command.
-	HideURL       bool   // When true, a result returns no URL. Use with caution, attribution is often required.
+	Title         string                // When sending a post, what should the title be.
+	Trigger       string                // Word which triggers this command to activate.
+	Parameters    []Parameter           // How to capture words.
+	TitleSelector SelectorCapture       // The output message's title.
+	ErrorURL      string                // A url to show only when there is an error.
+	URL           string                // A url to scrape from, can contain one "%s" which is replaced with the first capture group.
+	URLSuffix     string                // When adding a URL to a message, this string is appended. This is useful for including referral links.
+	ReplySelector SelectorCapture       // The output message's body text.
+	Fields        []GoQueryFieldCapture // Fields to add to the output message.
+	Help          string                // Help message to display.
+	HelpInput     string                // Help message to display for input following command.
+	HideURL       bool                  // When true, a result returns no URL. Use with caution, attribution is often required.
+	Color         string                // Color of the output message as a hex code, such as "#FF0000".
+	Footer        SelectorCapture       // Small text shown at the bottom of the output message.
+	Author        SelectorCapture       // Who or what the output message is from, shown above the title.
+	Thumbnail     SelectorCapture       // URL of a small image shown beside the output message. Relative URLs are resolved against the page.
+	Timestamp     SelectorCapture       // When the scraped content is from, such as "2021-05-04" or "2021-05-04T03:02:01Z".
+	Paginated     bool                  // When true, each match of TitleSelector and ReplySelector is a page, instead of choosing one using HandleMultiple.
+	ImageSelector SelectorCapture       // URL of an image attached to the output message, taken from Attribute, which defaults to "src". Relative URLs are resolved against the page.
+	ImageMaxBytes int                   // The largest image that is downloaded, in bytes. Defaults to 8 MiB.
+	ImageMaxSide  int                   // Images wider or taller than this many pixels are scaled down to fit. Defaults to 1024.
 }
 
 // GoQueryFieldCapture is used to have a selector capture for a pair of selectors.
 type GoQueryFieldCapture struct {
-	Title       SelectorCapture
-	Description SelectorCapture
+	Title       SelectorCapture // The field's title.
+	Description SelectorCapture // The field's body text.
 }
 
 // SelectorCapture will fill out a template string using webpage content selected with goquery.
@@ -44,50 +53,87 @@ type SelectorCapture struct {
 	Selectors       []string            // What goquery captures are used to fill out the template.
 	Replacements    []map[string]string // String replacements for each entry in selectors.
 	FullReplacement map[string]string   // String replacement that takes place on the completed selector.
-	HandleMultiple  string              // How to handle multiple captures. "Random" or "First."
+	HandleMultiple  string              // How to handle multiple captures. "First", "Last", "Random", "All", "Index:n" or "Range:a-b", where indexes count from 0 and a negative n counts back from the last.
+	Separator       string              // Joins the filled out templates when HandleMultiple is "All" or "Range". Defaults to a new line.
+	Limit           int                 // When more than 0, at most this many filled out templates are joined.
+	Attribute       string              // When set, the value of this attribute is captured instead of text, such as "src" or "href". Relative links in "href" and "src" are made absolute.
+	Extract         string              // What is captured when Attribute isn't set. "Text", "HTML" for the inner HTML, or "Markdown" to keep formatting such as bold text and links. Defaults to "Text".
 }
 
+// urlAttributes are attributes whose values are links, so relative links can be made absolute.
+var urlAttributes = map[string]bool{"href": true, "src": true, "data-src": true, "poster": true}
+
 // A HTMLGetter returns a url and buffer based on a string.
 type HTMLGetter = func(string) (url string, out io.ReadCloser, err error)
 
 // selectorCaptureToString matches all selectors and fill out template.
-// Then using HandleMultiple decide which to use.
+// Then using HandleMultiple decide which to use. When more than one is used, they're joined by Separator.
+// An unknown HandleMultiple uses the first match, as it did before there were other modes.
 func (s SelectorCapture) selectorCaptureToString(doc goquery.Document) (string, error) {
 	if len(s.Selectors) == 0 || !strings.Contains(s.Template, "%s") {
 		return s.Template, nil
 	}
 
-	var maxLength int64 = math.MaxInt64
-	allCaptures := make([](*(goquery.Selection)), len(s.Selectors))
-	for i, selector := range s.Selectors {
-		capture := doc.Find(selector)
-		allCaptures[i] = capture
-		captureLength := int64(capture.Length())
+	handle, err := parseHandleMultiple(s.HandleMultiple)
+	if err != nil {
+		handle = handleMultiple{mode: "First"}
+	}
 
-		if captureLength < maxLength {
-			maxLength = captureLength
-		}
+	allCaptures, length := s.captures(doc)
+	base := documentURL(doc)
+	indexes := handle.indexes(length)
+	if s.Limit > 0 && len(indexes) > s.Limit {
+		indexes = indexes[:s.Limit]
 	}
 
-	// if maxLength == 0 {
-	// 	return "", fmt.Errorf("There was an error retrieving information from the webpage.")
-	// }
-	maxLength--
+	replies := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		replies = append(replies, s.fill(allCaptures, index, base))
+	}
 
-	var index int = 0
-	if maxLength > 0 {
-		if s.HandleMultiple == "Random" {
-			index = int(rand.Int63n(maxLength))
-		} else if s.HandleMultiple == "Last" {
-			index = int(maxLength)
-		}
+	separator := s.Separator
+	if separator == "" {
+		separator = defaultSeparator
+	}
+	return strings.Join(replies, separator), nil
+}
+
+// selectorCaptureAll fills out the template once for each match of the selectors, ignoring HandleMultiple.
+// A template without selectors is filled out once.
+func (s SelectorCapture) selectorCaptureAll(doc goquery.Document) []string {
+	if len(s.Selectors) == 0 || !strings.Contains(s.Template, "%s") {
+		return []string{s.Template}
+	}
+
+	allCaptures, length := s.captures(doc)
+	base := documentURL(doc)
+	replies := make([]string, 0, length)
+	for index := 0; index < length; index++ {
+		replies = append(replies, s.fill(allCaptures, index, base))
 	}
+	return replies
+}
 
+// captures finds each selector in doc, returning what was found and the fewest matches of any selector.
+func (s SelectorCapture) captures(doc goquery.Document) ([]*goquery.Selection, int) {
+	length := math.MaxInt
+	allCaptures := make([](*(goquery.Selection)), len(s.Selectors))
+	for i, selector := range s.Selectors {
+		capture := doc.Find(selector)
+		allCaptures[i] = capture
+		length = min(length, capture.Length())
+	}
+	return allCaptures, length
+}
+
+// fill fills out the template using the match at index of each selector.
+// Relative links are resolved against base.
+func (s SelectorCapture) fill(allCaptures []*goquery.Selection, index int, base string) string {
 	tmp := make([]interface{}, len(s.Selectors))
 	for i, selector := range allCaptures {
 		val := ""
-		if index < (*selector).Length() {
-			val = strings.TrimSpace(selector.Slice(int(index), int(index)+1).Text())
+		if index >= 0 && index < (*selector).Length() {
+			val = s.extract(selector.Slice(index, index+1), base)
 			if i < len(s.Replacements) {
 				for search, replace := range s.Replacements[i] {
 					if strings.Contains(val, search) {
@@ -107,7 +153,37 @@ func (s SelectorCapture) selectorCaptureToString(doc goquery.Document) (string,
 		}
 	}
 
-	return reply, nil
+	return reply
+}
+
+// extract returns what is captured from a single match, using Attribute or Extract.
+func (s SelectorCapture) extract(capture *goquery.Selection, base string) string {
+	if s.Attribute != "" {
+		val, _ := capture.Attr(s.Attribute)
+		val = strings.TrimSpace(val)
+		if val != "" && urlAttributes[s.Attribute] {
+			val = resolveURL(base, val)
+		}
+		return val
+	}
+
+	switch s.Extract {
+	case "HTML":
+		val, _ := capture.Html()
+		return strings.TrimSpace(val)
+	case "Markdown":
+		return htmlToMarkdown(capture, base)
+	default:
+		return strings.TrimSpace(capture.Text())
+	}
+}
+
+// documentURL returns where doc was retrieved from, or "" if that isn't known.
+func documentURL(doc goquery.Document) string {
+	if doc.Url == nil {
+		return ""
+	}
+	return doc.Url.String()
 }
 
 // Command returns a webscraper Command from a config.
@@ -156,6 +232,7 @@ func (g GoQueryScraperConfig) onMessage(sender service.Conversation, user servic
 	}
 
 	redirect, htmlReader, err := htmlGetter(msgURL)
+	pageURL := redirect // Kept even when HideURL is used, to resolve relative URLs.
 	if err == nil {
 		defer htmlReader.Close()
 	} else {
@@ -180,6 +257,7 @@ func (g GoQueryScraperConfig) onMessage(sender service.Conversation, user servic
 			},
 		)
 	}
+	doc.Url, _ = url.Parse(pageURL)
 
 	if doc.Text() == "" {
 		captures := []string{}
@@ -243,9 +321,89 @@ func (g GoQueryScraperConfig) onMessage(sender service.Conversation, user servic
 		replyMsg.Fields = fields[1:]
 	}
 
+	g.addDetails(*doc, pageURL, &replyMsg)
+	g.addImage(*doc, pageURL, htmlGetter, &replyMsg)
+	if g.Paginated {
+		if pages := g.pages(*doc, replyMsg); len(pages) > 1 {
+			return sink(sender, service.Paginate(pages))
+		}
+	}
 	return sink(sender, replyMsg)
 }
 
+// addImage attaches the image found by ImageSelector to msg, retrieving it using htmlGetter.
+// If there's no image, or it can't be retrieved, msg is unchanged.
+func (g GoQueryScraperConfig) addImage(doc goquery.Document, pageURL string, htmlGetter HTMLGetter, msg *service.Message) {
+	selector := g.ImageSelector
+	if selector.Attribute == "" {
+		selector.Attribute = "src"
+	}
+
+	imageURL, _ := selector.selectorCaptureToString(doc)
+	if imageURL == "" {
+		return
+	}
+
+	maxBytes := g.ImageMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultImageMaxBytes
+	}
+
+	maxSide := g.ImageMaxSide
+	if maxSide <= 0 {
+		maxSide = defaultImageMaxSide
+	}
+
+	imageURL = resolveURL(pageURL, imageURL)
+	img, err := fetchImage(htmlGetter, imageURL, maxBytes, maxSide)
+	if err != nil {
+		slog.Warn("unable to retrieve image", "url", imageURL, "error", err)
+		return
+	}
+	msg.Image = img
+}
+
+// pages returns a page for each match of TitleSelector and ReplySelector, which otherwise look like msg.
+// If only one of them has several matches, the other is the same on every page.
+func (g GoQueryScraperConfig) pages(doc goquery.Document, msg service.Message) []service.Message {
+	titles := g.TitleSelector.selectorCaptureAll(doc)
+	values := g.ReplySelector.selectorCaptureAll(doc)
+	if len(titles) == 0 || len(values) == 0 {
+		return nil
+	}
+
+	pages := []service.Message{}
+	for i := 0; i < max(len(titles), len(values)); i++ {
+		title := titles[min(i, len(titles)-1)]
+		value := values[min(i, len(values)-1)]
+		if title == "" || value == "" {
+			continue
+		}
+
+		page := msg
+		page.Title = title
+		page.Description = value
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+// addDetails adds the color, footer, author, thumbnail and timestamp of a config to msg.
+// pageURL is the URL doc was retrieved from. Details that can't be captured are left out.
+func (g GoQueryScraperConfig) addDetails(doc goquery.Document, pageURL string, msg *service.Message) {
+	msg.Color, _ = ParseColor(g.Color)
+	msg.Footer, _ = g.Footer.selectorCaptureToString(doc)
+	msg.Author.Name, _ = g.Author.selectorCaptureToString(doc)
+
+	if thumbnail, _ := g.Thumbnail.selectorCaptureToString(doc); thumbnail != "" {
+		msg.ThumbnailURL = resolveURL(pageURL, thumbnail)
+	}
+
+	if timestamp, _ := g.Timestamp.selectorCaptureToString(doc); timestamp != "" {
+		msg.Timestamp, _ = ParseTimestamp(timestamp)
+	}
+}
+
 // GetGoqueryScraperConfigs retrieves an array of GoQueryScraperConfig by parsing JSON from a buffer.
 // If a file doesn't exist, an example is made in its place, and an error is returned.
 func GetGoqueryScraperConfigs(reader io.Reader) ([]GoQueryScraperConfig, error) {
@@ -5,9 +5,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
@@ -547,7 +550,8 @@ func TestGoQueryScraperWithOneCapture(t *testing.T) {
 		t.Errorf("Title was different!")
 	}
 
-	if !strings.HasPrefix(resultMessage.Description, "Heading Two") {
+	// Random can choose either match.
+	if resultMessage.Description != "Heading Two" && resultMessage.Description != "2nd Heading Two" {
 		t.Errorf("Message was different!")
 	}
 
@@ -1404,3 +1408,246 @@ func TestGoQueryScraperWithCaptureHideUrl(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestGoQueryScraperDetails(t *testing.T) {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	const page = `
+<html>
+<h1>Heading</h1>
+<p class="body">Body</p>
+<span class="by">Someone</span>
+<img class="thumb" src="/images/thumb.png">
+<time>2021-05-04</time>
+</html>
+`
+	getter := func(string) (string, io.ReadCloser, error) {
+		return "https://example.com/word/page", io.NopCloser(strings.NewReader(page)), nil
+	}
+
+	config := GoQueryScraperConfig{
+		URL:           "https://example.com/word",
+		TitleSelector: SelectorCapture{Template: "%s", Selectors: []string{"h1"}},
+		ReplySelector: SelectorCapture{Template: "%s", Selectors: []string{".body"}},
+		Color:         "#00FF00",
+		Footer:        SelectorCapture{Template: "Scraped from example.com"},
+		Author:        SelectorCapture{Template: "By %s", Selectors: []string{".by"}},
+		Thumbnail:     SelectorCapture{Template: "%s", Selectors: []string{".thumb"}, Attribute: "src"},
+		Timestamp:     SelectorCapture{Template: "%s", Selectors: []string{"time"}},
+		HideURL:       true,
+	}
+
+	scraper, err := config.CommandWithHTMLGetter(getter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scraper.Exec(testConversation, testSender, []interface{}{}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	expect := service.Message{
+		Title:        "Heading",
+		Description:  "Body",
+		Color:        0x00FF00,
+		Footer:       "Scraped from example.com",
+		Author:       service.MessageAuthor{Name: "By Someone"},
+		ThumbnailURL: "https://example.com/images/thumb.png",
+		Timestamp:    time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC),
+	}
+
+	if diff := cmp.Diff(expect, resultMessage); diff != "" {
+		t.Errorf("Unexpected message: %s", diff)
+	}
+}
+
+func TestGoQueryScraperPaginated(t *testing.T) {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	config := GoQueryScraperConfig{
+		URL:           "%s",
+		Parameters:    []Parameter{{Type: "string"}},
+		TitleSelector: SelectorCapture{Template: "Heading"},
+		ReplySelector: SelectorCapture{Template: "%s", Selectors: []string{"h2"}},
+		Fields:        []GoQueryFieldCapture{{Title: SelectorCapture{Template: "Field"}, Description: SelectorCapture{Template: "%s", Selectors: []string{"h1"}}}},
+		Paginated:     true,
+	}
+
+	scraper, err := config.CommandWithHTMLGetter(htmlTestPage)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scraper.Exec(testConversation, testSender, []interface{}{"usual"}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	descriptions := []string{}
+	for _, page := range resultMessage.Pages {
+		descriptions = append(descriptions, page.Description)
+		if page.Title != "Heading" || len(page.Fields) != 1 || page.Fields[0].Value != "Heading One" {
+			t.Errorf("Expected every page to share the title and fields, got %+v", page)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"Heading Two", "2nd Heading Two"}, descriptions); diff != "" {
+		t.Errorf("Unexpected pages: %s", diff)
+	}
+
+	if resultMessage.Description != "Heading Two" {
+		t.Errorf("Expected the message to be the first page")
+	}
+
+	// A single match isn't paginated.
+	if err := scraper.Exec(testConversation, testSender, []interface{}{"tables"}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ = demoSender.PopMessage()
+	if resultMessage.Pages != nil || resultMessage.Description != "Tables Heading Two" {
+		t.Errorf("Expected a single page to be a message, got %+v", resultMessage)
+	}
+}
+
+func TestGoQueryScraperExtract(t *testing.T) {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	const page = `
+<html>
+<h1>Word</h1>
+<p class="definition">A <b>short</b> <a href="/related">meaning</a></p>
+<audio class="pronunciation" src="/audio/word.mp3" data-ipa="wɜːd"></audio>
+</html>
+`
+	getter := func(string) (string, io.ReadCloser, error) {
+		return "https://example.com/word/page", io.NopCloser(strings.NewReader(page)), nil
+	}
+
+	config := GoQueryScraperConfig{
+		URL:           "https://example.com/word",
+		TitleSelector: SelectorCapture{Template: "%s", Selectors: []string{"h1"}},
+		ReplySelector: SelectorCapture{Template: "%s", Selectors: []string{".definition"}, Extract: "Markdown"},
+		Fields: []GoQueryFieldCapture{
+			{
+				Title:       SelectorCapture{Template: "Pronunciation"},
+				Description: SelectorCapture{Template: "%s", Selectors: []string{".pronunciation"}, Attribute: "src"},
+			},
+			{
+				Title:       SelectorCapture{Template: "IPA"},
+				Description: SelectorCapture{Template: "/%s/", Selectors: []string{".pronunciation"}, Attribute: "data-ipa"},
+			},
+			{
+				Title:       SelectorCapture{Template: "HTML"},
+				Description: SelectorCapture{Template: "%s", Selectors: []string{".definition"}, Extract: "HTML"},
+			},
+		},
+	}
+
+	scraper, err := config.CommandWithHTMLGetter(getter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scraper.Exec(testConversation, testSender, []interface{}{}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	if resultMessage.Description != "A **short** [meaning](https://example.com/related)" {
+		t.Errorf("Unexpected description %q", resultMessage.Description)
+	}
+
+	values := []string{}
+	for _, field := range resultMessage.Fields {
+		values = append(values, field.Value)
+	}
+
+	expect := []string{"https://example.com/audio/word.mp3", "/wɜːd/", `A <b>short</b> <a href="/related">meaning</a>`}
+	if diff := cmp.Diff(expect, values); diff != "" {
+		t.Errorf("Unexpected fields: %s", diff)
+	}
+}
+
+func TestGoQueryScraperImage(t *testing.T) {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	const page = `
+<html>
+<h1>Comic</h1>
+<img class="comic" src="/comics/1.png">
+</html>
+`
+	comic := bytes.Buffer{}
+	if err := png.Encode(&comic, image.NewRGBA(image.Rect(0, 0, 300, 100))); err != nil {
+		t.Fatal(err)
+	}
+
+	requested := []string{}
+	getter := func(url string) (string, io.ReadCloser, error) {
+		requested = append(requested, url)
+		if url == "https://example.com/comics/1.png" {
+			return url, io.NopCloser(bytes.NewReader(comic.Bytes())), nil
+		}
+		return "https://example.com/latest", io.NopCloser(strings.NewReader(page)), nil
+	}
+
+	config := GoQueryScraperConfig{
+		URL:           "https://example.com/latest",
+		TitleSelector: SelectorCapture{Template: "%s", Selectors: []string{"h1"}},
+		ReplySelector: SelectorCapture{Template: "Today's comic"},
+		ImageSelector: SelectorCapture{Template: "%s", Selectors: []string{".comic"}, Attribute: "src"},
+		ImageMaxSide:  150,
+	}
+
+	scraper, err := config.CommandWithHTMLGetter(getter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scraper.Exec(testConversation, testSender, []interface{}{}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	if resultMessage.Image == nil {
+		t.Fatalf("Expected the comic to be attached, requested %v", requested)
+	}
+
+	if bounds := resultMessage.Image.Bounds(); bounds.Dx() != 150 || bounds.Dy() != 50 {
+		t.Errorf("Expected the comic to be scaled down, got %v", bounds)
+	}
+
+	// Without an Attribute, the image is found using "src".
+	config.ImageSelector.Attribute = ""
+	scraper, _ = config.CommandWithHTMLGetter(getter)
+	if err := scraper.Exec(testConversation, testSender, []interface{}{}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ = demoSender.PopMessage()
+	if resultMessage.Image == nil {
+		t.Errorf("Expected the comic to be found using src, requested %v", requested)
+	}
+
+	// A missing image still sends the rest of the message.
+	config.ImageSelector.Selectors = []string{".missing"}
+	scraper, _ = config.CommandWithHTMLGetter(getter)
+	if err := scraper.Exec(testConversation, testSender, []interface{}{}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ = demoSender.PopMessage()
+	if resultMessage.Image != nil || resultMessage.Title != "Comic" {
+		t.Errorf("Expected a message without an image, got %+v", resultMessage)
+	}
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// HandleMultiplePattern matches every value SelectorCapture.HandleMultiple can have.
+const HandleMultiplePattern = `^(|First|Last|Random|All|Index:-?[0-9]+|Range:[0-9]+-[0-9]+)$`
+
+// defaultSeparator joins matches when SelectorCapture.Separator is empty.
+const defaultSeparator = "\n"
+
+// handleMultiple is a SelectorCapture.HandleMultiple after it has been read.
+// Matches from start up to and including end are used, where a negative index counts back from the last match.
+type handleMultiple struct {
+	mode  string
+	start int
+	end   int
+}
+
+// parseHandleMultiple reads a SelectorCapture.HandleMultiple, such as "First", "Index:2" or "Range:0-4".
+// Indexes count from 0.
+func parseHandleMultiple(text string) (handleMultiple, error) {
+	mode, arguments, hasArguments := strings.Cut(text, ":")
+	switch mode {
+	case "", "First", "Last", "Random", "All":
+		if !hasArguments {
+			return handleMultiple{mode: mode}, nil
+		}
+	case "Index":
+		index, err := strconv.Atoi(arguments)
+		if err == nil {
+			return handleMultiple{mode: mode, start: index, end: index}, nil
+		}
+	case "Range":
+		first, last, found := strings.Cut(arguments, "-")
+		start, startErr := strconv.Atoi(first)
+		end, endErr := strconv.Atoi(last)
+		if found && startErr == nil && endErr == nil && start >= 0 && start <= end {
+			return handleMultiple{mode: mode, start: start, end: end}, nil
+		}
+	}
+
+	return handleMultiple{}, fmt.Errorf(
+		"%q must be \"First\", \"Last\", \"Random\", \"All\", \"Index:n\" or \"Range:a-b\" where a is at most b",
+		text,
+	)
+}
+
+// CheckHandleMultiple returns an error if text isn't a value SelectorCapture.HandleMultiple can have.
+func CheckHandleMultiple(text string) error {
+	_, err := parseHandleMultiple(text)
+	return err
+}
+
+// indexes returns which of length matches are used, in order.
+func (h handleMultiple) indexes(length int) []int {
+	switch h.mode {
+	case "Last":
+		return []int{max(length-1, 0)}
+	case "Random":
+		if length == 0 {
+			return []int{0}
+		}
+		return []int{rand.Intn(length)}
+	case "All":
+		return rangeOf(0, length-1)
+	case "Index":
+		index := h.start
+		if index < 0 {
+			index += length
+		}
+		// An index beyond the matches is no match, rather than a template filled out with nothing.
+		if index < 0 || index >= length {
+			return []int{}
+		}
+		return []int{index}
+	case "Range":
+		return rangeOf(h.start, min(h.end, length-1))
+	default:
+		return []int{0}
+	}
+}
+
+// rangeOf returns the numbers from start up to and including end.
+func rangeOf(start int, end int) []int {
+	numbers := []int{}
+	for i := start; i <= end; i++ {
+		numbers = append(numbers, i)
+	}
+	return numbers
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestParseHandleMultiple(t *testing.T) {
+	valid := []string{"", "First", "Last", "Random", "All", "Index:0", "Index:-1", "Range:0-4", "Range:2-2"}
+	for _, text := range valid {
+		if err := CheckHandleMultiple(text); err != nil {
+			t.Errorf("Expected %q to be valid, got %s", text, err)
+		}
+	}
+
+	invalid := []string{"Middle", "first", "All:2", "Index", "Index:a", "Range:4-2", "Range:-1-2", "Range:3"}
+	for _, text := range invalid {
+		if err := CheckHandleMultiple(text); err == nil {
+			t.Errorf("Expected %q to be invalid", text)
+		}
+	}
+}
+
+func TestHandleMultiple(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(
+		"<ol><li>one</li><li>two</li><li>three</li><li>four</li><li>five</li></ol>",
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		capture SelectorCapture
+		expect  string
+	}{
+		{SelectorCapture{HandleMultiple: ""}, "1. one"},
+		{SelectorCapture{HandleMultiple: "First"}, "1. one"},
+		{SelectorCapture{HandleMultiple: "Last"}, "1. five"},
+		{SelectorCapture{HandleMultiple: "All"}, "1. one\n1. two\n1. three\n1. four\n1. five"},
+		{SelectorCapture{HandleMultiple: "All", Separator: ", ", Limit: 2}, "1. one, 1. two"},
+		{SelectorCapture{HandleMultiple: "Index:2"}, "1. three"},
+		{SelectorCapture{HandleMultiple: "Index:-2"}, "1. four"},
+		{SelectorCapture{HandleMultiple: "Index:9"}, ""},
+		{SelectorCapture{HandleMultiple: "Index:-9"}, ""},
+		{SelectorCapture{HandleMultiple: "First."}, "1. one"},
+		{SelectorCapture{HandleMultiple: "Middle"}, "1. one"},
+		{SelectorCapture{HandleMultiple: "Range:1-2", Separator: " | "}, "1. two | 1. three"},
+		{SelectorCapture{HandleMultiple: "Range:3-9"}, "1. four\n1. five"},
+	}
+
+	for _, c := range cases {
+		c.capture.Template = "1. %s"
+		c.capture.Selectors = []string{"li"}
+		result, err := c.capture.selectorCaptureToString(*doc)
+		if err != nil {
+			t.Errorf("%q: %s", c.capture.HandleMultiple, err)
+		} else if result != c.expect {
+			t.Errorf("%q: expected %q, got %q", c.capture.HandleMultiple, c.expect, result)
+		}
+	}
+}
+
+func TestHandleMultipleRandom(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<p>a</p><p>b</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The last match can be chosen, so both are seen eventually.
+	capture := SelectorCapture{Template: "%s", Selectors: []string{"p"}, HandleMultiple: "Random"}
+	seen := map[string]bool{}
+	for i := 0; i < 200 && len(seen) < 2; i++ {
+		result, _ := capture.selectorCaptureToString(*doc)
+		seen[result] = true
+	}
+
+	if !seen["a"] || !seen["b"] {
+		t.Errorf("Expected Random to choose every match, got %v", seen)
+	}
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// markdownSpecial matches characters that would otherwise be read as Markdown formatting.
+var markdownSpecial = regexp.MustCompile("([\\\\*_~`|>#\\[\\]])")
+
+// blankLines matches runs of blank lines, which are shortened to one.
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+// markdownWrappers are elements shown by wrapping their content, such as bold text.
+var markdownWrappers = map[string]string{
+	"b":      "**",
+	"strong": "**",
+	"i":      "*",
+	"em":     "*",
+	"u":      "__",
+	"s":      "~~",
+	"strike": "~~",
+	"del":    "~~",
+	"h1":     "**",
+	"h2":     "**",
+	"h3":     "**",
+	"h4":     "**",
+	"h5":     "**",
+	"h6":     "**",
+}
+
+// markdownBlocks are elements that start on a new line.
+var markdownBlocks = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "blockquote": true, "table": true, "tr": true,
+	"ul": true, "ol": true, "dl": true, "dt": true, "dd": true, "h1": true, "h2": true, "h3": true,
+	"h4": true, "h5": true, "h6": true, "header": true, "footer": true,
+}
+
+// markdownIgnored are elements whose content is never shown.
+var markdownIgnored = map[string]bool{"script": true, "style": true, "head": true, "template": true}
+
+// htmlToMarkdown converts the content of selection to Markdown, keeping formatting such as bold and italic text,
+// links and lists. Relative links are resolved against base.
+func htmlToMarkdown(selection *goquery.Selection, base string) string {
+	builder := strings.Builder{}
+	for _, node := range selection.Nodes {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			writeMarkdown(&builder, child, base)
+		}
+	}
+
+	// Spaces between elements are collapsed, except in code blocks.
+	lines := strings.Split(builder.String(), "\n")
+	inCode := false
+	for i, line := range lines {
+		if line == "```" {
+			inCode = !inCode
+		} else if !inCode {
+			lines[i] = strings.Join(strings.Fields(line), " ")
+		}
+	}
+
+	markdown := blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(markdown)
+}
+
+// writeMarkdown writes node and its children to builder as Markdown.
+func writeMarkdown(builder *strings.Builder, node *html.Node, base string) {
+	switch node.Type {
+	case html.TextNode:
+		text := strings.Join(strings.Fields(node.Data), " ")
+		if text == "" {
+			if node.Data != "" {
+				builder.WriteString(" ")
+			}
+			return
+		}
+
+		if strings.TrimLeft(node.Data, " \t\r\n") != node.Data {
+			text = " " + text
+		}
+		if strings.TrimRight(node.Data, " \t\r\n") != node.Data {
+			text += " "
+		}
+		builder.WriteString(markdownSpecial.ReplaceAllString(text, `\$1`))
+		return
+	case html.ElementNode:
+	default:
+		writeMarkdownChildren(builder, node, base)
+		return
+	}
+
+	tag := node.Data
+	if markdownIgnored[tag] {
+		return
+	}
+
+	switch tag {
+	case "br":
+		builder.WriteString("\n")
+		return
+	case "hr":
+		builder.WriteString("\n\n---\n\n")
+		return
+	case "img":
+		if alt := attribute(node, "alt"); alt != "" {
+			builder.WriteString(markdownSpecial.ReplaceAllString(alt, `\$1`))
+		}
+		return
+	case "li":
+		builder.WriteString("\n- ")
+		writeMarkdownChildren(builder, node, base)
+		return
+	case "pre":
+		builder.WriteString("\n```\n")
+		builder.WriteString(strings.Trim(nodeText(node), "\n"))
+		builder.WriteString("\n```\n")
+		return
+	case "code":
+		builder.WriteString("`" + strings.ReplaceAll(nodeText(node), "`", "'") + "`")
+		return
+	case "a":
+		href := attribute(node, "href")
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			writeMarkdownChildren(builder, node, base)
+			return
+		}
+
+		text := strings.Builder{}
+		writeMarkdownChildren(&text, node, base)
+		label := strings.TrimSpace(text.String())
+		if label == "" {
+			label = href
+		}
+		builder.WriteString("[" + label + "](" + resolveURL(base, href) + ")")
+		return
+	}
+
+	if markdownBlocks[tag] {
+		builder.WriteString("\n\n")
+		defer builder.WriteString("\n\n")
+	}
+
+	if wrapper, ok := markdownWrappers[tag]; ok {
+		inner := strings.Builder{}
+		writeMarkdownChildren(&inner, node, base)
+		text := inner.String()
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			builder.WriteString(text)
+			return
+		}
+
+		// Markdown formatting can't start or end with a space, so spaces are kept outside it.
+		if strings.HasPrefix(text, " ") {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(wrapper + trimmed + wrapper)
+		if strings.HasSuffix(text, " ") {
+			builder.WriteString(" ")
+		}
+		return
+	}
+
+	writeMarkdownChildren(builder, node, base)
+}
+
+// writeMarkdownChildren writes each child of node to builder as Markdown.
+func writeMarkdownChildren(builder *strings.Builder, node *html.Node, base string) {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		writeMarkdown(builder, child, base)
+	}
+}
+
+// attribute returns the value of the attribute of node with key, or "" if it has none.
+func attribute(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns all the text within node, without changing its spaces.
+func nodeText(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+
+	text := strings.Builder{}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		text.WriteString(nodeText(child))
+	}
+	return text.String()
+}
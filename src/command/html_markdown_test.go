@@ -0,0 +1,38 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	cases := []struct {
+		html   string
+		expect string
+	}{
+		{"plain text", "plain text"},
+		{"a <b>bold</b> and <i>italic</i> word", "a **bold** and *italic* word"},
+		{"<strong> spaced </strong>word", "**spaced** word"},
+		{"<a href=\"/audio/word.mp3\">Listen</a>", "[Listen](https://example.com/audio/word.mp3)"},
+		{"<a href=\"#top\">Top</a>", "Top"},
+		{"<p>First</p>\n\n\n<p>Second<br>line</p>", "First\n\nSecond\nline"},
+		{"<ul>\n  <li>one</li>\n  <li><em>two</em></li>\n</ul>", "- one\n- *two*"},
+		{"2 * 3 = <code>a_b</code>", "2 \\* 3 = `a_b`"},
+		{"<pre>  indented\n    code</pre>", "```\n  indented\n    code\n```"},
+		{"<script>alert(1)</script>shown", "shown"},
+	}
+
+	for _, c := range cases {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<div>" + c.html + "</div>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		result := htmlToMarkdown(doc.Find("div"), "https://example.com/word/page")
+		if result != c.expect {
+			t.Errorf("%q: expected %q, got %q", c.html, c.expect, result)
+		}
+	}
+}
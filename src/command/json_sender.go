@@ -27,6 +27,12 @@ type JSONGetterConfig struct {
 	Delay       int             // If grouped is false, what is the delay between each message sent.
 	Token       TokenMaker      // Often an API requires a calculated API, Token is used to help create a token and append to a URL prior to requests.
 	RateLimit   RateLimitConfig // RateLimit places a limit on how frequently a user can send messages.
+	Color       string          // Color of messages as a hex code, such as "#FF0000".
+	Footer      FieldCapture    // Small text shown at the bottom of messages.
+	Author      FieldCapture    // Who or what messages are from, shown above the title.
+	Thumbnail   FieldCapture    // URL of a small image shown beside messages.
+	Timestamp   FieldCapture    // When the content of messages is from, such as "2021-05-04" or "2021-05-04T03:02:01Z".
+	Paginated   bool            // If grouped is false and this is true, messages are sent as pages instead of one after another, ignoring Delay.
 }
 
 // MessagesFromJSON accepts a dict (which usually represents a JSON) and returns a sequence of messages based on the configuration.
@@ -70,14 +76,31 @@ func (j JSONGetterConfig) MessagesFromJSON(dict map[string]interface{}) (message
 			})
 		}
 	}
+
+	for i := range messages {
+		j.addDetails(dict, &messages[i])
+	}
 	return
 }
 
+// addDetails adds the color, footer, author, thumbnail and timestamp of a config to msg.
+// Details that can't be filled out using dict are left out.
+func (j JSONGetterConfig) addDetails(dict map[string]interface{}, msg *service.Message) {
+	msg.Color, _ = ParseColor(j.Color)
+	msg.Footer, _ = j.Footer.ToStringWithMap(dict)
+	msg.Author.Name, _ = j.Author.ToStringWithMap(dict)
+	msg.ThumbnailURL, _ = j.Thumbnail.ToStringWithMap(dict)
+
+	if timestamp, _ := j.Timestamp.ToStringWithMap(dict); timestamp != "" {
+		msg.Timestamp, _ = ParseTimestamp(timestamp)
+	}
+}
+
 // JSONCapture is a pair of FieldCapture to represent a title, body pair in a message.
 type JSONCapture struct {
-	Title       FieldCapture
-	Body        FieldCapture
-	URLSelector string
+	Title       FieldCapture // The title of a message or field.
+	Body        FieldCapture // The body of a message or field.
+	URLSelector string       // Selector of the URL a title links to.
 }
 
 // MessageField uses a dict (which is a usually a reading of a JSON file), to create a MessageField.
@@ -210,7 +233,12 @@ func (j JSONGetterConfig) jsonGetterFunc(sender service.Conversation, user servi
 		if buf, err := io.ReadAll(jsonReader); err == nil {
 			dict := make(map[string]interface{})
 			if err := json.Unmarshal(buf, &dict); err == nil {
-				for _, msg := range j.MessagesFromJSON(dict) {
+				messages := j.MessagesFromJSON(dict)
+				if j.Paginated && len(messages) > 1 {
+					return sink(sender, service.Paginate(messages))
+				}
+
+				for _, msg := range messages {
 					err := sink(sender, msg)
 					if err != nil {
 						return err
@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
@@ -739,3 +740,73 @@ func TestSpacesInMessage(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestDetails(t *testing.T) {
+	config := JSONGetterConfig{
+		Grouped:   false,
+		Message:   JSONCapture{Title: FieldCapture{Template: "%s", Selectors: []string{"Key1"}}},
+		Fields:    []JSONCapture{{Title: FieldCapture{Template: "title"}, Body: FieldCapture{Template: "%s", Selectors: []string{"Key2"}}}},
+		Color:     "FF0000",
+		Footer:    FieldCapture{Template: "From %s", Selectors: []string{"Key2"}},
+		Author:    FieldCapture{Template: "%s", Selectors: []string{"Author"}},
+		Thumbnail: FieldCapture{Template: "https://example.com/%s.png", Selectors: []string{"Key1"}},
+		Timestamp: FieldCapture{Template: "%s", Selectors: []string{"Date"}},
+	}
+
+	messages := config.MessagesFromJSON(map[string]interface{}{
+		"Key1": "Value1",
+		"Key2": "Value2",
+		"Date": "2021-05-04T03:02:01Z",
+	})
+
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+
+	for _, msg := range messages {
+		if msg.Color != 0xFF0000 || msg.Footer != "From Value2" || msg.ThumbnailURL != "https://example.com/Value1.png" {
+			t.Errorf("Expected details to be added to every message, got %+v", msg)
+		}
+
+		if msg.Author.Name != "" {
+			t.Errorf("Expected a missing author to be left out, got %q", msg.Author.Name)
+		}
+
+		if !msg.Timestamp.Equal(time.Date(2021, 5, 4, 3, 2, 1, 0, time.UTC)) {
+			t.Errorf("Unexpected timestamp %s", msg.Timestamp)
+		}
+	}
+}
+
+func TestUngroupedPaginated(t *testing.T) {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	config := JSONGetterConfig{
+		Grouped:   false,
+		Paginated: true,
+		Delay:     10,
+		Message:   JSONCapture{Title: FieldCapture{Template: "Title"}, Body: FieldCapture{Template: "%s", Selectors: []string{"Key1"}}},
+		Fields:    []JSONCapture{{Title: FieldCapture{Template: "Title"}, Body: FieldCapture{Template: "%s", Selectors: []string{"Key2"}}}},
+		URL:       "%s",
+	}
+
+	getter, err := config.Command(jsonExamples)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := getter.Exec(testConversation, testSender, []interface{}{"example1"}, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	if len(resultMessage.Pages) != 2 || resultMessage.Pages[1].Description != "Value2" {
+		t.Errorf("Expected a message with a page for each message, got %+v", resultMessage)
+	}
+
+	if !demoSender.IsEmpty() {
+		t.Errorf("Expected a single message")
+	}
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are the layouts a timestamp can be read from, in order of preference.
+var timestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+}
+
+// ParseColor reads a color written as a hex code, such as "#FF0000", returning it as RGB.
+// An empty color is 0, which a service shows as its default color.
+func ParseColor(color string) (int, error) {
+	if color == "" {
+		return 0, nil
+	}
+
+	hex := strings.TrimPrefix(color, "#")
+	if len(hex) != 6 {
+		return 0, fmt.Errorf("%q must be a hex code such as \"#FF0000\"", color)
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%q must be a hex code such as \"#FF0000\"", color)
+	}
+	return int(rgb), nil
+}
+
+// ParseTimestamp reads a time written in one of timestampLayouts, such as "2021-05-04" or "2021-05-04T03:02:01Z".
+func ParseTimestamp(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	for _, layout := range timestampLayouts {
+		if timestamp, err := time.Parse(layout, text); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q isn't a recognised date or time", text)
+}
+
+// resolveURL returns ref relative to base, so that a link such as "/image.png" found on a page can be used elsewhere.
+// If either can't be parsed, ref is returned unchanged.
+func resolveURL(base string, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
@@ -0,0 +1,39 @@
+package command
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]int{"": 0, "#FF0000": 0xFF0000, "00ff00": 0x00FF00}
+	for color, expect := range cases {
+		if rgb, err := ParseColor(color); err != nil || rgb != expect {
+			t.Errorf("Expected %q to be %06X, got %06X (%v)", color, expect, rgb, err)
+		}
+	}
+
+	for _, color := range []string{"red", "#FFF", "#GGGGGG"} {
+		if _, err := ParseColor(color); err == nil {
+			t.Errorf("Expected %q to be invalid", color)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	cases := map[string]time.Time{
+		"2021-05-04T03:02:01Z":            time.Date(2021, 5, 4, 3, 2, 1, 0, time.UTC),
+		" 2021-05-04 ":                    time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC),
+		"2021-05-04 03:02:01":             time.Date(2021, 5, 4, 3, 2, 1, 0, time.UTC),
+		"Tue, 04 May 2021 03:02:01 +0000": time.Date(2021, 5, 4, 3, 2, 1, 0, time.UTC),
+	}
+	for text, expect := range cases {
+		if timestamp, err := ParseTimestamp(text); err != nil || !timestamp.Equal(expect) {
+			t.Errorf("Expected %q to be %s, got %s (%v)", text, expect, timestamp, err)
+		}
+	}
+
+	if _, err := ParseTimestamp("yesterday"); err == nil {
+		t.Errorf("Expected an unrecognised time to be an error")
+	}
+}
@@ -0,0 +1,26 @@
+package command
+
+import (
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// NextPageTrigger is the trigger of the command made by NextPageCommand.
+const NextPageTrigger = service.NextPageTrigger
+
+// NextPageCommand returns a command that sends the next page of the pages most recently sent to a
+// conversation. It's for services that can't show controls to change pages, which remember pages
+// using pages.Fallback.
+func NextPageCommand(pages *service.PageStore) Command {
+	return Command{
+		Trigger: NextPageTrigger,
+		Help:    "Shows the next page of the last result with pages.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			page, ok := pages.Next(sender, Prefix(storage, sender))
+			if !ok {
+				return sink(sender, service.Message{Description: "There are no pages to show, they may have expired."})
+			}
+			return sink(sender, page)
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+func TestNextPage(t *testing.T) {
+	pages := service.NewPageStore(service.PageExpiry)
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID, Pages: pages, Prefix: "!"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	if err := _storage.SetDefaultGuildValue(PrefixKey, "!"); err != nil {
+		t.Fatal(err)
+	}
+	next := NextPageCommand(pages)
+
+	err := demoSender.SendMessage(testConversation, service.Paginate([]service.Message{{Title: "One"}, {Title: "Two", Footer: "Footer"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	if resultMessage.Title != "One" || resultMessage.Footer != "Page 1 of 2, use !next to see the next page" || resultMessage.Pages != nil {
+		t.Errorf("Expected the first page, got %+v", resultMessage)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := next.Exec(testConversation, testSender, []interface{}{}, &_storage, demoSender.SendMessage); err != nil {
+			t.Fatal(err)
+		}
+
+		resultMessage, _ = demoSender.PopMessage()
+		if resultMessage.Title != "Two" || resultMessage.Footer != "Page 2 of 2. Footer" {
+			t.Errorf("Expected the last page, got %+v", resultMessage)
+		}
+	}
+}
+
+func TestNextPageWithoutPages(t *testing.T) {
+	pages := service.NewPageStore(service.PageExpiry)
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID, Pages: pages}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+
+	if err := NextPageCommand(pages).Exec(testConversation, testSender, []interface{}{}, &_storage, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	if resultMessage.Description != "There are no pages to show, they may have expired." {
+		t.Errorf("Unexpected message %+v", resultMessage)
+	}
+}
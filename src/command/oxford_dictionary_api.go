@@ -4,27 +4,28 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
 )
 
-// OxfordDictionaryConfig can be turned into a scraper that uses GoQuery.
+// OxfordDictionaryConfig can be turned into a command that translates using the Oxford Dictionaries API.
 type OxfordDictionaryConfig struct {
-	AppID              string
-	AppKey             string
-	Trigger            string
-	HelpText           string
-	HelpInput          string
-	SourceLanguage     string
-	TargetLanguage     string
-	TimesPerInterval   int
-	SecondsPerInterval int
-	Body               string
-	ID                 string
+	AppID              string // Application ID for the Oxford Dictionaries API.
+	AppKey             string // Application key for the Oxford Dictionaries API.
+	Trigger            string // Word which triggers this command to activate.
+	HelpText           string // Help message to display.
+	HelpInput          string // Help message to display for input following command.
+	SourceLanguage     string // Language of words that are looked up, such as "en".
+	TargetLanguage     string // Language to translate to, such as "es".
+	TimesPerInterval   int    // How many times the command can be used per interval.
+	SecondsPerInterval int    // How long is an interval, in seconds.
+	Body               string // Reply when limit is reached.
+	ID                 string // An ID used for storage purposes.
 }
 
 // GetOxfordConfigs retrieves an array of OxfordDictionaryConfig by parsing JSON from a buffer.
@@ -51,7 +52,7 @@ func (o *OxfordDictionaryConfig) Command() (Command, Command, error) {
 		url := fmt.Sprintf("https://od-api.oxforddictionaries.com/api/v2/translations/%s/%s/%s?strictMatch=false", sourceLang, targetLang, url.PathEscape(msg[0].(string)))
 		req, err := http.NewRequest("GET", url, nil)
 		if err != nil {
-			log.Printf("Oxford API error 1: %s", err)
+			slog.Error("oxford api request could not be made", "url", url, "error", err)
 			return sink(
 				sender,
 				service.Message{
@@ -63,9 +64,10 @@ func (o *OxfordDictionaryConfig) Command() (Command, Command, error) {
 		req.Header.Set("app_id", appID)
 		req.Header.Set("app_key", appKey)
 		client := &http.Client{}
+		start := time.Now()
 		resp, err := client.Do(req)
 		if err != nil {
-			log.Printf("Oxford API error 2: %s", err)
+			slog.Warn("upstream request failed", "url", url, "duration", time.Since(start), "error", err)
 			return sink(
 				sender,
 				service.Message{
@@ -74,6 +76,9 @@ func (o *OxfordDictionaryConfig) Command() (Command, Command, error) {
 			)
 		}
 
+		slog.Info("upstream request", "url", url, "status", resp.StatusCode, "duration", time.Since(start))
+		defer resp.Body.Close()
+
 		buf, err := io.ReadAll(resp.Body)
 		if err != nil {
 			return err
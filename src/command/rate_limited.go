@@ -2,7 +2,7 @@ package command
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"math"
 	"sort"
 	"strconv"
@@ -18,7 +18,7 @@ type RateLimitConfig struct {
 	SecondsPerInterval int64  // How long is an interval, in seconds.
 	Body               string // Reply when limit is reached.
 	ID                 string // An ID used for storage purposes.
-	Global             bool
+	Global             bool   // When true, the limit is shared by every user, rather than applying to each user.
 }
 
 // rateLimited returns true if a message should be rate limited.
@@ -51,7 +51,8 @@ func (r RateLimitConfig) timeRemaining(now int64, history []int64) time.Duration
 	result, err := time.ParseDuration(strconv.FormatInt(seconds, 10) + "s")
 
 	if err != nil {
-		log.Panic(err)
+		slog.Error("unable to compute rate limit wait", "error", err)
+		panic(err)
 	}
 
 	return result
@@ -160,14 +161,16 @@ func (r RateLimitConfig) GetRateLimitHistory(storage *storage.Storage, user serv
 		if val, ok := (*storage).GetGlobalValue(r.ID); ok {
 			history, ok = val.([]int64)
 			if !ok {
-				log.Panic("interface type wasn't usable")
+				slog.Error("rate limit history has an unexpected type", "id", r.ID, "type", fmt.Sprintf("%T", val))
+				panic("interface type wasn't usable")
 			}
 		}
 	} else {
 		if val, ok := (*storage).GetUserValue(user, r.ID); ok {
 			history, ok = val.([]int64)
 			if !ok {
-				log.Panic("interface type wasn't usable")
+				slog.Error("rate limit history has an unexpected type", "id", r.ID, "type", fmt.Sprintf("%T", val))
+				panic("interface type wasn't usable")
 			}
 		}
 	}
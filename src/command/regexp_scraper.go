@@ -17,14 +17,14 @@ import (
 // RegexpScraperConfig is a struct that can be made into a command.
 // That Command will process a HTML based on a regexp, to send responses.
 type RegexpScraperConfig struct {
-	Trigger       string
-	Parameters    []Parameter
-	TitleTemplate string // Title template that will be replaced by regex captures (using %s).
-	TitleCapture  string // Regex captures for title replacement.
-	URL           string // A url to scrape from, can contain one "%s" which is replaced with the first capture group.
-	ReplyCapture  string // Regular expression used to parse a webpage.
-	Help          string // Help message to display
-	HelpInput     string // Help message to display for input following command
+	Trigger       string      // Word which triggers this command to activate.
+	Parameters    []Parameter // How to capture words.
+	TitleTemplate string      // Title template that will be replaced by regex captures (using %s).
+	TitleCapture  string      // Regex captures for title replacement.
+	URL           string      // A url to scrape from, can contain one "%s" which is replaced with the first capture group.
+	ReplyCapture  string      // Regular expression used to parse a webpage.
+	Help          string      // Help message to display
+	HelpInput     string      // Help message to display for input following command
 }
 
 // GetRegexpScraperConfigs returns a set of RegexScraperConfig by reading a file.
@@ -46,9 +46,17 @@ func (r RegexpScraperConfig) Command() (Command, error) {
 }
 
 // CommandWithHTMLGetter makes a scraper from a config.
+// An error is returned if either regular expression doesn't compile.
 func (r RegexpScraperConfig) CommandWithHTMLGetter(htmlGetter HTMLGetter) (Command, error) {
-	webpageCapture := regexp.MustCompile(r.ReplyCapture)
-	titleCapture := regexp.MustCompile(r.TitleCapture)
+	webpageCapture, err := regexp.Compile(r.ReplyCapture)
+	if err != nil {
+		return Command{}, fmt.Errorf("ReplyCapture of %s: %w", r.Trigger, err)
+	}
+
+	titleCapture, err := regexp.Compile(r.TitleCapture)
+	if err != nil {
+		return Command{}, fmt.Errorf("TitleCapture of %s: %w", r.Trigger, err)
+	}
 
 	curry := func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
 		return scraper(r.URL,
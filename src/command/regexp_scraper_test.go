@@ -27,6 +27,19 @@ func TestMakeScraper(t *testing.T) {
 	}
 }
 
+func TestMakeScraperBadRegexp(t *testing.T) {
+	config := RegexpScraperConfig{
+		Trigger:      "!",
+		Parameters:   []Parameter{{Type: "string"}},
+		URL:          "%s",
+		ReplyCapture: "<h1>([^<]*</h1>",
+	}
+	_, err := config.Command()
+	if err == nil {
+		t.Errorf("An invalid regular expression was accepted")
+	}
+}
+
 func TestGetBadGetHttp(t *testing.T) {
 	_, _, err := utils.HTMLGetWithHTTP("")
 	if err == nil {
@@ -10,6 +10,17 @@ import (
 // PrefixKey is the key used in storage when storing a prefix.
 const PrefixKey = "prefix"
 
+// Prefix returns the prefix messages in conversation are to be preceded by, or "" if there isn't one.
+func Prefix(storage *storage.Storage, conversation service.Conversation) string {
+	value, ok := (*storage).GetGuildValue(conversation.Guild(), PrefixKey)
+	if !ok {
+		return ""
+	}
+
+	prefix, _ := value.(string)
+	return prefix
+}
+
 // SetPrefix will set the prefix all messages are to be preceded by, for a guild.
 // This uses key "prefix" in storage.
 func SetPrefix(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
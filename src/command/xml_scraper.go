@@ -0,0 +1,266 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// XMLScraperConfig can be turned into a command that reads XML using XPath.
+type XMLScraperConfig struct {
+	Trigger    string            // Word which triggers this command to activate.
+	Parameters []Parameter       // How to capture words.
+	URL        string            // A url to retrieve XML from, each "%s" is replaced with a captured word.
+	URLSuffix  string            // When adding a URL to a message, this string is appended. This is useful for including referral links.
+	ErrorURL   string            // A url to show only when there is an error.
+	HideURL    bool              // When true, a result returns no URL. Use with caution, attribution is often required.
+	Namespaces map[string]string // Prefixes used in XPath expressions, mapped to namespace URIs, such as {"atom": "http://www.w3.org/2005/Atom"}.
+	Title      XPathCapture      // The output message's title.
+	Body       XPathCapture      // The output message's body text.
+	Fields     []XMLFieldCapture // Fields to add to the output message.
+	Color      string            // Color of the output message as a hex code, such as "#FF0000".
+	Help       string            // Help message to display.
+	HelpInput  string            // Help message to display for input following command.
+	RateLimit  RateLimitConfig   // RateLimit places a limit on how frequently a user can send messages.
+}
+
+// XMLFieldCapture is used to have an XPathCapture for a field's title and body.
+type XMLFieldCapture struct {
+	Title XPathCapture // The field's title.
+	Body  XPathCapture // The field's body text.
+}
+
+// XPathCapture will fill out a template string using XML selected with XPath.
+type XPathCapture struct {
+	Template       string   // Message template to be filled out. Every %s in a template is replaced with results of selectors.
+	Selectors      []string // XPath expressions, such as "//entry/title" or "//link/@href". An expression can also give text or a number, such as "count(//entry)".
+	HandleMultiple string   // How to handle multiple matches, as in SelectorCapture.HandleMultiple.
+	Separator      string   // Joins the filled out templates when HandleMultiple is "All" or "Range". Defaults to a new line.
+	Limit          int      // When more than 0, at most this many filled out templates are joined.
+}
+
+// compiledXPathCapture is an XPathCapture with its selectors compiled.
+type compiledXPathCapture struct {
+	XPathCapture
+	handle      handleMultiple
+	expressions []*xpath.Expr
+}
+
+// compile checks an XPathCapture, using namespaces for prefixes in its selectors.
+func (x XPathCapture) compile(namespaces map[string]string) (compiledXPathCapture, error) {
+	handle, err := parseHandleMultiple(x.HandleMultiple)
+	if err != nil {
+		return compiledXPathCapture{}, err
+	}
+
+	expressions := make([]*xpath.Expr, 0, len(x.Selectors))
+	for _, selector := range x.Selectors {
+		expression, err := xpath.CompileWithNS(selector, namespaces)
+		if err != nil {
+			return compiledXPathCapture{}, fmt.Errorf("%q: %w", selector, err)
+		}
+		expressions = append(expressions, expression)
+	}
+
+	return compiledXPathCapture{XPathCapture: x, handle: handle, expressions: expressions}, nil
+}
+
+// compiledXMLField is an XMLFieldCapture with its selectors compiled.
+type compiledXMLField struct {
+	title compiledXPathCapture
+	body  compiledXPathCapture
+}
+
+// CheckXPath returns an error if selector isn't a valid XPath expression, using namespaces for its prefixes.
+func CheckXPath(selector string, namespaces map[string]string) error {
+	_, err := xpath.CompileWithNS(selector, namespaces)
+	return err
+}
+
+// toString matches all selectors in doc and fills out the template, using HandleMultiple to decide which matches are used.
+func (c compiledXPathCapture) toString(doc *xmlquery.Node) string {
+	if len(c.expressions) == 0 || !strings.Contains(c.Template, "%s") {
+		return c.Template
+	}
+
+	allMatches := make([][]string, len(c.expressions))
+	length := math.MaxInt
+	for i, expression := range c.expressions {
+		allMatches[i] = xpathMatches(doc, expression)
+		length = min(length, len(allMatches[i]))
+	}
+
+	indexes := c.handle.indexes(length)
+	if c.Limit > 0 && len(indexes) > c.Limit {
+		indexes = indexes[:c.Limit]
+	}
+
+	replies := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		values := make([]interface{}, len(allMatches))
+		for i, matches := range allMatches {
+			values[i] = ""
+			if index >= 0 && index < len(matches) {
+				values[i] = matches[index]
+			}
+		}
+		replies = append(replies, fmt.Sprintf(c.Template, values...))
+	}
+
+	separator := c.Separator
+	if separator == "" {
+		separator = defaultSeparator
+	}
+	return strings.Join(replies, separator)
+}
+
+// xpathMatches returns the text of each node expression selects in doc.
+// An expression that gives text, a number or a boolean has that as its only match.
+func xpathMatches(doc *xmlquery.Node, expression *xpath.Expr) []string {
+	matches := []string{}
+	switch result := expression.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		for result.MoveNext() {
+			matches = append(matches, strings.TrimSpace(result.Current().Value()))
+		}
+	case string:
+		matches = append(matches, strings.TrimSpace(result))
+	case float64:
+		matches = append(matches, strconv.FormatFloat(result, 'f', -1, 64))
+	case bool:
+		matches = append(matches, strconv.FormatBool(result))
+	}
+	return matches
+}
+
+// Command returns an XML scraper Command from a config, retrieving XML using getter.
+func (x XMLScraperConfig) Command(getter HTMLGetter) (Command, error) {
+	title, err := x.Title.compile(x.Namespaces)
+	if err != nil {
+		return Command{}, fmt.Errorf("Title of %s: %w", x.Trigger, err)
+	}
+
+	body, err := x.Body.compile(x.Namespaces)
+	if err != nil {
+		return Command{}, fmt.Errorf("Body of %s: %w", x.Trigger, err)
+	}
+
+	fields := make([]compiledXMLField, 0, len(x.Fields))
+	for i, field := range x.Fields {
+		fieldTitle, err := field.Title.compile(x.Namespaces)
+		if err != nil {
+			return Command{}, fmt.Errorf("Fields[%d].Title of %s: %w", i, x.Trigger, err)
+		}
+
+		fieldBody, err := field.Body.compile(x.Namespaces)
+		if err != nil {
+			return Command{}, fmt.Errorf("Fields[%d].Body of %s: %w", i, x.Trigger, err)
+		}
+		fields = append(fields, compiledXMLField{title: fieldTitle, body: fieldBody})
+	}
+
+	curry := func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return x.onMessage(sender, msg, sink, getter, title, body, fields)
+	}
+
+	return Command{
+		Trigger:    x.Trigger,
+		Parameters: x.Parameters,
+		Exec:       curry,
+		Help:       x.Help,
+		HelpInput:  x.HelpInput,
+	}, nil
+}
+
+// onMessage retrieves XML for a request, and sends a message made from it.
+func (x XMLScraperConfig) onMessage(
+	sender service.Conversation,
+	msg []interface{},
+	sink func(service.Conversation, service.Message) error,
+	getter HTMLGetter,
+	title compiledXPathCapture,
+	body compiledXPathCapture,
+	fields []compiledXMLField,
+) error {
+	substitutions := strings.Count(x.URL, "%s")
+	if substitutions > len(msg) {
+		return sink(sender, service.Message{Description: "An error occurred when building the url."})
+	}
+
+	msgURL := x.URL
+	for _, word := range msg[:substitutions] {
+		msgURL = strings.Replace(msgURL, "%s", url.PathEscape(word.(string)), 1)
+	}
+
+	redirect, reader, err := getter(msgURL)
+	if err != nil {
+		return sink(sender, service.Message{
+			Title:       "Error",
+			Description: "An error occurred retrieving the webpage.",
+			URL:         msgURL,
+		})
+	}
+	defer reader.Close()
+
+	doc, err := xmlquery.Parse(reader)
+	if err != nil {
+		return sink(sender, service.Message{
+			Title:       msgURL,
+			Description: "An error occurred when processing the webpage.",
+			URL:         x.ErrorURL,
+		})
+	}
+
+	reply := service.Message{
+		Title:       title.toString(doc),
+		Description: body.toString(doc),
+	}
+
+	for _, field := range fields {
+		fieldTitle := field.title.toString(doc)
+		fieldBody := field.body.toString(doc)
+		if fieldTitle != "" && fieldBody != "" {
+			reply.Fields = append(reply.Fields, service.MessageField{Field: fieldTitle, Value: fieldBody, Inline: true})
+		}
+	}
+
+	if reply.Title == "" && reply.Description == "" && len(reply.Fields) == 0 {
+		captures := []string{}
+		for _, item := range msg {
+			captures = append(captures, item.(string))
+		}
+
+		return sink(sender, service.Message{
+			Title:       "Error",
+			Description: fmt.Sprintf("No result was found for \"%s\"", strings.Join(captures, " ")),
+			URL:         x.ErrorURL,
+		})
+	}
+
+	if !x.HideURL {
+		reply.URL = redirect + x.URLSuffix
+	}
+	reply.Color, _ = ParseColor(x.Color)
+	return sink(sender, reply)
+}
+
+// GetXMLScraperConfigs retrieves an array of XMLScraperConfig by parsing JSON from a reader.
+func GetXMLScraperConfigs(reader io.Reader) ([]XMLScraperConfig, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var config []XMLScraperConfig
+	return config, json.Unmarshal(bytes, &config)
+}
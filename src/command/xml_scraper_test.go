@@ -0,0 +1,157 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/google/go-cmp/cmp"
+)
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<title>Word of the day</title>
+	<entry>
+		<title>kumusta</title>
+		<link href="https://example.com/kumusta"/>
+		<summary>How are you?</summary>
+		<dc:creator>Someone</dc:creator>
+	</entry>
+	<entry>
+		<title>salamat</title>
+		<link href="https://example.com/salamat"/>
+		<summary>Thank you</summary>
+		<dc:creator>Someone else</dc:creator>
+	</entry>
+</feed>`
+
+// xmlGetter returns a HTMLGetter that returns content for any URL, remembering the last URL requested.
+func xmlGetter(content string, requested *string) HTMLGetter {
+	return func(url string) (string, io.ReadCloser, error) {
+		*requested = url
+		return url, io.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+// execXMLScraper runs an XMLScraperConfig made with getter, returning the message it sent.
+func execXMLScraper(t *testing.T, config XMLScraperConfig, getter HTMLGetter, words ...interface{}) service.Message {
+	demoSender := demoservice.DemoSender{}
+	testConversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0"}
+	testSender := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	scraper, err := config.Command(getter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scraper.Exec(testConversation, testSender, words, nil, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	resultMessage, _ := demoSender.PopMessage()
+	return resultMessage
+}
+
+func TestXMLScraper(t *testing.T) {
+	requested := ""
+	config := XMLScraperConfig{
+		Trigger:    "feed",
+		Parameters: []Parameter{{Type: "string"}},
+		URL:        "https://example.com/%s.xml",
+		Namespaces: map[string]string{"atom": "http://www.w3.org/2005/Atom", "dc": "http://purl.org/dc/elements/1.1/"},
+		Title:      XPathCapture{Template: "%s", Selectors: []string{"//atom:entry/atom:title"}},
+		Body:       XPathCapture{Template: "%s (%s)", Selectors: []string{"//atom:entry/atom:summary", "//atom:entry/dc:creator"}, HandleMultiple: "Last"},
+		Fields: []XMLFieldCapture{
+			{Title: XPathCapture{Template: "Link"}, Body: XPathCapture{Template: "%s", Selectors: []string{"//atom:entry/atom:link/@href"}}},
+			{Title: XPathCapture{Template: "Entries"}, Body: XPathCapture{Template: "%s", Selectors: []string{"count(//atom:entry)"}}},
+			{Title: XPathCapture{Template: "All"}, Body: XPathCapture{Template: "- %s", Selectors: []string{"//atom:entry/atom:title"}, HandleMultiple: "All"}},
+			{Title: XPathCapture{Template: "Missing"}, Body: XPathCapture{Template: "%s", Selectors: []string{"//atom:missing"}}},
+		},
+		Color:     "#0000FF",
+		URLSuffix: "?ref=boby",
+	}
+
+	resultMessage := execXMLScraper(t, config, xmlGetter(atomFeed, &requested), "words")
+	if requested != "https://example.com/words.xml" {
+		t.Errorf("Unexpected URL requested %s", requested)
+	}
+
+	expect := service.Message{
+		Title:       "kumusta",
+		Description: "Thank you (Someone else)",
+		URL:         "https://example.com/words.xml?ref=boby",
+		Color:       0x0000FF,
+		Fields: []service.MessageField{
+			{Field: "Link", Value: "https://example.com/kumusta", Inline: true},
+			{Field: "Entries", Value: "2", Inline: true},
+			{Field: "All", Value: "- kumusta\n- salamat", Inline: true},
+		},
+	}
+
+	if diff := cmp.Diff(expect, resultMessage); diff != "" {
+		t.Errorf("Unexpected message: %s", diff)
+	}
+}
+
+func TestXMLScraperNoResult(t *testing.T) {
+	requested := ""
+	config := XMLScraperConfig{
+		URL:     "https://example.com/feed.xml",
+		Title:   XPathCapture{Template: "%s", Selectors: []string{"//missing"}},
+		Body:    XPathCapture{Template: "%s", Selectors: []string{"//missing"}},
+		HideURL: true,
+	}
+
+	resultMessage := execXMLScraper(t, config, xmlGetter(atomFeed, &requested), "word")
+	if resultMessage.Title != "Error" || !strings.Contains(resultMessage.Description, "word") {
+		t.Errorf("Expected no result to be reported, got %+v", resultMessage)
+	}
+}
+
+func TestXMLScraperErrors(t *testing.T) {
+	failing := func(url string) (string, io.ReadCloser, error) {
+		return "", nil, fmt.Errorf("unreachable")
+	}
+
+	config := XMLScraperConfig{URL: "https://example.com/%s.xml", Title: XPathCapture{Template: "Title"}}
+	if resultMessage := execXMLScraper(t, config, failing, "word"); resultMessage.Title != "Error" {
+		t.Errorf("Expected a getter error to be reported, got %+v", resultMessage)
+	}
+
+	if resultMessage := execXMLScraper(t, config, failing); resultMessage.Description != "An error occurred when building the url." {
+		t.Errorf("Expected a missing word to be reported, got %+v", resultMessage)
+	}
+
+	requested := ""
+	if resultMessage := execXMLScraper(t, config, xmlGetter("<unclosed", &requested), "word"); !strings.Contains(resultMessage.Description, "processing") {
+		t.Errorf("Expected invalid XML to be reported, got %+v", resultMessage)
+	}
+}
+
+func TestXMLScraperInvalidSelector(t *testing.T) {
+	configs := []XMLScraperConfig{
+		{Title: XPathCapture{Template: "%s", Selectors: []string{"//entry["}}},
+		{Body: XPathCapture{Template: "%s", Selectors: []string{"count(//entry"}}},
+		{Fields: []XMLFieldCapture{{Body: XPathCapture{HandleMultiple: "Middle"}}}},
+	}
+
+	for _, config := range configs {
+		if _, err := config.Command(nil); err == nil {
+			t.Errorf("Expected an error for %+v", config)
+		}
+	}
+}
+
+func TestGetXMLScraperConfigs(t *testing.T) {
+	configs, err := GetXMLScraperConfigs(strings.NewReader(`[{"Trigger": "feed", "Namespaces": {"atom": "http://www.w3.org/2005/Atom"}}]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(configs) != 1 || configs[0].Namespaces["atom"] != "http://www.w3.org/2005/Atom" {
+		t.Errorf("Unexpected configs %+v", configs)
+	}
+}
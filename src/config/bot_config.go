@@ -0,0 +1,289 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service/discordservice"
+	"github.com/BKrajancic/boby/m/v2/src/utils"
+)
+
+// unifiedFilepaths are the names of a single file holding the whole configuration,
+// in the order they are looked for.
+var unifiedFilepaths = []string{"boby.yaml", "boby.yml", "boby.toml", "boby.json"}
+
+// BotConfig is everything needed to run the bot.
+// It is read either from a single unified file, or from a file per type of command.
+// A missing section means there are no commands of that type.
+type BotConfig struct {
+	Settings                                         // How the bot runs.
+	Discord         discordservice.DiscordConfig     // Token and other Discord settings.
+	JSONGetters     []command.JSONGetterConfig       // Commands that read from JSON APIs.
+	RegexpScrapers  []command.RegexpScraperConfig    // Commands that scrape webpages using regular expressions.
+	GoQueryScrapers []command.GoQueryScraperConfig   // Commands that scrape webpages using CSS selectors.
+	Oxford          []command.OxfordDictionaryConfig // Commands that use the Oxford Dictionary API.
+	XMLScrapers     []command.XMLScraperConfig       // Commands that read XML using XPath.
+	Dailies         []command.DailyConfig            // Commands that choose a word, such as a word of the day, and give it to another command.
+	Admin           command.AdminConfig              // Whether admin commands are available.
+}
+
+// UnifiedFilepath returns the path of the unified configuration file in configDir,
+// and whether one exists.
+func UnifiedFilepath(configDir string) (string, bool) {
+	for _, filename := range unifiedFilepaths {
+		filepath := path.Join(configDir, filename)
+		if _, err := os.Stat(filepath); err == nil {
+			return filepath, true
+		}
+	}
+	return "", false
+}
+
+// LoadBotConfig reads the configuration in configDir.
+// A unified file (boby.yaml, boby.yml, boby.toml or boby.json) is used if one exists,
+// otherwise a file for each type of command is read.
+func LoadBotConfig(configDir string) (BotConfig, error) {
+	var botConfig BotConfig
+	var err error
+	if unified, ok := UnifiedFilepath(configDir); ok {
+		botConfig, err = ReadUnifiedConfig(unified)
+		if err != nil {
+			return botConfig, fmt.Errorf("%s: %w", path.Base(unified), err)
+		}
+	} else {
+		botConfig, err = readLegacyConfig(configDir)
+		if err != nil {
+			return botConfig, err
+		}
+	}
+
+	botConfig.resolvePaths(configDir)
+	return botConfig, nil
+}
+
+// resolvePaths makes paths to files in the configuration relative to configDir, rather than the working directory.
+func (b *BotConfig) resolvePaths(configDir string) {
+	for i, daily := range b.Dailies {
+		if daily.WordsFile != "" && !path.IsAbs(daily.WordsFile) {
+			b.Dailies[i].WordsFile = path.Join(configDir, daily.WordsFile)
+		}
+	}
+}
+
+// ReadUnifiedConfig reads a BotConfig from a YAML, TOML or JSON file, chosen by its extension.
+// Strings can contain ${NAME}, which is replaced with the environment variable NAME.
+func ReadUnifiedConfig(filepath string) (BotConfig, error) {
+	var botConfig BotConfig
+	bytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return botConfig, err
+	}
+
+	bytes, err = unifiedToJSON(filepath, bytes)
+	if err != nil {
+		return botConfig, err
+	}
+
+	return botConfig, json.Unmarshal(bytes, &botConfig)
+}
+
+// unifiedToJSON converts the contents of a unified file to JSON, interpolating environment variables.
+func unifiedToJSON(filepath string, bytes []byte) ([]byte, error) {
+	var value interface{}
+	switch strings.ToLower(path.Ext(filepath)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(bytes, &value); err != nil {
+			return nil, err
+		}
+	case ".toml":
+		if _, err := toml.Decode(string(bytes), &value); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.Unmarshal(bytes, &value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown configuration format %q", path.Ext(filepath))
+	}
+
+	value, err := normalize("$", value)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		// An empty file is an empty configuration.
+		value = map[string]interface{}{}
+	}
+
+	return json.Marshal(value)
+}
+
+// envPattern matches ${NAME}, where NAME is an environment variable.
+var envPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// An EnvError is returned when a configuration refers to an environment variable that isn't set.
+type EnvError struct {
+	Path string // JSON path to the string that refers to the variable, such as "$.Discord.Token".
+	Name string // Name of the variable.
+}
+
+// Error formats an EnvError as "path: environment variable NAME is not set".
+func (e EnvError) Error() string {
+	return fmt.Sprintf("%s: environment variable %s is not set", e.Path, e.Name)
+}
+
+// interpolate replaces each ${NAME} in text with the environment variable NAME.
+// An error is returned if a variable isn't set, as a missing secret should stop the bot from starting.
+func interpolate(jsonPath string, text string) (string, error) {
+	var err error
+	result := envPattern.ReplaceAllStringFunc(text, func(match string) string {
+		name := envPattern.FindStringSubmatch(match)[1]
+		value, ok := os.LookupEnv(name)
+		if !ok && err == nil {
+			err = EnvError{Path: jsonPath, Name: name}
+		}
+		return value
+	})
+	return result, err
+}
+
+// normalize interpolates every string in value, and converts maps so they can be
+// marshalled to JSON (YAML can decode maps with non-string keys).
+// jsonPath is where value is, and is used to describe errors.
+func normalize(jsonPath string, value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case string:
+		return interpolate(jsonPath, value)
+	case map[string]interface{}:
+		for key, item := range value {
+			normalized, err := normalize(jsonPath+"."+key, item)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = normalized
+		}
+		return value, nil
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(value))
+		for key, item := range value {
+			converted[fmt.Sprint(key)] = item
+		}
+		return normalize(jsonPath, converted)
+	case []interface{}:
+		for i, item := range value {
+			normalized, err := normalize(fmt.Sprintf("%s[%d]", jsonPath, i), item)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = normalized
+		}
+		return value, nil
+	case []map[string]interface{}:
+		// TOML decodes arrays of tables to this type.
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = item
+		}
+		return normalize(jsonPath, items)
+	default:
+		return value, nil
+	}
+}
+
+// Getters are used by commands to retrieve webpages and JSON.
+type Getters struct {
+	HTML command.HTMLGetter // Used by scrapers, including XML scrapers.
+	JSON command.JSONGetter // Used by JSON getters.
+}
+
+// DefaultGetters make requests using HTTP.
+var DefaultGetters = Getters{HTML: utils.HTMLGetWithHTTP, JSON: utils.JSONGetWithHTTP}
+
+// Commands returns the commands described by this configuration.
+func (b BotConfig) Commands() ([]command.Command, error) {
+	return b.CommandsWithGetters(DefaultGetters)
+}
+
+// CommandsWithGetters returns the commands described by this configuration,
+// which retrieve webpages and JSON using getters.
+func (b BotConfig) CommandsWithGetters(getters Getters) ([]command.Command, error) {
+	commands := []command.Command{}
+
+	for i, jsonGetter := range b.JSONGetters {
+		command, err := jsonGetter.Command(getters.JSON)
+		if err != nil {
+			return commands, fmt.Errorf("JSONGetters[%d]: %w", i, err)
+		}
+		commands = append(commands, jsonGetter.RateLimit.GetRateLimitedCommand(command))
+	}
+
+	for i, regexScraperConfig := range b.RegexpScrapers {
+		command, err := regexScraperConfig.CommandWithHTMLGetter(getters.HTML)
+		if err != nil {
+			return commands, fmt.Errorf("RegexpScrapers[%d]: %w", i, err)
+		}
+		commands = append(commands, command)
+	}
+
+	for i, goqueryScraperConfig := range b.GoQueryScrapers {
+		scraperCommand, err := goqueryScraperConfig.CommandWithHTMLGetter(getters.HTML)
+		if err != nil {
+			return commands, fmt.Errorf("GoQueryScrapers[%d]: %w", i, err)
+		}
+		commands = append(commands, scraperCommand)
+	}
+
+	for i, xmlScraperConfig := range b.XMLScrapers {
+		xmlCommand, err := xmlScraperConfig.Command(getters.HTML)
+		if err != nil {
+			return commands, fmt.Errorf("XMLScrapers[%d]: %w", i, err)
+		}
+		commands = append(commands, xmlScraperConfig.RateLimit.GetRateLimitedCommand(xmlCommand))
+	}
+
+	for i, oxfordConfig := range b.Oxford {
+		oxfordCommand, oxfordCommandInfo, err := oxfordConfig.Command()
+		if err != nil {
+			return commands, fmt.Errorf("Oxford[%d]: %w", i, err)
+		}
+		commands = append(commands, oxfordCommand, oxfordCommandInfo)
+	}
+
+	if b.Admin.Enabled {
+		commands = append(commands, command.AdminCommands()...)
+	}
+
+	// TODO: Helptext is hardcoded for discord, and is therefore a leaky abstraction.
+	renderCmd := command.Command{
+		Trigger: "render",
+		Parameters: []command.Parameter{{
+			Type:        "string",
+			Name:        "message",
+			Description: "text to render",
+		}},
+		Help:      "render text as image",
+		HelpInput: "input help",
+		Exec:      command.RenderText,
+	}
+	commands = append(commands, renderCmd)
+
+	// Dailies are made last, as they run other commands.
+	for i, dailyConfig := range b.Dailies {
+		dailyCommand, err := dailyConfig.Command(getters.HTML, commands)
+		if err != nil {
+			return commands, fmt.Errorf("Dailies[%d]: %w", i, err)
+		}
+		commands = append(commands, dailyCommand)
+	}
+	return commands, nil
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"errors"
+	"path"
+	"testing"
+)
+
+const unifiedYAML = `
+Discord:
+  Token: ${BOBY_TEST_TOKEN}
+Logging:
+  Level: debug
+GoQueryScrapers:
+  - Trigger: word
+    URL: https://example.com/%s
+    Help: Look up a word.
+    Parameters:
+      - Type: string
+        Name: word
+        Description: Word to look up.
+Oxford:
+  - Trigger: ox
+    AppKey: ${BOBY_TEST_KEY}
+XMLScrapers:
+  - Trigger: feed
+    URL: https://example.com/feed.xml
+    Help: Show the latest entry.
+    Namespaces:
+      atom: http://www.w3.org/2005/Atom
+    Title:
+      Template: "%s"
+      Selectors: ["//atom:entry/atom:title"]
+`
+
+const unifiedTOML = `
+[Discord]
+Token = "${BOBY_TEST_TOKEN}"
+
+[[GoQueryScrapers]]
+Trigger = "word"
+URL = "https://example.com/%s"
+Help = "Look up a word."
+
+[[GoQueryScrapers.Parameters]]
+Type = "string"
+Name = "word"
+Description = "Word to look up."
+`
+
+func TestLoadBotConfigYAML(t *testing.T) {
+	t.Setenv("BOBY_TEST_TOKEN", "secret")
+	t.Setenv("BOBY_TEST_KEY", "key")
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", unifiedYAML)
+
+	botConfig, err := LoadBotConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if botConfig.Discord.Token != "secret" || botConfig.Oxford[0].AppKey != "key" {
+		t.Errorf("Expected environment variables to be interpolated, got %+v", botConfig)
+	}
+
+	if botConfig.Logging.Level != "debug" {
+		t.Errorf("Expected settings to be read, got %+v", botConfig.Settings)
+	}
+
+	if len(botConfig.GoQueryScrapers) != 1 || botConfig.GoQueryScrapers[0].Parameters[0].Name != "word" {
+		t.Errorf("Expected a goquery scraper, got %+v", botConfig.GoQueryScrapers)
+	}
+
+	if len(botConfig.XMLScrapers) != 1 || botConfig.XMLScrapers[0].Namespaces["atom"] != "http://www.w3.org/2005/Atom" {
+		t.Errorf("Expected an XML scraper, got %+v", botConfig.XMLScrapers)
+	}
+}
+
+func TestLoadBotConfigTOML(t *testing.T) {
+	t.Setenv("BOBY_TEST_TOKEN", "secret")
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.toml", unifiedTOML)
+
+	botConfig, err := LoadBotConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if botConfig.Discord.Token != "secret" {
+		t.Errorf("Expected a token, got %+v", botConfig.Discord)
+	}
+
+	if len(botConfig.GoQueryScrapers) != 1 || len(botConfig.GoQueryScrapers[0].Parameters) != 1 {
+		t.Errorf("Expected a goquery scraper with a parameter, got %+v", botConfig.GoQueryScrapers)
+	}
+}
+
+func TestLoadBotConfigMissingSections(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.json", `{"Discord": {"Token": "token"}}`)
+
+	commands, err := ConfiguredBot(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(commands) != 1 || !hasTrigger(commands, "render") {
+		t.Errorf("Expected only built in commands, got %d commands", len(commands))
+	}
+}
+
+func TestLoadBotConfigPrefersUnified(t *testing.T) {
+	t.Setenv("BOBY_TEST_TOKEN", "secret")
+	t.Setenv("BOBY_TEST_KEY", "key")
+	dir := writeConfigDir(t, "legacy")
+	writeFile(t, dir, "boby.yml", unifiedYAML)
+
+	commands, err := ConfiguredBot(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hasTrigger(commands, "legacy") || !hasTrigger(commands, "word") {
+		t.Errorf("Expected commands from the unified file only")
+	}
+}
+
+func TestLoadBotConfigMissingEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", unifiedYAML)
+
+	_, err := LoadBotConfig(dir)
+	var envErr EnvError
+	if !errors.As(err, &envErr) {
+		t.Fatalf("Expected an EnvError, got %v", err)
+	}
+
+	if envErr.Name != "BOBY_TEST_TOKEN" && envErr.Name != "BOBY_TEST_KEY" {
+		t.Errorf("Unexpected variable %s", envErr.Name)
+	}
+}
+
+func TestGetSettingsUnified(t *testing.T) {
+	t.Setenv("BOBY_TEST_TOKEN", "secret")
+	t.Setenv("BOBY_TEST_KEY", "key")
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", unifiedYAML)
+
+	settings, err := GetSettings(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if settings.Logging.Level != "debug" {
+		t.Errorf("Expected settings from the unified file, got %+v", settings)
+	}
+}
+
+func TestValidateUnified(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", `
+Discord:
+  Token: token
+GoQueryScrapers:
+  - Trigger: Word
+    URL: https://example.com
+    Help: Look up a word.
+    Unknown: true
+`)
+
+	problems := Validate(dir)
+	if _, ok := findProblem(problems, "boby.yaml", "$.GoQueryScrapers[0].Unknown"); !ok {
+		t.Errorf("Expected the unknown field to be found, got %v", problems)
+	}
+}
+
+func TestValidateUnifiedMissingEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", "Discord:\n  Token: ${BOBY_TEST_TOKEN}\n")
+
+	problems := Validate(dir)
+	if _, ok := findProblem(problems, "boby.yaml", "$.Discord.Token"); !ok {
+		t.Errorf("Expected the missing variable to be found, got %v", problems)
+	}
+}
+
+func TestValidateUnifiedSettings(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "boby.yaml", "Discord:\n  Token: token\nLogging:\n  Level: loud\n")
+
+	problems := Validate(dir)
+	if _, ok := findProblem(problems, "boby.yaml", "$.Logging.Level"); !ok {
+		t.Errorf("Expected the invalid level to be found, got %v", problems)
+	}
+}
+
+func TestLoadLegacyXMLScrapers(t *testing.T) {
+	dir := writeConfigDir(t, "word")
+	botConfig, err := LoadBotConfig(dir)
+	if err != nil || botConfig.XMLScrapers != nil {
+		t.Fatalf("Expected xml_scraper_config.json to be optional, got %v", err)
+	}
+
+	writeFile(t, dir, xmlFilepath, `[{"Trigger": "feed", "URL": "https://example.com/feed.xml", "Help": "Show the latest entry."}]`)
+	commands, err := ConfiguredBotWithGetters(dir, nil, DefaultGetters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasTrigger(commands, "feed") || !hasTrigger(commands, "word") {
+		t.Errorf("Expected the XML scraper to be loaded alongside other commands")
+	}
+}
+
+func TestLoadLegacyDailies(t *testing.T) {
+	dir := writeConfigDir(t, "word")
+	writeFile(t, dir, dailyFilepath, `[{"Trigger": "wotd", "Target": "word", "WordsFile": "words.txt", "Help": "Look up a word every day."}]`)
+
+	botConfig, err := LoadBotConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(botConfig.Dailies) != 1 || botConfig.Dailies[0].WordsFile != path.Join(dir, "words.txt") {
+		t.Errorf("Expected WordsFile to be relative to the configuration directory, got %+v", botConfig.Dailies)
+	}
+
+	commands, err := ConfiguredBotWithGetters(dir, nil, DefaultGetters)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hasTrigger(commands, "wotd") {
+		t.Errorf("Expected the daily command to be loaded")
+	}
+
+	writeFile(t, dir, dailyFilepath, `[{"Trigger": "wotd", "Target": "missing", "Words": ["a"], "Help": "help"}]`)
+	if _, err := ConfiguredBotWithGetters(dir, nil, DefaultGetters); err == nil {
+		t.Errorf("Expected a daily with a missing target to be rejected")
+	}
+}
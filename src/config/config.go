@@ -3,14 +3,14 @@ package config
 import (
 	"bufio"
 	"encoding/json"
+	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path"
 
 	"github.com/BKrajancic/boby/m/v2/src/command"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
-	"github.com/BKrajancic/boby/m/v2/src/utils"
 )
 
 const adminConfigFilepath = "admin_config.json"
@@ -18,33 +18,40 @@ const jsonFilepath = "json_getter_config.json"
 const regexpFilepath = "regexp_scraper_config.json"
 const goqueryFilepath = "goquery_scraper_config.json"
 const oxfordFilepath = "oxford_config.json"
+const xmlFilepath = "xml_scraper_config.json"
+const dailyFilepath = "daily_config.json"
+
+// configFilepaths are the files in a configuration directory that describe commands.
+var configFilepaths = append([]string{
+	adminConfigFilepath,
+	jsonFilepath,
+	regexpFilepath,
+	goqueryFilepath,
+	oxfordFilepath,
+	xmlFilepath,
+	dailyFilepath,
+}, unifiedFilepaths...)
 
 // MakeExampleDir makes an example folder with example config files.
 func MakeExampleDir(dir string) error {
-	log.Printf(
-		"A Folder \"%s\" will be created, which contains example configuration files.\n",
-		dir,
-	)
-	log.Println("The configuration files can be edited, and the folder can be used to run this software.")
-	log.Printf("For information on editing configuration files, "+
-		"make sure to read the documentation at %s, or this project's readme.md file.",
-		command.Repo)
+	slog.Info("creating a folder of example configuration files, which can be edited and used to run this software",
+		"folder", dir, "documentation", command.Repo)
 
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		err = os.Mkdir(dir, 0755)
 		if err != nil {
-			log.Printf("There was an error creating the folder. Example configuration files will not be created.")
+			slog.Error("unable to create the folder, example configuration files will not be created", "folder", dir, "error", err)
 			return err
 		}
 	} else {
-		log.Printf("\nThe folder \"%s\" already exists, example configuration files will not be created.", dir)
+		slog.Warn("the folder already exists, example configuration files will not be created", "folder", dir)
 		return err
 	}
 
 	jsonGetters := []command.JSONGetterConfig{
 		{
 			Trigger:    "cmd",
-			Parameters: []command.Parameter{{Type: "string"}},
+			Parameters: []command.Parameter{{Type: "string", Name: "word", Description: "Word to look up."}},
 			Message: command.JSONCapture{
 				Title: command.FieldCapture{
 					Template:  "Title: %s",
@@ -68,7 +75,7 @@ func MakeExampleDir(dir string) error {
 				},
 			},
 			Grouped:   true,
-			URL:       "https://",
+			URL:       "https://example.com/%s",
 			Help:      "Help message for cmd.",
 			HelpInput: "word",
 			RateLimit: command.RateLimitConfig{
@@ -83,11 +90,11 @@ func MakeExampleDir(dir string) error {
 	regexpGetters := []command.RegexpScraperConfig{
 		{
 			Trigger:       "rx",
-			Parameters:    []command.Parameter{{Type: "string"}},
+			Parameters:    []command.Parameter{{Type: "string", Name: "sentence", Description: "Sentence to look up."}},
 			TitleTemplate: "Title: %s",
 			TitleCapture:  "<h1>(.*)</h1>",
 			ReplyCapture:  "<h1>(.*)</h1>",
-			URL:           "https://",
+			URL:           "https://example.com/%s",
 			Help:          "Help message for rx.",
 			HelpInput:     "[sentence]",
 		},
@@ -97,7 +104,7 @@ func MakeExampleDir(dir string) error {
 		{
 			Title:      "Title",
 			Trigger:    "gq",
-			Parameters: []command.Parameter{{Type: "string"}},
+			Parameters: []command.Parameter{{Type: "string", Name: "sentence", Description: "Sentence to look up."}},
 			TitleSelector: command.SelectorCapture{
 				Template:       "Title: %s",
 				Selectors:      []string{".titlefield"},
@@ -109,7 +116,7 @@ func MakeExampleDir(dir string) error {
 				Replacements:   []map[string]string{{"Heading": "Title"}},
 				HandleMultiple: "Random",
 			},
-			URL:       "https://",
+			URL:       "https://example.com/%s",
 			Help:      "Help message for rx.",
 			HelpInput: "[@sentence]",
 		},
@@ -160,116 +167,116 @@ func MakeExampleDir(dir string) error {
 // ConfiguredBot uses files in configDir to return a bot ready for usage.
 // This bot is not attached to any storage or services.
 func ConfiguredBot(configDir string, storage *storage.Storage) ([]command.Command, error) {
-	commands := []command.Command{}
+	return ConfiguredBotWithGetters(configDir, storage, DefaultGetters)
+}
 
-	file, err := os.Open(path.Join(configDir, jsonFilepath))
+// ConfiguredBotWithGetters is ConfiguredBot, where commands retrieve webpages and JSON using getters.
+func ConfiguredBotWithGetters(configDir string, storage *storage.Storage, getters Getters) ([]command.Command, error) {
+	botConfig, err := LoadBotConfig(configDir)
 	if err != nil {
-		return commands, err
+		return []command.Command{}, err
 	}
 
-	bytes, err := io.ReadAll(file)
-	if err != nil {
-		return commands, err
-	}
+	return botConfig.CommandsWithGetters(getters)
+}
 
-	// Get JSON getters.
-	var jsonGetters []command.JSONGetterConfig
-	if err = json.Unmarshal(bytes, &jsonGetters); err != nil {
-		return commands, err
-	}
+// readLegacyConfig reads a BotConfig from a directory that has a file for each type of command.
+// Every file must exist, except config.json, xml_scraper_config.json and daily_config.json.
+func readLegacyConfig(configDir string) (BotConfig, error) {
+	var botConfig BotConfig
 
-	for _, jsonGetter := range jsonGetters {
-		command, err := jsonGetter.Command(utils.JSONGetWithHTTP)
-		if err != nil {
-			return commands, err
+	bytes, err := os.ReadFile(path.Join(configDir, settingsFilepath))
+	if err == nil {
+		if err := json.Unmarshal(bytes, &botConfig.Settings); err != nil {
+			return botConfig, fmt.Errorf("%s: %w", settingsFilepath, err)
 		}
-		newCommand := jsonGetter.RateLimit.GetRateLimitedCommand(command)
-		commands = append(commands, newCommand)
+		if err := json.Unmarshal(bytes, &botConfig.Discord); err != nil {
+			return botConfig, fmt.Errorf("%s: %w", settingsFilepath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return botConfig, fmt.Errorf("%s: %w", settingsFilepath, err)
 	}
 
-	// Get regex scraper.
-	file, err = os.Open(path.Join(configDir, regexpFilepath))
+	file, err := os.Open(path.Join(configDir, jsonFilepath))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", jsonFilepath, err)
 	}
+	defer file.Close()
 
-	regexScraperConfigs, err := command.GetRegexpScraperConfigs(bufio.NewReader(file))
+	bytes, err = io.ReadAll(file)
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", jsonFilepath, err)
 	}
 
-	for _, regexScraperConfig := range regexScraperConfigs {
-		command, err := regexScraperConfig.Command()
-		if err != nil {
-			return commands, err
-		}
-		commands = append(commands, command)
+	if err = json.Unmarshal(bytes, &botConfig.JSONGetters); err != nil {
+		return botConfig, fmt.Errorf("%s: %w", jsonFilepath, err)
 	}
 
-	file, err = os.Open(path.Join(configDir, goqueryFilepath))
+	file, err = os.Open(path.Join(configDir, regexpFilepath))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", regexpFilepath, err)
 	}
+	defer file.Close()
 
-	goqueryScraperConfigs, err := command.GetGoqueryScraperConfigs(bufio.NewReader(file))
+	botConfig.RegexpScrapers, err = command.GetRegexpScraperConfigs(bufio.NewReader(file))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", regexpFilepath, err)
 	}
 
-	for _, goqueryScraperConfig := range goqueryScraperConfigs {
-		scraperCommand, err := goqueryScraperConfig.Command()
-		if err != nil {
-			return commands, err
-		}
-		commands = append(commands, scraperCommand)
+	file, err = os.Open(path.Join(configDir, goqueryFilepath))
+	if err != nil {
+		return botConfig, fmt.Errorf("%s: %w", goqueryFilepath, err)
 	}
+	defer file.Close()
 
-	// Oxford
-	file, err = os.Open(path.Join(configDir, oxfordFilepath))
+	botConfig.GoQueryScrapers, err = command.GetGoqueryScraperConfigs(bufio.NewReader(file))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", goqueryFilepath, err)
 	}
 
-	oxfordConfigs, err := command.GetOxfordConfigs(bufio.NewReader(file))
+	file, err = os.Open(path.Join(configDir, oxfordFilepath))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", oxfordFilepath, err)
 	}
+	defer file.Close()
 
-	for _, oxfordConfig := range oxfordConfigs {
-		oxfordCommand, oxfordCommandInfo, err := oxfordConfig.Command()
-		if err != nil {
-			return commands, err
-		}
-		commands = append(commands, oxfordCommand)
-		commands = append(commands, oxfordCommandInfo)
+	botConfig.Oxford, err = command.GetOxfordConfigs(bufio.NewReader(file))
+	if err != nil {
+		return botConfig, fmt.Errorf("%s: %w", oxfordFilepath, err)
 	}
 
 	file, err = os.Open(path.Join(configDir, adminConfigFilepath))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", adminConfigFilepath, err)
 	}
+	defer file.Close()
 
-	adminConfig, err := command.GetAdminConfigs(bufio.NewReader(file))
+	botConfig.Admin, err = command.GetAdminConfigs(bufio.NewReader(file))
 	if err != nil {
-		return commands, err
+		return botConfig, fmt.Errorf("%s: %w", adminConfigFilepath, err)
 	}
-	if adminConfig.Enabled {
-		commands = append(commands, command.AdminCommands()...)
+
+	file, err = os.Open(path.Join(configDir, xmlFilepath))
+	if err == nil {
+		defer file.Close()
+		botConfig.XMLScrapers, err = command.GetXMLScraperConfigs(bufio.NewReader(file))
+		if err != nil {
+			return botConfig, fmt.Errorf("%s: %w", xmlFilepath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return botConfig, fmt.Errorf("%s: %w", xmlFilepath, err)
 	}
 
-	// TODO: Helptext is hardcoded for discord, and is therefore a leaky abstraction.
-	renderCmd := command.Command{
-		Trigger: "render",
-		Parameters: []command.Parameter{{
-			Type:        "string",
-			Name:        "message",
-			Description: "text to render",
-		}},
-		Help:      "render text as image",
-		HelpInput: "input help",
-		Exec:      command.RenderText,
+	file, err = os.Open(path.Join(configDir, dailyFilepath))
+	if err == nil {
+		defer file.Close()
+		botConfig.Dailies, err = command.GetDailyConfigs(bufio.NewReader(file))
+		if err != nil {
+			return botConfig, fmt.Errorf("%s: %w", dailyFilepath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return botConfig, fmt.Errorf("%s: %w", dailyFilepath, err)
 	}
-	commands = append(commands, renderCmd)
 
-	return commands, nil
+	return botConfig, nil
 }
@@ -0,0 +1,128 @@
+//go:build ignore
+
+// gen_descriptions reads the doc comments of configuration types, and writes them
+// to schema_descriptions.go so they can be used as descriptions in JSON Schemas.
+//
+// Run with "go generate" in the config folder.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// packages are folders (relative to the config folder) that have configuration types.
+var packages = []string{".", "../command", "../logging", "../quiz", "../service/discordservice"}
+
+const output = "schema_descriptions.go"
+
+func main() {
+	descriptions := map[string]string{}
+	for _, dir := range packages {
+		if err := readPackage(dir, descriptions); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	keys := make([]string, 0, len(descriptions))
+	for key := range descriptions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buffer bytes.Buffer
+	buffer.WriteString("// Code generated by gen_descriptions.go; DO NOT EDIT.\n\n")
+	buffer.WriteString("package config\n\n")
+	buffer.WriteString("// descriptions are the doc comments of configuration types and their fields,\n")
+	buffer.WriteString("// keyed by \"package.Type\" and \"package.Type.Field\".\n")
+	buffer.WriteString("var descriptions = map[string]string{\n")
+	for _, key := range keys {
+		fmt.Fprintf(&buffer, "\t%q: %q,\n", key, descriptions[key])
+	}
+	buffer.WriteString("}\n")
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(output, source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readPackage adds the documentation of every exported struct in dir to descriptions.
+func readPackage(dir string, descriptions map[string]string) error {
+	fset := token.NewFileSet()
+	packages, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	for name, pkg := range packages {
+		if name == "main" {
+			continue
+		}
+
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				genDecl, ok := decl.(*ast.GenDecl)
+				if !ok || genDecl.Tok != token.TYPE {
+					continue
+				}
+
+				for _, spec := range genDecl.Specs {
+					typeSpec := spec.(*ast.TypeSpec)
+					structType, ok := typeSpec.Type.(*ast.StructType)
+					if !ok || !typeSpec.Name.IsExported() {
+						continue
+					}
+
+					typeName := name + "." + typeSpec.Name.Name
+					doc := typeSpec.Doc
+					if doc == nil && len(genDecl.Specs) == 1 {
+						doc = genDecl.Doc
+					}
+					addDescription(descriptions, typeName, doc)
+
+					for _, field := range structType.Fields.List {
+						for _, fieldName := range field.Names {
+							if !fieldName.IsExported() {
+								continue
+							}
+
+							if field.Doc != nil {
+								addDescription(descriptions, typeName+"."+fieldName.Name, field.Doc)
+							} else {
+								addDescription(descriptions, typeName+"."+fieldName.Name, field.Comment)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// addDescription adds the text of a comment to descriptions, if there is one.
+func addDescription(descriptions map[string]string, key string, comment *ast.CommentGroup) {
+	if comment == nil {
+		return
+	}
+
+	text := strings.Join(strings.Fields(comment.Text()), " ")
+	if text != "" {
+		descriptions[key] = text
+	}
+}
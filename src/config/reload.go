@@ -0,0 +1,153 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// ReloadTrigger is the trigger of the command that reloads configuration files.
+const ReloadTrigger = "reload"
+
+// A Reloader re-reads a directory of configuration files while the bot runs,
+// so that commands can be changed without restarting.
+type Reloader struct {
+	ConfigDir string                           // Directory of configuration files, as used by ConfiguredBot.
+	Storage   *storage.Storage                 // Storage passed to ConfiguredBot.
+	OnReload  func(commands []command.Command) // Receives the new commands after a successful reload.
+	Extra     []command.Command                // Commands that aren't configured by files, such as feed commands, kept on every reload.
+	mutex     sync.Mutex                       // Lock when reading the configuration directory.
+	reloads   sync.Mutex                       // Lock when reloading, so OnReload receives commands in the order they were read.
+	modTimes  map[string]time.Time             // Modification times of files when they were last read.
+}
+
+// Commands reads the configuration directory, returning the configured commands
+// along with a command that reloads them.
+func (r *Reloader) Commands() ([]command.Command, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.load()
+}
+
+// load reads the configuration directory, remembering when each file was modified.
+// They're remembered even if the configuration is invalid, so it isn't read again until it changes.
+func (r *Reloader) load() ([]command.Command, error) {
+	r.modTimes = r.readModTimes()
+	commands, err := ConfiguredBot(r.ConfigDir, r.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	commands = append(commands, r.Extra...)
+	return append(commands, r.Command()), nil
+}
+
+// Reload reads the configuration directory, passing the new commands to OnReload if it's set.
+// If the configuration is invalid, an error is returned and OnReload isn't called,
+// so the bot keeps its current commands. OnReload is called without holding the lock
+// used by Commands and Changed, as it can take a while, such as to update slash commands.
+func (r *Reloader) Reload() error {
+	r.reloads.Lock()
+	defer r.reloads.Unlock()
+
+	r.mutex.Lock()
+	commands, err := r.load()
+	onReload := r.OnReload
+	r.mutex.Unlock()
+	if err != nil {
+		slog.Error("unable to reload configuration, keeping current commands", "dir", r.ConfigDir, "error", err)
+		return err
+	}
+
+	if onReload == nil {
+		slog.Warn("configuration reloaded before anything can use it", "dir", r.ConfigDir)
+		return nil
+	}
+
+	onReload(commands)
+	slog.Info("configuration reloaded", "dir", r.ConfigDir, "commands", len(commands))
+	return nil
+}
+
+// Changed returns true if a configuration file was modified, created or removed
+// since the configuration was last read.
+func (r *Reloader) Changed() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	modTimes := r.readModTimes()
+	if len(modTimes) != len(r.modTimes) {
+		return true
+	}
+
+	for filepath, modTime := range modTimes {
+		if previous, ok := r.modTimes[filepath]; !ok || !previous.Equal(modTime) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch checks for changes to configuration files every interval, reloading when
+// something changed. It returns once stop is closed.
+func (r *Reloader) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if r.Changed() {
+				// An invalid configuration is logged once by Reload, and the current commands are kept until it changes.
+				_ = r.Reload()
+			}
+		}
+	}
+}
+
+// Command returns a command that lets an admin reload configuration files.
+func (r *Reloader) Command() command.Command {
+	return command.Command{
+		Trigger: ReloadTrigger,
+		Help:    "Reload the bot's configuration files. Only usable by admins.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			// Everyone is an admin of their own direct messages, so the configuration can't be reloaded there.
+			if sender.GuildID == "" {
+				return sink(sender, service.Message{Title: "Error", Description: "The configuration can only be reloaded in a server."})
+			}
+
+			if !sender.Admin {
+				return sink(sender, service.Message{Description: "Only admins can reload the configuration."})
+			}
+
+			if err := r.Reload(); err != nil {
+				return sink(sender, service.Message{
+					Title:       "The configuration could not be reloaded",
+					Description: err.Error(),
+				})
+			}
+
+			return sink(sender, service.Message{Description: "The configuration has been reloaded."})
+		},
+	}
+}
+
+// readModTimes returns the modification time of each configuration file that exists.
+func (r *Reloader) readModTimes() map[string]time.Time {
+	modTimes := make(map[string]time.Time)
+	for _, filename := range configFilepaths {
+		filepath := path.Join(r.ConfigDir, filename)
+		if info, err := os.Stat(filepath); err == nil {
+			modTimes[filepath] = info.ModTime()
+		}
+	}
+	return modTimes
+}
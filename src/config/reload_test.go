@@ -0,0 +1,215 @@
+package config
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// writeConfigDir makes a directory with a valid configuration file for each command type.
+// The goquery file has a command with trigger.
+func writeConfigDir(t *testing.T, trigger string) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		settingsFilepath:    `{"Token": "token"}`,
+		adminConfigFilepath: `{"Enabled": false}`,
+		jsonFilepath:        `[]`,
+		regexpFilepath:      `[]`,
+		oxfordFilepath:      `[]`,
+	}
+	for filename, content := range files {
+		if err := os.WriteFile(path.Join(dir, filename), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeGoquery(t, dir, trigger)
+	return dir
+}
+
+// writeGoquery writes a goquery config file with a single command.
+func writeGoquery(t *testing.T, dir string, trigger string) {
+	content := `[{"Trigger": "` + trigger + `", "URL": "https://", "Help": "Help for ` + trigger + `."}]`
+	if err := os.WriteFile(path.Join(dir, goqueryFilepath), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hasTrigger(commands []command.Command, trigger string) bool {
+	for _, cmd := range commands {
+		if cmd.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReloaderCommands(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	reloader := Reloader{ConfigDir: writeConfigDir(t, "first"), Storage: &_storage, Extra: []command.Command{{Trigger: "extra"}}}
+
+	commands, err := reloader.Commands()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasTrigger(commands, "first") || !hasTrigger(commands, ReloadTrigger) || !hasTrigger(commands, "extra") {
+		t.Errorf("Expected the configured command, the extra command and a reload command")
+	}
+}
+
+func TestReloaderReload(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	dir := writeConfigDir(t, "first")
+
+	var reloaded []command.Command
+	reloader := Reloader{
+		ConfigDir: dir,
+		Storage:   &_storage,
+		OnReload:  func(commands []command.Command) { reloaded = commands },
+	}
+
+	if _, err := reloader.Commands(); err != nil {
+		t.Fatal(err)
+	}
+
+	if reloader.Changed() {
+		t.Errorf("Nothing has changed yet")
+	}
+
+	writeGoquery(t, dir, "second")
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path.Join(dir, goqueryFilepath), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reloader.Changed() {
+		t.Errorf("A change wasn't noticed")
+	}
+
+	if err := reloader.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !hasTrigger(reloaded, "second") || hasTrigger(reloaded, "first") {
+		t.Errorf("New commands weren't passed to OnReload")
+	}
+
+	if reloader.Changed() {
+		t.Errorf("A reload should reset changes")
+	}
+}
+
+func TestReloaderKeepsCommandsWhenInvalid(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	dir := writeConfigDir(t, "first")
+
+	called := false
+	reloader := Reloader{
+		ConfigDir: dir,
+		Storage:   &_storage,
+		OnReload:  func([]command.Command) { called = true },
+	}
+
+	if err := os.WriteFile(path.Join(dir, goqueryFilepath), []byte("[{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := reloader.Reload(); err == nil {
+		t.Errorf("An invalid configuration was loaded")
+	}
+
+	if called {
+		t.Errorf("OnReload was called for an invalid configuration")
+	}
+
+	if reloader.Changed() {
+		t.Errorf("An invalid configuration should only be read again once it changes")
+	}
+}
+
+func TestReloaderWithoutOnReload(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	reloader := Reloader{ConfigDir: writeConfigDir(t, "first"), Storage: &_storage}
+
+	if err := reloader.Reload(); err != nil {
+		t.Errorf("Unable to reload without OnReload: %s", err)
+	}
+
+	if reloader.Changed() {
+		t.Errorf("A reload without OnReload should still reset changes")
+	}
+}
+
+func TestReloadCommandRequiresAdmin(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+
+	called := false
+	reloader := Reloader{
+		ConfigDir: writeConfigDir(t, "first"),
+		Storage:   &_storage,
+		OnReload:  func([]command.Command) { called = true },
+	}
+
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	reload := reloader.Command()
+	if err := reload.Exec(conversation, user, []interface{}{}, &_storage, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if called {
+		t.Errorf("A non-admin reloaded the configuration")
+	}
+
+	conversation.Admin = true
+	if err := reload.Exec(conversation, user, []interface{}{}, &_storage, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if !called {
+		t.Errorf("An admin was unable to reload the configuration")
+	}
+}
+
+func TestReloadCommandRefusedInDirectMessages(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+
+	called := false
+	reloader := Reloader{
+		ConfigDir: writeConfigDir(t, "first"),
+		Storage:   &_storage,
+		OnReload:  func([]command.Command) { called = true },
+	}
+
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoSender.ID(), ConversationID: "0", Admin: true}
+	user := service.User{Name: "Test_User", ServiceID: demoSender.ID()}
+
+	reload := reloader.Command()
+	if err := reload.Exec(conversation, user, []interface{}{}, &_storage, demoSender.SendMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if called {
+		t.Errorf("The configuration was reloaded from direct messages")
+	}
+
+	if reply, _ := demoSender.PopMessage(); !strings.Contains(reply.Description, "only be reloaded in a server") {
+		t.Errorf("Expected the reload to be refused, got %+v", reply)
+	}
+}
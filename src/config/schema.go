@@ -0,0 +1,166 @@
+package config
+
+//go:generate go run gen_descriptions.go
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+)
+
+// schemaDraft is the version of JSON Schema that schemas are written in.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// A Schema is a JSON Schema, which editors can use to complete and check configuration files.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"` // false for structs, or a *Schema for maps.
+}
+
+// schemaRoots are the configuration files that schemas describe, and what each file holds.
+var schemaRoots = map[string]reflect.Type{
+	settingsFilepath:    reflect.TypeOf(settingsFile{}),
+	adminConfigFilepath: reflect.TypeOf(command.AdminConfig{}),
+	jsonFilepath:        reflect.TypeOf([]command.JSONGetterConfig{}),
+	regexpFilepath:      reflect.TypeOf([]command.RegexpScraperConfig{}),
+	goqueryFilepath:     reflect.TypeOf([]command.GoQueryScraperConfig{}),
+	oxfordFilepath:      reflect.TypeOf([]command.OxfordDictionaryConfig{}),
+	xmlFilepath:         reflect.TypeOf([]command.XMLScraperConfig{}),
+	dailyFilepath:       reflect.TypeOf([]command.DailyConfig{}),
+}
+
+func init() {
+	for _, filename := range unifiedFilepaths {
+		schemaRoots[filename] = reflect.TypeOf(BotConfig{})
+	}
+}
+
+// schemaEnums are the values a field can have, keyed by "package.Type.Field".
+var schemaEnums = map[string][]string{
+	"command.Parameter.Type":          parameterTypes,
+	"command.SelectorCapture.Extract": extractModes,
+	"command.TokenMaker.Type":         tokenTypes,
+	"config.TelemetryConfig.Exporter": exporters,
+	"logging.Config.Format":           logFormats,
+	"logging.Config.Level":            logLevels,
+}
+
+// schemaPatterns are regular expressions a field must match, keyed by "package.Type.Field".
+// They're used instead of schemaEnums for fields whose values have arguments.
+var schemaPatterns = map[string]string{
+	"command.SelectorCapture.HandleMultiple": command.HandleMultiplePattern,
+	"command.XPathCapture.HandleMultiple":    command.HandleMultiplePattern,
+}
+
+// schemaRequired are the fields a type must have, keyed by "package.Type".
+var schemaRequired = map[string][]string{
+	"command.DailyConfig":            {"Trigger", "Target", "Help"},
+	"command.GoQueryScraperConfig":   {"Trigger", "URL", "Help"},
+	"command.JSONGetterConfig":       {"Trigger", "URL", "Help"},
+	"command.OxfordDictionaryConfig": {"Trigger", "AppID", "AppKey", "HelpText"},
+	"command.Parameter":              {"Type", "Name", "Description"},
+	"command.RegexpScraperConfig":    {"Trigger", "URL", "Help", "ReplyCapture"},
+	"command.XMLScraperConfig":       {"Trigger", "URL", "Help"},
+	"config.BotConfig":               {"Discord"},
+	"discordservice.DiscordConfig":   {"Token"},
+	"quiz.Deck":                      {"Name", "Questions"},
+	"quiz.Question":                  {"Prompt", "Answers"},
+}
+
+// SchemaNames returns the names of configuration files that a schema can be generated for.
+func SchemaNames() []string {
+	names := make([]string, 0, len(schemaRoots))
+	for name := range schemaRoots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GenerateSchema returns a JSON Schema for the configuration file named filename,
+// such as "goquery_scraper_config.json" or "boby.yaml".
+func GenerateSchema(filename string) (*Schema, error) {
+	typ, ok := schemaRoots[filename]
+	if !ok {
+		return nil, fmt.Errorf("no schema for %q, expected one of %v", filename, SchemaNames())
+	}
+
+	schema := schemaOf(typ)
+	schema.Schema = schemaDraft
+	schema.Title = filename
+	return schema, nil
+}
+
+// schemaOf returns a schema for values of typ.
+func schemaOf(typ reflect.Type) *Schema {
+	switch typ.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOf(typ.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(typ.Elem())}
+	case reflect.Ptr:
+		return schemaOf(typ.Elem())
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Description:          descriptions[typeKey(typ)],
+			Properties:           make(map[string]*Schema),
+			AdditionalProperties: false,
+		}
+		addProperties(schema, typ)
+		sort.Strings(schema.Required)
+		return schema
+	}
+	return &Schema{}
+}
+
+// addProperties adds the fields of typ to schema, including the fields of embedded structs.
+func addProperties(schema *Schema, typ reflect.Type) {
+	key := typeKey(typ)
+	schema.Required = append(schema.Required, schemaRequired[key]...)
+
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addProperties(schema, field.Type)
+			continue
+		}
+
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		property := schemaOf(field.Type)
+		if description, ok := descriptions[key+"."+field.Name]; ok {
+			property.Description = description
+		}
+		property.Enum = schemaEnums[key+"."+field.Name]
+		property.Pattern = schemaPatterns[key+"."+field.Name]
+		schema.Properties[jsonName(field)] = property
+	}
+}
+
+// typeKey returns "package.Type" for a named type, as used by descriptions.
+func typeKey(typ reflect.Type) string {
+	return path.Base(typ.PkgPath()) + "." + typ.Name()
+}
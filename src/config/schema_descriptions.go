@@ -0,0 +1,238 @@
+// Code generated by gen_descriptions.go; DO NOT EDIT.
+
+package config
+
+// descriptions are the doc comments of configuration types and their fields,
+// keyed by "package.Type" and "package.Type.Field".
+var descriptions = map[string]string{
+	"command.AdminConfig":                                     "Config for admin commands.",
+	"command.AdminConfig.Enabled":                             "Whether admin commands are enabled.",
+	"command.Command":                                         "A Command is how a User interacts with a bot.",
+	"command.Command.Continue":                                "Receives the user's next message when Exec or Continue starts a session with this command's trigger.",
+	"command.Command.Exec":                                    "The command's processing. The last parameter sends a reply, and is expected to be used at least once (if the command is unsuccessful, report an error).",
+	"command.Command.Help":                                    "What this command does.",
+	"command.Command.HelpInput":                               "Arguments following the trigger.",
+	"command.Command.Parameters":                              "What text to capture following a trigger.",
+	"command.Command.Trigger":                                 "Messages starting with Trigger are processed by this Command.",
+	"command.DailyConfig":                                     "DailyConfig can be turned into a command that chooses a word, such as a word of the day, and gives it to another command, such as a dictionary lookup. Schedule the command to post the word every day.",
+	"command.DailyConfig.Help":                                "Help message to display.",
+	"command.DailyConfig.History":                             "How many of the latest words aren't chosen again. When 0, words aren't repeated until every word has been chosen.",
+	"command.DailyConfig.Target":                              "Trigger of the configured command that the chosen word is given to.",
+	"command.DailyConfig.Title":                               "Shown above the message of Target, such as \"Word of the day\".",
+	"command.DailyConfig.Trigger":                             "Word which triggers this command to activate.",
+	"command.DailyConfig.Words":                               "Words to choose from.",
+	"command.DailyConfig.WordsFile":                           "A file with a word on each line, relative to the configuration directory. Used with Words.",
+	"command.DailyConfig.WordsURL":                            "A url of a text file with a word on each line. Used with Words and WordsFile.",
+	"command.FieldCapture":                                    "A FieldCapture represents a template to be filled out by selectors.",
+	"command.FieldCapture.ErrorMsg":                           "If the template has any %s remaining, replace the entire msg with this msg.",
+	"command.FieldCapture.Selectors":                          "What captures to use to fill out the template",
+	"command.FieldCapture.Template":                           "Message template to be filled out. Use %s to denote text to be replaced.",
+	"command.GoQueryFieldCapture":                             "GoQueryFieldCapture is used to have a selector capture for a pair of selectors.",
+	"command.GoQueryFieldCapture.Description":                 "The field's body text.",
+	"command.GoQueryFieldCapture.Title":                       "The field's title.",
+	"command.GoQueryScraperConfig":                            "GoQueryScraperConfig can be turned into a scraper that uses GoQuery.",
+	"command.GoQueryScraperConfig.Author":                     "Who or what the output message is from, shown above the title.",
+	"command.GoQueryScraperConfig.Color":                      "Color of the output message as a hex code, such as \"#FF0000\".",
+	"command.GoQueryScraperConfig.ErrorURL":                   "A url to show only when there is an error.",
+	"command.GoQueryScraperConfig.Fields":                     "Fields to add to the output message.",
+	"command.GoQueryScraperConfig.Footer":                     "Small text shown at the bottom of the output message.",
+	"command.GoQueryScraperConfig.Help":                       "Help message to display.",
+	"command.GoQueryScraperConfig.HelpInput":                  "Help message to display for input following command.",
+	"command.GoQueryScraperConfig.HideURL":                    "When true, a result returns no URL. Use with caution, attribution is often required.",
+	"command.GoQueryScraperConfig.ImageMaxBytes":              "The largest image that is downloaded, in bytes. Defaults to 8 MiB.",
+	"command.GoQueryScraperConfig.ImageMaxSide":               "Images wider or taller than this many pixels are scaled down to fit. Defaults to 1024.",
+	"command.GoQueryScraperConfig.ImageSelector":              "URL of an image attached to the output message, taken from Attribute, which defaults to \"src\". Relative URLs are resolved against the page.",
+	"command.GoQueryScraperConfig.Paginated":                  "When true, each match of TitleSelector and ReplySelector is a page, instead of choosing one using HandleMultiple.",
+	"command.GoQueryScraperConfig.Parameters":                 "How to capture words.",
+	"command.GoQueryScraperConfig.ReplySelector":              "The output message's body text.",
+	"command.GoQueryScraperConfig.Thumbnail":                  "URL of a small image shown beside the output message. Relative URLs are resolved against the page.",
+	"command.GoQueryScraperConfig.Timestamp":                  "When the scraped content is from, such as \"2021-05-04\" or \"2021-05-04T03:02:01Z\".",
+	"command.GoQueryScraperConfig.Title":                      "When sending a post, what should the title be.",
+	"command.GoQueryScraperConfig.TitleSelector":              "The output message's title.",
+	"command.GoQueryScraperConfig.Trigger":                    "Word which triggers this command to activate.",
+	"command.GoQueryScraperConfig.URL":                        "A url to scrape from, can contain one \"%s\" which is replaced with the first capture group.",
+	"command.GoQueryScraperConfig.URLSuffix":                  "When adding a URL to a message, this string is appended. This is useful for including referral links.",
+	"command.JSONCapture":                                     "JSONCapture is a pair of FieldCapture to represent a title, body pair in a message.",
+	"command.JSONCapture.Body":                                "The body of a message or field.",
+	"command.JSONCapture.Title":                               "The title of a message or field.",
+	"command.JSONCapture.URLSelector":                         "Selector of the URL a title links to.",
+	"command.JSONGetterConfig":                                "JSONGetterConfig can be used to extract from JSON into a message.",
+	"command.JSONGetterConfig.Author":                         "Who or what messages are from, shown above the title.",
+	"command.JSONGetterConfig.Color":                          "Color of messages as a hex code, such as \"#FF0000\".",
+	"command.JSONGetterConfig.Delay":                          "If grouped is false, what is the delay between each message sent.",
+	"command.JSONGetterConfig.Fields":                         "A message is composed of several fields. Captures is used to make fields of a message.",
+	"command.JSONGetterConfig.Footer":                         "Small text shown at the bottom of messages.",
+	"command.JSONGetterConfig.Grouped":                        "If true, only a single message is sent, if false each entry in .",
+	"command.JSONGetterConfig.Help":                           "Message shown when help command is used.",
+	"command.JSONGetterConfig.HelpInput":                      "Message shown used to explain what expected user input is following trigger.",
+	"command.JSONGetterConfig.Message":                        "The primary title and body of a message.",
+	"command.JSONGetterConfig.Paginated":                      "If grouped is false and this is true, messages are sent as pages instead of one after another, ignoring Delay.",
+	"command.JSONGetterConfig.Parameters":                     "Capture is a regexp, that is used to capture everything following 'trigger.'",
+	"command.JSONGetterConfig.RateLimit":                      "RateLimit places a limit on how frequently a user can send messages.",
+	"command.JSONGetterConfig.Thumbnail":                      "URL of a small image shown beside messages.",
+	"command.JSONGetterConfig.Timestamp":                      "When the content of messages is from, such as \"2021-05-04\" or \"2021-05-04T03:02:01Z\".",
+	"command.JSONGetterConfig.Token":                          "Often an API requires a calculated API, Token is used to help create a token and append to a URL prior to requests.",
+	"command.JSONGetterConfig.Trigger":                        "What a message must begin with to trigger this command.",
+	"command.JSONGetterConfig.URL":                            "URL to retrieve a JSON from.",
+	"command.JSONGetterConfig.URLSelector":                    "Selector to make into the URL for the title.",
+	"command.OxfordDictionaryConfig":                          "OxfordDictionaryConfig can be turned into a command that translates using the Oxford Dictionaries API.",
+	"command.OxfordDictionaryConfig.AppID":                    "Application ID for the Oxford Dictionaries API.",
+	"command.OxfordDictionaryConfig.AppKey":                   "Application key for the Oxford Dictionaries API.",
+	"command.OxfordDictionaryConfig.Body":                     "Reply when limit is reached.",
+	"command.OxfordDictionaryConfig.HelpInput":                "Help message to display for input following command.",
+	"command.OxfordDictionaryConfig.HelpText":                 "Help message to display.",
+	"command.OxfordDictionaryConfig.ID":                       "An ID used for storage purposes.",
+	"command.OxfordDictionaryConfig.SecondsPerInterval":       "How long is an interval, in seconds.",
+	"command.OxfordDictionaryConfig.SourceLanguage":           "Language of words that are looked up, such as \"en\".",
+	"command.OxfordDictionaryConfig.TargetLanguage":           "Language to translate to, such as \"es\".",
+	"command.OxfordDictionaryConfig.TimesPerInterval":         "How many times the command can be used per interval.",
+	"command.OxfordDictionaryConfig.Trigger":                  "Word which triggers this command to activate.",
+	"command.OxfordTranslateResponseStruct":                   "OxfordTranslateResponseStruct is the response from the Oxford Dictionary api when requesting a translation.",
+	"command.Parameter":                                       "A Parameter captures input to a command.",
+	"command.Parameter.Description":                           "Description of the slash command option.",
+	"command.Parameter.Name":                                  "Name of the slash command option.",
+	"command.Parameter.Type":                                  "\"string\", \"int\", \"bool\", \"user\" or \"role\".",
+	"command.RateLimitConfig":                                 "RateLimitConfig is a wrapper around a Command, that ensures a command is not used excessively.",
+	"command.RateLimitConfig.Body":                            "Reply when limit is reached.",
+	"command.RateLimitConfig.Global":                          "When true, the limit is shared by every user, rather than applying to each user.",
+	"command.RateLimitConfig.ID":                              "An ID used for storage purposes.",
+	"command.RateLimitConfig.SecondsPerInterval":              "How long is an interval, in seconds.",
+	"command.RateLimitConfig.TimesPerInterval":                "How many times can it be used per interval?",
+	"command.RegexpScraperConfig":                             "RegexpScraperConfig is a struct that can be made into a command. That Command will process a HTML based on a regexp, to send responses.",
+	"command.RegexpScraperConfig.Help":                        "Help message to display",
+	"command.RegexpScraperConfig.HelpInput":                   "Help message to display for input following command",
+	"command.RegexpScraperConfig.Parameters":                  "How to capture words.",
+	"command.RegexpScraperConfig.ReplyCapture":                "Regular expression used to parse a webpage.",
+	"command.RegexpScraperConfig.TitleCapture":                "Regex captures for title replacement.",
+	"command.RegexpScraperConfig.TitleTemplate":               "Title template that will be replaced by regex captures (using %s).",
+	"command.RegexpScraperConfig.Trigger":                     "Word which triggers this command to activate.",
+	"command.RegexpScraperConfig.URL":                         "A url to scrape from, can contain one \"%s\" which is replaced with the first capture group.",
+	"command.SelectorCapture":                                 "SelectorCapture will fill out a template string using webpage content selected with goquery.",
+	"command.SelectorCapture.Attribute":                       "When set, the value of this attribute is captured instead of text, such as \"src\" or \"href\". Relative links in \"href\" and \"src\" are made absolute.",
+	"command.SelectorCapture.Extract":                         "What is captured when Attribute isn't set. \"Text\", \"HTML\" for the inner HTML, or \"Markdown\" to keep formatting such as bold text and links. Defaults to \"Text\".",
+	"command.SelectorCapture.FullReplacement":                 "String replacement that takes place on the completed selector.",
+	"command.SelectorCapture.HandleMultiple":                  "How to handle multiple captures. \"First\", \"Last\", \"Random\", \"All\", \"Index:n\" or \"Range:a-b\", where indexes count from 0 and a negative n counts back from the last.",
+	"command.SelectorCapture.Limit":                           "When more than 0, at most this many filled out templates are joined.",
+	"command.SelectorCapture.Replacements":                    "String replacements for each entry in selectors.",
+	"command.SelectorCapture.Selectors":                       "What goquery captures are used to fill out the template.",
+	"command.SelectorCapture.Separator":                       "Joins the filled out templates when HandleMultiple is \"All\" or \"Range\". Defaults to a new line.",
+	"command.SelectorCapture.Template":                        "Message template to be filled out. Every %s in a template is replaced with results of selectors.",
+	"command.TokenMaker":                                      "A TokenMaker is useful for creating a token that may be part of an API request.",
+	"command.TokenMaker.Postfix":                              "When calculating a token, what should be appended",
+	"command.TokenMaker.Prefix":                               "When calculating a token, what should be prepended",
+	"command.TokenMaker.Size":                                 "Take the first 'Size' characters from the result.",
+	"command.TokenMaker.Suffix":                               "String to append after the token",
+	"command.TokenMaker.Type":                                 "Can be MD5",
+	"command.XMLFieldCapture":                                 "XMLFieldCapture is used to have an XPathCapture for a field's title and body.",
+	"command.XMLFieldCapture.Body":                            "The field's body text.",
+	"command.XMLFieldCapture.Title":                           "The field's title.",
+	"command.XMLScraperConfig":                                "XMLScraperConfig can be turned into a command that reads XML using XPath.",
+	"command.XMLScraperConfig.Body":                           "The output message's body text.",
+	"command.XMLScraperConfig.Color":                          "Color of the output message as a hex code, such as \"#FF0000\".",
+	"command.XMLScraperConfig.ErrorURL":                       "A url to show only when there is an error.",
+	"command.XMLScraperConfig.Fields":                         "Fields to add to the output message.",
+	"command.XMLScraperConfig.Help":                           "Help message to display.",
+	"command.XMLScraperConfig.HelpInput":                      "Help message to display for input following command.",
+	"command.XMLScraperConfig.HideURL":                        "When true, a result returns no URL. Use with caution, attribution is often required.",
+	"command.XMLScraperConfig.Namespaces":                     "Prefixes used in XPath expressions, mapped to namespace URIs, such as {\"atom\": \"http://www.w3.org/2005/Atom\"}.",
+	"command.XMLScraperConfig.Parameters":                     "How to capture words.",
+	"command.XMLScraperConfig.RateLimit":                      "RateLimit places a limit on how frequently a user can send messages.",
+	"command.XMLScraperConfig.Title":                          "The output message's title.",
+	"command.XMLScraperConfig.Trigger":                        "Word which triggers this command to activate.",
+	"command.XMLScraperConfig.URL":                            "A url to retrieve XML from, each \"%s\" is replaced with a captured word.",
+	"command.XMLScraperConfig.URLSuffix":                      "When adding a URL to a message, this string is appended. This is useful for including referral links.",
+	"command.XPathCapture":                                    "XPathCapture will fill out a template string using XML selected with XPath.",
+	"command.XPathCapture.HandleMultiple":                     "How to handle multiple matches, as in SelectorCapture.HandleMultiple.",
+	"command.XPathCapture.Limit":                              "When more than 0, at most this many filled out templates are joined.",
+	"command.XPathCapture.Selectors":                          "XPath expressions, such as \"//entry/title\" or \"//link/@href\". An expression can also give text or a number, such as \"count(//entry)\".",
+	"command.XPathCapture.Separator":                          "Joins the filled out templates when HandleMultiple is \"All\" or \"Range\". Defaults to a new line.",
+	"command.XPathCapture.Template":                           "Message template to be filled out. Every %s in a template is replaced with results of selectors.",
+	"config.AccessConfig":                                     "AccessConfig configures which commands can be used in each server, and in which channels.",
+	"config.AccessConfig.Enabled":                             "When true, admins can disable commands in their server, or only allow them in some channels.",
+	"config.BotConfig":                                        "BotConfig is everything needed to run the bot. It is read either from a single unified file, or from a file per type of command. A missing section means there are no commands of that type.",
+	"config.BotConfig.Admin":                                  "Whether admin commands are available.",
+	"config.BotConfig.Dailies":                                "Commands that choose a word, such as a word of the day, and give it to another command.",
+	"config.BotConfig.Discord":                                "Token and other Discord settings.",
+	"config.BotConfig.GoQueryScrapers":                        "Commands that scrape webpages using CSS selectors.",
+	"config.BotConfig.JSONGetters":                            "Commands that read from JSON APIs.",
+	"config.BotConfig.Oxford":                                 "Commands that use the Oxford Dictionary API.",
+	"config.BotConfig.RegexpScrapers":                         "Commands that scrape webpages using regular expressions.",
+	"config.BotConfig.XMLScrapers":                            "Commands that read XML using XPath.",
+	"config.EnvError":                                         "An EnvError is returned when a configuration refers to an environment variable that isn't set.",
+	"config.EnvError.Name":                                    "Name of the variable.",
+	"config.EnvError.Path":                                    "JSON path to the string that refers to the variable, such as \"$.Discord.Token\".",
+	"config.FeedsConfig":                                      "FeedsConfig configures how often subscribed feeds are checked for new entries.",
+	"config.FeedsConfig.MaxPosts":                             "Most new entries of a feed posted per poll, newest first. Defaults to 5.",
+	"config.FeedsConfig.PollMinutes":                          "When above 0, feeds are polled this often, and commands for subscribing to feeds are added.",
+	"config.FlashcardsConfig":                                 "FlashcardsConfig configures the decks of vocabulary cards that users can save and review.",
+	"config.FlashcardsConfig.Enabled":                         "When true, users can save cards to a deck and review them.",
+	"config.FlashcardsConfig.Lookup":                          "Trigger of a command, such as a dictionary, used to find the meaning of a word saved without one.",
+	"config.Getters":                                          "Getters are used by commands to retrieve webpages and JSON.",
+	"config.Getters.HTML":                                     "Used by scrapers, including XML scrapers.",
+	"config.Getters.JSON":                                     "Used by JSON getters.",
+	"config.QuizConfig":                                       "QuizConfig configures quizzes, where the first correct answer to each question scores a point.",
+	"config.QuizConfig.Decks":                                 "When not empty, commands for playing quizzes with these decks are added.",
+	"config.QuizConfig.Rounds":                                "How many questions a quiz asks. Defaults to 10.",
+	"config.QuizConfig.Seconds":                               "How long each question can be answered for. Defaults to 30.",
+	"config.Reloader":                                         "A Reloader re-reads a directory of configuration files while the bot runs, so that commands can be changed without restarting.",
+	"config.Reloader.ConfigDir":                               "Directory of configuration files, as used by ConfiguredBot.",
+	"config.Reloader.Extra":                                   "Commands that aren't configured by files, such as feed commands, kept on every reload.",
+	"config.Reloader.OnReload":                                "Receives the new commands after a successful reload.",
+	"config.Reloader.Storage":                                 "Storage passed to ConfiguredBot.",
+	"config.SchedulerConfig":                                  "SchedulerConfig configures scheduled messages, commands and reminders.",
+	"config.SchedulerConfig.Enabled":                          "When true, admins can schedule messages and commands in a channel.",
+	"config.SchedulerConfig.Reminders":                        "When true, users can set reminders for themselves.",
+	"config.SchedulerConfig.Timezone":                         "Timezone of schedules and reminders that don't give one, such as \"Asia/Manila\". Defaults to UTC.",
+	"config.Schema":                                           "A Schema is a JSON Schema, which editors can use to complete and check configuration files.",
+	"config.Schema.AdditionalProperties":                      "false for structs, or a *Schema for maps.",
+	"config.Settings":                                         "Settings configure how the bot runs, rather than what commands it has. They are read from the same file as the service configuration, so a config.json can hold a token alongside \"Telemetry\" and \"Logging\" sections.",
+	"config.Settings.Access":                                  "Whether admins can choose which commands can be used in their server.",
+	"config.Settings.Feeds":                                   "How RSS and Atom feeds are polled.",
+	"config.Settings.Flashcards":                              "Whether users can save and review vocabulary cards.",
+	"config.Settings.Logging":                                 "How logs are formatted, filtered and stored.",
+	"config.Settings.Quiz":                                    "Decks of questions that quizzes are played with.",
+	"config.Settings.Scheduler":                               "Whether messages, commands and reminders can be scheduled.",
+	"config.Settings.Tags":                                    "Whether admins can add commands to their server.",
+	"config.Settings.Telemetry":                               "How traces are sampled, redacted and exported.",
+	"config.Settings.WatchSeconds":                            "When above 0, configuration files are checked for changes this often, and reloaded when they change.",
+	"config.TagsConfig":                                       "TagsConfig configures tags, which are commands that admins add to their server while the bot runs.",
+	"config.TagsConfig.Enabled":                               "When true, admins can add, edit and delete tags, which reply with a response they write.",
+	"config.TelemetryConfig":                                  "TelemetryConfig configures OpenTelemetry tracing. Empty fields fall back to the standard OTEL_* environment variables.",
+	"config.TelemetryConfig.Endpoint":                         "URL of an OTLP collector. Defaults to OTEL_EXPORTER_OTLP_ENDPOINT.",
+	"config.TelemetryConfig.Exporter":                         "\"none\", \"stdout\", \"file\", \"otlpgrpc\" or \"otlphttp\". Defaults to OTEL_TRACES_EXPORTER, then \"otlpgrpc\".",
+	"config.TelemetryConfig.Filepath":                         "Where spans are written to when Exporter is \"file\".",
+	"config.TelemetryConfig.Insecure":                         "When true, OTLP exporters don't use TLS. Defaults to true, as a collector is usually local, so set it to false to use TLS.",
+	"config.TelemetryConfig.RedactAttributes":                 "Span attributes to redact before export. When missing, DefaultRedactAttributes is used, use [] to redact nothing.",
+	"config.TelemetryConfig.SampleRatio":                      "Fraction of traces to keep, between 0 and 1. When 0, OTEL_TRACES_SAMPLER is used (which defaults to keeping everything).",
+	"config.ValidationError":                                  "A ValidationError describes a problem with part of a configuration file.",
+	"config.ValidationError.File":                             "Name of the file with the problem.",
+	"config.ValidationError.Path":                             "JSON path to the problem within the file, such as \"$[0].TitleSelector\".",
+	"config.ValidationError.Reason":                           "What the problem is.",
+	"config.ValidationError.Warning":                          "When true, the configuration still loads, but may not behave as expected.",
+	"discordservice.DiscordConfig":                            "DiscordConfig has data required for discord to work (e.g. Token).",
+	"discordservice.DiscordConfig.ChannelIDsToReportErrorsTo": "Channels that errors are sent to.",
+	"discordservice.DiscordConfig.Token":                      "Token of the bot's Discord application.",
+	"discordservice.DiscordSender":                            "DiscordSender adheres to the Sender interface for discord.",
+	"discordservice.DiscordSubject":                           "A DiscordSubject receives messages from discord, and passes events to its observers.",
+	"logging.Config":                                          "Config describes how logs are formatted and where they are written.",
+	"logging.Config.Destinations":                             "\"stdout\", \"stderr\" or filepaths. Defaults to stdout and logging.log.",
+	"logging.Config.Format":                                   "\"text\" or \"json\". Defaults to \"text\".",
+	"logging.Config.Level":                                    "\"debug\", \"info\", \"warn\" or \"error\". Defaults to \"info\".",
+	"logging.Config.MaxBackups":                               "How many rotated files are kept, older files are removed. When 0, all are kept.",
+	"logging.Config.MaxSizeMB":                                "Files are rotated once they reach this size. When 0, files are never rotated.",
+	"logging.RotatingFile":                                    "RotatingFile is a file that is renamed once it reaches a size limit, so that logging can continue in a new file. The current file is always at the original filepath. Older files have a numbered suffix, where \".1\" is the most recently rotated.",
+	"quiz.Deck":                                               "A Deck is a set of questions that a quiz is made of.",
+	"quiz.Deck.Name":                                          "Name used to start a quiz of this deck.",
+	"quiz.Deck.Questions":                                     "Questions asked in a random order.",
+	"quiz.Question":                                           "A Question is a prompt, such as a word to translate, and its correct answers.",
+	"quiz.Question.Answers":                                   "Answers that are correct, ignoring case, accents and punctuation.",
+	"quiz.Question.Prompt":                                    "Shown when the question is asked.",
+	"quiz.Quiz":                                               "A Quiz runs a game in each conversation that starts one, posting questions and scoring answers.",
+	"quiz.Quiz.Rounds":                                        "How many questions each game asks. Defaults to DefaultRounds.",
+	"quiz.Quiz.Seconds":                                       "How long each question can be answered for. Defaults to DefaultSeconds.",
+	"quiz.Rank":                                               "A Rank is a user's place on a leaderboard.",
+	"quiz.Rank.User":                                          "Name of the user.",
+	"quiz.Score":                                              "A Score is the points of a user in a guild.",
+	"quiz.Score.Week":                                         "The week of Weekly, as written by week.",
+	"quiz.Score.Weekly":                                       "Points scored in Week.",
+}
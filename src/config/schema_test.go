@@ -0,0 +1,122 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+)
+
+// checkDescriptions reports every property in schema that has no description.
+// If this fails after changing a configuration type, run "go generate" in the config folder.
+func checkDescriptions(t *testing.T, jsonPath string, schema *Schema) {
+	for name, property := range schema.Properties {
+		if property.Description == "" {
+			t.Errorf("%s.%s has no description", jsonPath, name)
+		}
+		checkDescriptions(t, jsonPath+"."+name, property)
+	}
+
+	if schema.Items != nil {
+		checkDescriptions(t, jsonPath+"[]", schema.Items)
+	}
+}
+
+func TestGenerateSchemas(t *testing.T) {
+	for _, name := range SchemaNames() {
+		schema, err := GenerateSchema(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := json.Marshal(schema); err != nil {
+			t.Errorf("%s: %s", name, err)
+		}
+
+		checkDescriptions(t, name+": $", schema)
+	}
+}
+
+func TestGenerateSchemaUnknown(t *testing.T) {
+	if _, err := GenerateSchema("unknown.json"); err == nil {
+		t.Errorf("Expected an error for an unknown file")
+	}
+}
+
+func TestGenerateSchemaGoQuery(t *testing.T) {
+	schema, err := GenerateSchema(goqueryFilepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if schema.Type != "array" || schema.Items.Type != "object" {
+		t.Fatalf("Expected an array of objects, got %+v", schema)
+	}
+
+	if diff := cmp.Diff([]string{"Help", "Trigger", "URL"}, schema.Items.Required); diff != "" {
+		t.Errorf("Unexpected required fields: %s", diff)
+	}
+
+	handleMultiple := schema.Items.Properties["TitleSelector"].Properties["HandleMultiple"]
+	if handleMultiple.Pattern != command.HandleMultiplePattern || handleMultiple.Enum != nil {
+		t.Errorf("Expected HandleMultiple to have a pattern, got %q", handleMultiple.Pattern)
+	}
+
+	parameterType := schema.Items.Properties["Parameters"].Items.Properties["Type"]
+	if diff := cmp.Diff(parameterTypes, parameterType.Enum); diff != "" {
+		t.Errorf("Unexpected Parameter.Type enum: %s", diff)
+	}
+}
+
+func TestGenerateSchemaJSONGetter(t *testing.T) {
+	schema, err := GenerateSchema(jsonFilepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenType := schema.Items.Properties["Token"].Properties["Type"]
+	if diff := cmp.Diff(tokenTypes, tokenType.Enum); diff != "" {
+		t.Errorf("Unexpected TokenMaker.Type enum: %s", diff)
+	}
+}
+
+func TestGenerateSchemaUnified(t *testing.T) {
+	schema, err := GenerateSchema("boby.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Settings are embedded, so their fields are at the top level.
+	for _, name := range []string{"Telemetry", "Logging", "WatchSeconds", "Discord", "GoQueryScrapers"} {
+		if _, ok := schema.Properties[name]; !ok {
+			t.Errorf("Expected a %s property", name)
+		}
+	}
+
+	if diff := cmp.Diff([]string{"Token"}, schema.Properties["Discord"].Required); diff != "" {
+		t.Errorf("Unexpected required fields: %s", diff)
+	}
+
+	if schema.AdditionalProperties != false {
+		t.Errorf("Expected unknown properties to be disallowed")
+	}
+}
+
+func TestGenerateSchemaSettings(t *testing.T) {
+	schema, err := GenerateSchema(settingsFilepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// config.json holds the token alongside settings.
+	if diff := cmp.Diff([]string{"Token"}, schema.Required); diff != "" {
+		t.Errorf("Unexpected required fields: %s", diff)
+	}
+
+	exporter := schema.Properties["Telemetry"].Properties["Exporter"]
+	if diff := cmp.Diff(exporters, exporter.Enum); diff != "" {
+		t.Errorf("Unexpected exporters: %s", diff)
+	}
+}
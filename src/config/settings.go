@@ -0,0 +1,156 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+
+	"github.com/BKrajancic/boby/m/v2/src/logging"
+	"github.com/BKrajancic/boby/m/v2/src/quiz"
+	"github.com/BKrajancic/boby/m/v2/src/service/discordservice"
+)
+
+// settingsFilepath is shared with discordservice, which reads the token from the same file.
+const settingsFilepath = "config.json"
+
+// Settings configure how the bot runs, rather than what commands it has.
+// They are read from the same file as the service configuration, so a
+// config.json can hold a token alongside "Telemetry" and "Logging" sections.
+type Settings struct {
+	Telemetry TelemetryConfig // How traces are sampled, redacted and exported.
+	Logging   logging.Config  // How logs are formatted, filtered and stored.
+
+	WatchSeconds int              // When above 0, configuration files are checked for changes this often, and reloaded when they change.
+	Feeds        FeedsConfig      // How RSS and Atom feeds are polled.
+	Scheduler    SchedulerConfig  // Whether messages, commands and reminders can be scheduled.
+	Flashcards   FlashcardsConfig // Whether users can save and review vocabulary cards.
+	Quiz         QuizConfig       // Decks of questions that quizzes are played with.
+	Tags         TagsConfig       // Whether admins can add commands to their server.
+	Access       AccessConfig     // Whether admins can choose which commands can be used in their server.
+}
+
+// FeedsConfig configures how often subscribed feeds are checked for new entries.
+type FeedsConfig struct {
+	PollMinutes int // When above 0, feeds are polled this often, and commands for subscribing to feeds are added.
+	MaxPosts    int // Most new entries of a feed posted per poll, newest first. Defaults to 5.
+}
+
+// SchedulerConfig configures scheduled messages, commands and reminders.
+type SchedulerConfig struct {
+	Enabled   bool   // When true, admins can schedule messages and commands in a channel.
+	Reminders bool   // When true, users can set reminders for themselves.
+	Timezone  string // Timezone of schedules and reminders that don't give one, such as "Asia/Manila". Defaults to UTC.
+}
+
+// FlashcardsConfig configures the decks of vocabulary cards that users can save and review.
+type FlashcardsConfig struct {
+	Enabled bool   // When true, users can save cards to a deck and review them.
+	Lookup  string // Trigger of a command, such as a dictionary, used to find the meaning of a word saved without one.
+}
+
+// QuizConfig configures quizzes, where the first correct answer to each question scores a point.
+type QuizConfig struct {
+	Decks   []quiz.Deck // When not empty, commands for playing quizzes with these decks are added.
+	Seconds int         // How long each question can be answered for. Defaults to 30.
+	Rounds  int         // How many questions a quiz asks. Defaults to 10.
+}
+
+// TagsConfig configures tags, which are commands that admins add to their server while the bot runs.
+type TagsConfig struct {
+	Enabled bool // When true, admins can add, edit and delete tags, which reply with a response they write.
+}
+
+// AccessConfig configures which commands can be used in each server, and in which channels.
+type AccessConfig struct {
+	Enabled bool // When true, admins can disable commands in their server, or only allow them in some channels.
+}
+
+// settingsFile is what config.json holds.
+type settingsFile struct {
+	discordservice.DiscordConfig
+	Settings
+}
+
+// TelemetryConfig configures OpenTelemetry tracing.
+// Empty fields fall back to the standard OTEL_* environment variables.
+type TelemetryConfig struct {
+	Exporter         string   // "none", "stdout", "file", "otlpgrpc" or "otlphttp". Defaults to OTEL_TRACES_EXPORTER, then "otlpgrpc".
+	Endpoint         string   // URL of an OTLP collector. Defaults to OTEL_EXPORTER_OTLP_ENDPOINT.
+	Insecure         *bool    // When true, OTLP exporters don't use TLS. Defaults to true, as a collector is usually local, so set it to false to use TLS.
+	Filepath         string   // Where spans are written to when Exporter is "file".
+	SampleRatio      float64  // Fraction of traces to keep, between 0 and 1. When 0, OTEL_TRACES_SAMPLER is used (which defaults to keeping everything).
+	RedactAttributes []string // Span attributes to redact before export. When missing, DefaultRedactAttributes is used, use [] to redact nothing.
+}
+
+// Exporters supported by TelemetryConfig.
+const (
+	ExporterNone     = "none"
+	ExporterStdout   = "stdout"
+	ExporterFile     = "file"
+	ExporterOTLPGRPC = "otlpgrpc"
+	ExporterOTLPHTTP = "otlphttp"
+)
+
+// DefaultRedactAttributes are span attributes that can contain text written by users.
+var DefaultRedactAttributes = []string{"msg.title", "msg.description"}
+
+// ResolvedExporter returns the exporter to use, considering environment variables
+// when Exporter is empty.
+func (t TelemetryConfig) ResolvedExporter() string {
+	if t.Exporter != "" {
+		return t.Exporter
+	}
+
+	if os.Getenv("OTEL_SDK_DISABLED") == "true" {
+		return ExporterNone
+	}
+
+	switch os.Getenv("OTEL_TRACES_EXPORTER") {
+	case "none":
+		return ExporterNone
+	case "console":
+		return ExporterStdout
+	}
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL") == "http/protobuf" {
+		return ExporterOTLPHTTP
+	}
+
+	return ExporterOTLPGRPC
+}
+
+// ResolvedInsecure returns whether OTLP exporters connect without TLS, which they do unless Insecure is false.
+func (t TelemetryConfig) ResolvedInsecure() bool {
+	return t.Insecure == nil || *t.Insecure
+}
+
+// ResolvedRedactAttributes returns the attributes to redact, using
+// DefaultRedactAttributes if RedactAttributes was never set.
+func (t TelemetryConfig) ResolvedRedactAttributes() []string {
+	if t.RedactAttributes == nil {
+		return DefaultRedactAttributes
+	}
+	return t.RedactAttributes
+}
+
+// GetSettings reads Settings from the unified file in configDir, or otherwise its config.json file.
+// If neither file exists, default Settings are returned without an error,
+// as config.json is created by the service that requires it.
+func GetSettings(configDir string) (Settings, error) {
+	if unified, ok := UnifiedFilepath(configDir); ok {
+		botConfig, err := ReadUnifiedConfig(unified)
+		return botConfig.Settings, err
+	}
+
+	var settings Settings
+	bytes, err := os.ReadFile(path.Join(configDir, settingsFilepath))
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+
+	if err != nil {
+		return settings, err
+	}
+
+	return settings, json.Unmarshal(bytes, &settings)
+}
@@ -0,0 +1,876 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/andybalholm/cascadia"
+
+	"github.com/BKrajancic/boby/m/v2/src/access"
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/feed"
+	"github.com/BKrajancic/boby/m/v2/src/flashcard"
+	"github.com/BKrajancic/boby/m/v2/src/quiz"
+	"github.com/BKrajancic/boby/m/v2/src/routine"
+	"github.com/BKrajancic/boby/m/v2/src/tag"
+)
+
+// A ValidationError describes a problem with part of a configuration file.
+type ValidationError struct {
+	File    string // Name of the file with the problem.
+	Path    string // JSON path to the problem within the file, such as "$[0].TitleSelector".
+	Reason  string // What the problem is.
+	Warning bool   // When true, the configuration still loads, but may not behave as expected.
+}
+
+// Error formats a ValidationError as "file: path: reason".
+func (v ValidationError) Error() string {
+	severity := "error"
+	if v.Warning {
+		severity = "warning"
+	}
+
+	if v.Path == "" {
+		return fmt.Sprintf("%s: %s: %s", v.File, severity, v.Reason)
+	}
+	return fmt.Sprintf("%s: %s: %s: %s", v.File, v.Path, severity, v.Reason)
+}
+
+// Discord's limits for slash commands.
+const (
+	discordNameLimit        = 32
+	discordDescriptionLimit = 100
+)
+
+// discordName matches names Discord accepts for slash commands and their options.
+var discordName = regexp.MustCompile(`^[-_\p{L}\p{N}]{1,32}$`)
+
+// parameterTypes are the types that a Parameter can have.
+var parameterTypes = []string{"string", "int", "bool", "user", "role"}
+
+// extractModes are the values SelectorCapture.Extract can have.
+var extractModes = []string{"", "Text", "HTML", "Markdown"}
+
+// tokenTypes are the values TokenMaker.Type can have.
+var tokenTypes = []string{"", "MD5"}
+
+// exporters are the values TelemetryConfig.Exporter can have.
+var exporters = []string{"", ExporterNone, ExporterStdout, ExporterFile, ExporterOTLPGRPC, ExporterOTLPHTTP}
+
+// logFormats are the values logging.Config.Format can have.
+var logFormats = []string{"", "text", "json"}
+
+// logLevels are the values logging.Config.Level can have.
+var logLevels = []string{"", "debug", "info", "warn", "error"}
+
+// builtinTriggers are triggers of commands that every bot has.
+var builtinTriggers = []string{"help", "render", ReloadTrigger}
+
+// Validate checks every configuration file in configDir, returning each problem found.
+// Unlike ConfiguredBot, it doesn't stop at the first problem.
+func Validate(configDir string) []ValidationError {
+	v := validator{triggers: make(map[string][]triggerSource)}
+
+	for _, trigger := range builtinTriggers {
+		v.addTrigger(trigger, triggerSource{file: "(built in)"})
+	}
+
+	if unified, ok := UnifiedFilepath(configDir); ok {
+		v.checkUnified(unified)
+	} else {
+		v.checkLegacy(configDir)
+	}
+
+	v.checkDuplicateTriggers()
+	return v.errors
+}
+
+// HasErrors returns true if any problem in problems isn't a warning.
+func HasErrors(problems []ValidationError) bool {
+	for _, problem := range problems {
+		if !problem.Warning {
+			return true
+		}
+	}
+	return false
+}
+
+// triggerSource is where a trigger was configured.
+type triggerSource struct {
+	file string
+	path string
+}
+
+// validator collects problems while configuration files are checked.
+type validator struct {
+	errors   []ValidationError
+	triggers map[string][]triggerSource
+}
+
+// fail records an error.
+func (v *validator) fail(file string, jsonPath string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{File: file, Path: jsonPath, Reason: fmt.Sprintf(format, args...)})
+}
+
+// warn records a warning.
+func (v *validator) warn(file string, jsonPath string, format string, args ...interface{}) {
+	v.errors = append(v.errors, ValidationError{File: file, Path: jsonPath, Reason: fmt.Sprintf(format, args...), Warning: true})
+}
+
+// checkLegacy checks a configuration directory that has a file for each type of command.
+func (v *validator) checkLegacy(configDir string) {
+	var settings settingsFile
+	if v.decode(configDir, settingsFilepath, &settings) {
+		v.checkSettings(settingsFilepath, settings.Settings)
+	}
+
+	var jsonGetters []command.JSONGetterConfig
+	if v.decode(configDir, jsonFilepath, &jsonGetters) {
+		for i, jsonGetter := range jsonGetters {
+			v.checkJSONGetter(jsonFilepath, fmt.Sprintf("$[%d]", i), jsonGetter)
+		}
+	}
+
+	var regexpScrapers []command.RegexpScraperConfig
+	if v.decode(configDir, regexpFilepath, &regexpScrapers) {
+		for i, regexpScraper := range regexpScrapers {
+			v.checkRegexpScraper(regexpFilepath, fmt.Sprintf("$[%d]", i), regexpScraper)
+		}
+	}
+
+	var goqueryScrapers []command.GoQueryScraperConfig
+	if v.decode(configDir, goqueryFilepath, &goqueryScrapers) {
+		for i, goqueryScraper := range goqueryScrapers {
+			v.checkGoQueryScraper(goqueryFilepath, fmt.Sprintf("$[%d]", i), goqueryScraper)
+		}
+	}
+
+	var oxfordConfigs []command.OxfordDictionaryConfig
+	if v.decode(configDir, oxfordFilepath, &oxfordConfigs) {
+		for i, oxfordConfig := range oxfordConfigs {
+			v.checkOxford(oxfordFilepath, fmt.Sprintf("$[%d]", i), oxfordConfig)
+		}
+	}
+
+	// XML scrapers are optional, so a missing file isn't a problem.
+	var xmlScrapers []command.XMLScraperConfig
+	if _, err := os.Stat(path.Join(configDir, xmlFilepath)); err == nil && v.decode(configDir, xmlFilepath, &xmlScrapers) {
+		for i, xmlScraper := range xmlScrapers {
+			v.checkXMLScraper(xmlFilepath, fmt.Sprintf("$[%d]", i), xmlScraper)
+		}
+	}
+
+	var adminConfig command.AdminConfig
+	if v.decode(configDir, adminConfigFilepath, &adminConfig) && adminConfig.Enabled {
+		for _, adminCommand := range command.AdminCommands() {
+			v.addTrigger(adminCommand.Trigger, triggerSource{file: adminConfigFilepath, path: "$.Enabled"})
+		}
+	}
+
+	// Dailies are checked last, as they run other commands. They are optional, so a missing file isn't a problem.
+	var dailies []command.DailyConfig
+	if _, err := os.Stat(path.Join(configDir, dailyFilepath)); err == nil && v.decode(configDir, dailyFilepath, &dailies) {
+		for i, daily := range dailies {
+			v.checkDaily(dailyFilepath, fmt.Sprintf("$[%d]", i), daily, configDir)
+		}
+	}
+	v.checkFlashcardLookup(settingsFilepath, settings.Flashcards)
+}
+
+// checkUnified checks a single file holding the whole configuration.
+func (v *validator) checkUnified(filepath string) {
+	filename := path.Base(filepath)
+	bytes, err := os.ReadFile(filepath)
+	if err != nil {
+		v.fail(filename, "", "%s", err)
+		return
+	}
+
+	jsonBytes, err := unifiedToJSON(filename, bytes)
+	if err != nil {
+		var syntaxErr *json.SyntaxError
+		var envErr EnvError
+		if errors.As(err, &envErr) {
+			v.fail(filename, envErr.Path, "environment variable %s is not set", envErr.Name)
+		} else if errors.As(err, &syntaxErr) {
+			line, column := lineAndColumn(bytes, syntaxErr.Offset)
+			v.fail(filename, "", "invalid JSON at line %d, column %d: %s", line, column, syntaxErr)
+		} else {
+			v.fail(filename, "", "%s", err)
+		}
+		return
+	}
+
+	var botConfig BotConfig
+	if !v.decodeBytes(filename, jsonBytes, &botConfig) {
+		return
+	}
+
+	v.checkSettings(filename, botConfig.Settings)
+	if botConfig.Discord.Token == "" {
+		v.fail(filename, "$.Discord.Token", "a token is required to connect to Discord")
+	}
+
+	for i, jsonGetter := range botConfig.JSONGetters {
+		v.checkJSONGetter(filename, fmt.Sprintf("$.JSONGetters[%d]", i), jsonGetter)
+	}
+
+	for i, regexpScraper := range botConfig.RegexpScrapers {
+		v.checkRegexpScraper(filename, fmt.Sprintf("$.RegexpScrapers[%d]", i), regexpScraper)
+	}
+
+	for i, goqueryScraper := range botConfig.GoQueryScrapers {
+		v.checkGoQueryScraper(filename, fmt.Sprintf("$.GoQueryScrapers[%d]", i), goqueryScraper)
+	}
+
+	for i, oxfordConfig := range botConfig.Oxford {
+		v.checkOxford(filename, fmt.Sprintf("$.Oxford[%d]", i), oxfordConfig)
+	}
+
+	for i, xmlScraper := range botConfig.XMLScrapers {
+		v.checkXMLScraper(filename, fmt.Sprintf("$.XMLScrapers[%d]", i), xmlScraper)
+	}
+
+	if botConfig.Admin.Enabled {
+		for _, adminCommand := range command.AdminCommands() {
+			v.addTrigger(adminCommand.Trigger, triggerSource{file: filename, path: "$.Admin.Enabled"})
+		}
+	}
+
+	// Dailies are checked last, as they run other commands.
+	for i, daily := range botConfig.Dailies {
+		v.checkDaily(filename, fmt.Sprintf("$.Dailies[%d]", i), daily, path.Dir(filepath))
+	}
+	v.checkFlashcardLookup(filename, botConfig.Flashcards)
+}
+
+// decode reads the JSON file filename into out. Syntax errors, unknown fields and
+// values of the wrong type are recorded. Returns true if out can be checked further.
+func (v *validator) decode(configDir string, filename string, out interface{}) bool {
+	bytes, err := os.ReadFile(path.Join(configDir, filename))
+	if err != nil {
+		v.fail(filename, "", "%s", err)
+		return false
+	}
+
+	return v.decodeBytes(filename, bytes, out)
+}
+
+// decodeBytes is decode, for a file that has already been read.
+func (v *validator) decodeBytes(filename string, bytes []byte, out interface{}) bool {
+	var raw interface{}
+	if err := json.Unmarshal(bytes, &raw); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line, column := lineAndColumn(bytes, syntaxErr.Offset)
+			v.fail(filename, "", "invalid JSON at line %d, column %d: %s", line, column, syntaxErr)
+		} else {
+			v.fail(filename, "", "invalid JSON: %s", err)
+		}
+		return false
+	}
+
+	before := len(v.errors)
+	v.checkStructure(filename, "$", raw, reflect.TypeOf(out).Elem())
+	if len(v.errors) > before {
+		return false
+	}
+
+	if err := json.Unmarshal(bytes, out); err != nil {
+		v.fail(filename, "", "%s", err)
+		return false
+	}
+	return true
+}
+
+// lineAndColumn converts an offset in bytes to a 1-based line and column.
+func lineAndColumn(bytes []byte, offset int64) (line int, column int) {
+	line, column = 1, 1
+	for i := int64(0); i < offset-1 && i < int64(len(bytes)); i++ {
+		if bytes[i] == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// checkStructure compares decoded JSON against the type it will be unmarshalled to,
+// recording unknown fields and values of the wrong type.
+func (v *validator) checkStructure(file string, jsonPath string, value interface{}, typ reflect.Type) {
+	if value == nil {
+		return
+	}
+
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(file, jsonPath, "expected an object, got %s", jsonTypeName(value))
+			return
+		}
+
+		fields := jsonFields(typ)
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			field, ok := findField(fields, key)
+			if !ok {
+				v.fail(file, jsonPath+"."+key, "unknown field %q", key)
+				continue
+			}
+			v.checkStructure(file, jsonPath+"."+key, object[key], field.Type)
+		}
+
+	case reflect.Slice, reflect.Array:
+		array, ok := value.([]interface{})
+		if !ok {
+			v.fail(file, jsonPath, "expected an array, got %s", jsonTypeName(value))
+			return
+		}
+		for i, item := range array {
+			v.checkStructure(file, fmt.Sprintf("%s[%d]", jsonPath, i), item, typ.Elem())
+		}
+
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(file, jsonPath, "expected an object, got %s", jsonTypeName(value))
+			return
+		}
+		for key, item := range object {
+			v.checkStructure(file, jsonPath+"."+key, item, typ.Elem())
+		}
+
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			v.fail(file, jsonPath, "expected a string, got %s", jsonTypeName(value))
+		}
+
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			v.fail(file, jsonPath, "expected true or false, got %s", jsonTypeName(value))
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, ok := value.(float64)
+		if !ok {
+			v.fail(file, jsonPath, "expected a whole number, got %s", jsonTypeName(value))
+		} else if number != float64(int64(number)) {
+			v.fail(file, jsonPath, "expected a whole number, got %v", number)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if _, ok := value.(float64); !ok {
+			v.fail(file, jsonPath, "expected a number, got %s", jsonTypeName(value))
+		}
+	}
+}
+
+// jsonFields returns the fields of a struct that encoding/json uses, including
+// the fields of embedded structs.
+func jsonFields(typ reflect.Type) []reflect.StructField {
+	fields := []reflect.StructField{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+
+		if !field.IsExported() || field.Tag.Get("json") == "-" {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// findField finds the field that key is unmarshalled to, preferring an exact match
+// but otherwise ignoring case (as encoding/json does).
+func findField(fields []reflect.StructField, key string) (reflect.StructField, bool) {
+	for _, field := range fields {
+		if jsonName(field) == key {
+			return field, true
+		}
+	}
+	for _, field := range fields {
+		if strings.EqualFold(jsonName(field), key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// jsonName returns the key of a field in JSON.
+func jsonName(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// jsonTypeName describes the type of a decoded JSON value.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "true or false"
+	}
+	return "null"
+}
+
+// checkSettings checks the options of config.json, or of a unified file.
+func (v *validator) checkSettings(file string, settings Settings) {
+	v.checkOneOf(file, "$.Telemetry.Exporter", settings.Telemetry.Exporter, exporters)
+	if settings.Telemetry.Exporter == ExporterFile && settings.Telemetry.Filepath == "" {
+		v.fail(file, "$.Telemetry.Filepath", "required when Exporter is %q", ExporterFile)
+	}
+	if settings.Telemetry.SampleRatio < 0 || settings.Telemetry.SampleRatio > 1 {
+		v.fail(file, "$.Telemetry.SampleRatio", "must be between 0 and 1")
+	}
+
+	v.checkOneOf(file, "$.Logging.Format", strings.ToLower(settings.Logging.Format), logFormats)
+	v.checkOneOf(file, "$.Logging.Level", strings.ToLower(settings.Logging.Level), logLevels)
+
+	if settings.Feeds.PollMinutes < 0 {
+		v.fail(file, "$.Feeds.PollMinutes", "must not be negative")
+	}
+	if settings.Feeds.MaxPosts < 0 {
+		v.fail(file, "$.Feeds.MaxPosts", "must not be negative")
+	}
+	if settings.Feeds.PollMinutes > 0 {
+		for _, trigger := range feed.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Feeds.PollMinutes"})
+		}
+	}
+
+	if _, err := routine.LoadLocation(settings.Scheduler.Timezone); err != nil {
+		v.fail(file, "$.Scheduler.Timezone", "%s", err)
+	}
+	if settings.Scheduler.Enabled {
+		for _, trigger := range routine.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Scheduler.Enabled"})
+		}
+	}
+	if settings.Scheduler.Reminders {
+		for _, trigger := range routine.ReminderTriggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Scheduler.Reminders"})
+		}
+	}
+
+	if settings.Flashcards.Enabled {
+		for _, trigger := range flashcard.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Flashcards.Enabled"})
+		}
+	}
+
+	v.checkQuiz(file, settings.Quiz)
+
+	if settings.Tags.Enabled {
+		for _, trigger := range tag.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Tags.Enabled"})
+		}
+	}
+	if settings.Access.Enabled {
+		for _, trigger := range access.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Access.Enabled"})
+		}
+	}
+}
+
+// checkQuiz checks the decks and options of quizzes.
+func (v *validator) checkQuiz(file string, config QuizConfig) {
+	if config.Seconds < 0 {
+		v.fail(file, "$.Quiz.Seconds", "must not be negative")
+	}
+	if config.Rounds < 0 {
+		v.fail(file, "$.Quiz.Rounds", "must not be negative")
+	}
+
+	names := map[string]bool{}
+	for i, deck := range config.Decks {
+		jsonPath := fmt.Sprintf("$.Quiz.Decks[%d]", i)
+		name := strings.ToLower(deck.Name)
+		if name == "" {
+			v.fail(file, jsonPath+".Name", "is required")
+		} else if names[name] {
+			v.fail(file, jsonPath+".Name", "%q is the name of another deck", deck.Name)
+		}
+		names[name] = true
+
+		if len(deck.Questions) == 0 {
+			v.fail(file, jsonPath+".Questions", "a deck needs at least one question")
+		}
+		for j, question := range deck.Questions {
+			questionPath := fmt.Sprintf("%s.Questions[%d]", jsonPath, j)
+			if question.Prompt == "" {
+				v.fail(file, questionPath+".Prompt", "is required")
+			}
+			if !slices.ContainsFunc(question.Answers, func(answer string) bool { return strings.TrimSpace(answer) != "" }) {
+				v.fail(file, questionPath+".Answers", "a question needs at least one answer")
+			}
+		}
+	}
+
+	if len(config.Decks) > 0 {
+		for _, trigger := range quiz.Triggers {
+			v.addTrigger(trigger, triggerSource{file: file, path: "$.Quiz.Decks"})
+		}
+	}
+}
+
+// checkFlashcardLookup checks that the Lookup command of flashcards exists. It runs after every
+// command has been checked.
+func (v *validator) checkFlashcardLookup(file string, flashcards FlashcardsConfig) {
+	if flashcards.Lookup == "" {
+		return
+	}
+
+	if !flashcards.Enabled {
+		v.warn(file, "$.Flashcards.Lookup", "is ignored unless Enabled is true")
+	} else if _, ok := v.triggers[flashcards.Lookup]; !ok {
+		v.fail(file, "$.Flashcards.Lookup", "there is no command %q", flashcards.Lookup)
+	}
+}
+
+// checkJSONGetter checks a JSONGetterConfig at jsonPath in file.
+func (v *validator) checkJSONGetter(file string, jsonPath string, config command.JSONGetterConfig) {
+	v.checkCommand(file, jsonPath, config.Trigger, "Help", config.Help, config.Parameters)
+	v.checkURL(file, jsonPath+".URL", config.URL, config.Parameters, false)
+	v.checkJSONCapture(file, jsonPath+".Message", config.Message)
+	for i, field := range config.Fields {
+		v.checkJSONCapture(file, fmt.Sprintf("%s.Fields[%d]", jsonPath, i), field)
+	}
+	v.checkOneOf(file, jsonPath+".Token.Type", config.Token.Type, tokenTypes)
+	if config.Token.Type == "MD5" && (config.Token.Size < 0 || config.Token.Size > 32) {
+		v.fail(file, jsonPath+".Token.Size", "must be between 0 and 32 for MD5")
+	}
+	v.checkRateLimit(file, jsonPath+".RateLimit", config.RateLimit)
+	v.checkColor(file, jsonPath+".Color", config.Color)
+	v.checkFieldCapture(file, jsonPath+".Footer", config.Footer)
+	v.checkFieldCapture(file, jsonPath+".Author", config.Author)
+	v.checkFieldCapture(file, jsonPath+".Thumbnail", config.Thumbnail)
+	v.checkFieldCapture(file, jsonPath+".Timestamp", config.Timestamp)
+}
+
+// checkJSONCapture checks a JSONCapture at jsonPath in file.
+func (v *validator) checkJSONCapture(file string, jsonPath string, capture command.JSONCapture) {
+	v.checkFieldCapture(file, jsonPath+".Title", capture.Title)
+	v.checkFieldCapture(file, jsonPath+".Body", capture.Body)
+}
+
+// checkFieldCapture checks a FieldCapture at jsonPath in file.
+func (v *validator) checkFieldCapture(file string, jsonPath string, capture command.FieldCapture) {
+	substitutions := strings.Count(capture.Template, "%s")
+	if substitutions > len(capture.Selectors) {
+		v.fail(file, jsonPath+".Template", "has %d %%s but only %d selectors", substitutions, len(capture.Selectors))
+	}
+}
+
+// checkColor checks that a color is a hex code.
+func (v *validator) checkColor(file string, jsonPath string, color string) {
+	if _, err := command.ParseColor(color); err != nil {
+		v.fail(file, jsonPath, "%s", err)
+	}
+}
+
+// checkRateLimit checks a RateLimitConfig at jsonPath in file.
+func (v *validator) checkRateLimit(file string, jsonPath string, config command.RateLimitConfig) {
+	if config.TimesPerInterval == 0 && config.SecondsPerInterval == 0 {
+		return
+	}
+
+	if config.TimesPerInterval <= 0 {
+		v.fail(file, jsonPath+".TimesPerInterval", "must be above 0")
+	}
+	if config.SecondsPerInterval <= 0 {
+		v.fail(file, jsonPath+".SecondsPerInterval", "must be above 0")
+	}
+	if config.ID == "" {
+		v.fail(file, jsonPath+".ID", "is required to store rate limiting")
+	}
+}
+
+// checkRegexpScraper checks a RegexpScraperConfig at jsonPath in file.
+func (v *validator) checkRegexpScraper(file string, jsonPath string, config command.RegexpScraperConfig) {
+	v.checkCommand(file, jsonPath, config.Trigger, "Help", config.Help, config.Parameters)
+	v.checkURL(file, jsonPath+".URL", config.URL, config.Parameters, true)
+	if _, err := regexp.Compile(config.ReplyCapture); err != nil {
+		v.fail(file, jsonPath+".ReplyCapture", "invalid regular expression: %s", err)
+	}
+	if _, err := regexp.Compile(config.TitleCapture); err != nil {
+		v.fail(file, jsonPath+".TitleCapture", "invalid regular expression: %s", err)
+	}
+}
+
+// checkGoQueryScraper checks a GoQueryScraperConfig at jsonPath in file.
+func (v *validator) checkGoQueryScraper(file string, jsonPath string, config command.GoQueryScraperConfig) {
+	v.checkCommand(file, jsonPath, config.Trigger, "Help", config.Help, config.Parameters)
+	v.checkURL(file, jsonPath+".URL", config.URL, config.Parameters, true)
+	v.checkSelectorCapture(file, jsonPath+".TitleSelector", config.TitleSelector)
+	v.checkSelectorCapture(file, jsonPath+".ReplySelector", config.ReplySelector)
+	for i, field := range config.Fields {
+		v.checkSelectorCapture(file, fmt.Sprintf("%s.Fields[%d].Title", jsonPath, i), field.Title)
+		v.checkSelectorCapture(file, fmt.Sprintf("%s.Fields[%d].Description", jsonPath, i), field.Description)
+	}
+	v.checkColor(file, jsonPath+".Color", config.Color)
+	v.checkSelectorCapture(file, jsonPath+".Footer", config.Footer)
+	v.checkSelectorCapture(file, jsonPath+".Author", config.Author)
+	v.checkSelectorCapture(file, jsonPath+".Thumbnail", config.Thumbnail)
+	v.checkSelectorCapture(file, jsonPath+".Timestamp", config.Timestamp)
+	v.checkSelectorCapture(file, jsonPath+".ImageSelector", config.ImageSelector)
+	if len(config.ImageSelector.Selectors) > 0 && config.ImageSelector.Attribute == "" {
+		v.fail(file, jsonPath+".ImageSelector.Attribute", "is required to find the image's URL, such as \"src\"")
+	}
+	if config.ImageMaxBytes < 0 {
+		v.fail(file, jsonPath+".ImageMaxBytes", "must not be negative")
+	}
+	if config.ImageMaxSide < 0 {
+		v.fail(file, jsonPath+".ImageMaxSide", "must not be negative")
+	}
+}
+
+// checkXMLScraper checks an XMLScraperConfig at jsonPath in file.
+func (v *validator) checkXMLScraper(file string, jsonPath string, config command.XMLScraperConfig) {
+	v.checkCommand(file, jsonPath, config.Trigger, "Help", config.Help, config.Parameters)
+	v.checkURL(file, jsonPath+".URL", config.URL, config.Parameters, false)
+	v.checkXPathCapture(file, jsonPath+".Title", config.Title, config.Namespaces)
+	v.checkXPathCapture(file, jsonPath+".Body", config.Body, config.Namespaces)
+	for i, field := range config.Fields {
+		v.checkXPathCapture(file, fmt.Sprintf("%s.Fields[%d].Title", jsonPath, i), field.Title, config.Namespaces)
+		v.checkXPathCapture(file, fmt.Sprintf("%s.Fields[%d].Body", jsonPath, i), field.Body, config.Namespaces)
+	}
+	v.checkColor(file, jsonPath+".Color", config.Color)
+	v.checkRateLimit(file, jsonPath+".RateLimit", config.RateLimit)
+}
+
+// checkDaily checks a DailyConfig at jsonPath in file, where WordsFile is relative to configDir.
+// Every other command must have been checked first, so that Target can be found.
+func (v *validator) checkDaily(file string, jsonPath string, config command.DailyConfig, configDir string) {
+	v.checkCommand(file, jsonPath, config.Trigger, "Help", config.Help, nil)
+
+	if config.Target == "" {
+		v.fail(file, jsonPath+".Target", "is required")
+	} else if _, ok := v.triggers[config.Target]; !ok {
+		v.fail(file, jsonPath+".Target", "there is no command %q", config.Target)
+	}
+
+	if len(config.Words) == 0 && config.WordsFile == "" && config.WordsURL == "" {
+		v.fail(file, jsonPath, "one of Words, WordsFile or WordsURL is required")
+	}
+
+	if config.WordsFile != "" {
+		wordsFile := config.WordsFile
+		if !path.IsAbs(wordsFile) {
+			wordsFile = path.Join(configDir, wordsFile)
+		}
+		if _, err := os.Stat(wordsFile); err != nil {
+			v.fail(file, jsonPath+".WordsFile", "%s", err)
+		}
+	}
+
+	if config.History < 0 {
+		v.fail(file, jsonPath+".History", "must not be negative")
+	}
+}
+
+// checkXPathCapture checks an XPathCapture at jsonPath in file, where selectors can use prefixes in namespaces.
+func (v *validator) checkXPathCapture(file string, jsonPath string, capture command.XPathCapture, namespaces map[string]string) {
+	for i, selector := range capture.Selectors {
+		if err := command.CheckXPath(selector, namespaces); err != nil {
+			v.fail(file, fmt.Sprintf("%s.Selectors[%d]", jsonPath, i), "invalid XPath: %s", err)
+		}
+	}
+
+	substitutions := strings.Count(capture.Template, "%s")
+	if len(capture.Selectors) > 0 && substitutions != len(capture.Selectors) {
+		v.fail(file, jsonPath+".Template", "has %d %%s but there are %d selectors", substitutions, len(capture.Selectors))
+	}
+
+	if err := command.CheckHandleMultiple(capture.HandleMultiple); err != nil {
+		v.fail(file, jsonPath+".HandleMultiple", "%s", err)
+	}
+
+	if capture.Limit < 0 {
+		v.fail(file, jsonPath+".Limit", "must not be negative")
+	}
+}
+
+// checkSelectorCapture checks a SelectorCapture at jsonPath in file.
+func (v *validator) checkSelectorCapture(file string, jsonPath string, capture command.SelectorCapture) {
+	for i, selector := range capture.Selectors {
+		if _, err := cascadia.Compile(selector); err != nil {
+			v.fail(file, fmt.Sprintf("%s.Selectors[%d]", jsonPath, i), "invalid selector: %s", err)
+		}
+	}
+
+	substitutions := strings.Count(capture.Template, "%s")
+	if len(capture.Selectors) > 0 && substitutions != len(capture.Selectors) {
+		v.fail(file, jsonPath+".Template", "has %d %%s but there are %d selectors", substitutions, len(capture.Selectors))
+	}
+
+	if len(capture.Replacements) > len(capture.Selectors) {
+		v.warn(file, jsonPath+".Replacements", "has more entries than Selectors, extra entries are ignored")
+	}
+
+	if err := command.CheckHandleMultiple(capture.HandleMultiple); err != nil {
+		v.fail(file, jsonPath+".HandleMultiple", "%s", err)
+	}
+
+	if capture.Limit < 0 {
+		v.fail(file, jsonPath+".Limit", "must not be negative")
+	}
+
+	v.checkOneOf(file, jsonPath+".Extract", capture.Extract, extractModes)
+	if capture.Attribute != "" && capture.Extract != "" {
+		v.warn(file, jsonPath+".Extract", "is ignored because Attribute is set")
+	}
+}
+
+// checkOxford checks an OxfordDictionaryConfig at jsonPath in file.
+func (v *validator) checkOxford(file string, jsonPath string, config command.OxfordDictionaryConfig) {
+	parameters := []command.Parameter{{Type: "string", Name: "word", Description: "word to translate"}}
+	v.checkCommand(file, jsonPath, config.Trigger, "HelpText", config.HelpText, parameters)
+	if config.TimesPerInterval > 0 || config.SecondsPerInterval > 0 {
+		v.addTrigger("info"+config.Trigger, triggerSource{file: file, path: jsonPath + ".Trigger"})
+	}
+	if config.AppID == "" {
+		v.fail(file, jsonPath+".AppID", "is required")
+	}
+	if config.AppKey == "" {
+		v.fail(file, jsonPath+".AppKey", "is required")
+	}
+}
+
+// checkCommand checks the parts of a config that every command has.
+// helpField is the name of the field help is read from.
+func (v *validator) checkCommand(file string, jsonPath string, trigger string, helpField string, help string, parameters []command.Parameter) {
+	v.addTrigger(trigger, triggerSource{file: file, path: jsonPath + ".Trigger"})
+	v.checkName(file, jsonPath+".Trigger", trigger)
+	v.checkDescription(file, jsonPath+"."+helpField, help)
+
+	for i, parameter := range parameters {
+		parameterPath := fmt.Sprintf("%s.Parameters[%d]", jsonPath, i)
+		v.checkOneOf(file, parameterPath+".Type", parameter.Type, parameterTypes)
+		v.checkName(file, parameterPath+".Name", parameter.Name)
+		v.checkDescription(file, parameterPath+".Description", parameter.Description)
+	}
+}
+
+// checkName checks that name can be used as the name of a slash command or option.
+func (v *validator) checkName(file string, jsonPath string, name string) {
+	if name == "" {
+		v.fail(file, jsonPath, "is required")
+	} else if utf8.RuneCountInString(name) > discordNameLimit {
+		v.fail(file, jsonPath, "must be at most %d characters for Discord", discordNameLimit)
+	} else if !discordName.MatchString(name) || strings.ToLower(name) != name {
+		v.fail(file, jsonPath, "%q must be lowercase letters, numbers, '-' or '_' for Discord", name)
+	}
+}
+
+// checkDescription checks that description can be used as the description of a slash command or option.
+func (v *validator) checkDescription(file string, jsonPath string, description string) {
+	if description == "" {
+		v.fail(file, jsonPath, "is required for Discord")
+	} else if utf8.RuneCountInString(description) > discordDescriptionLimit {
+		v.warn(file, jsonPath, "is longer than %d characters, so it is shortened for Discord", discordDescriptionLimit)
+	}
+}
+
+// checkURL checks that a URL template has a %s for the parameters it's used with.
+// If exact, there must be a %s for every parameter, otherwise there can be fewer.
+func (v *validator) checkURL(file string, jsonPath string, url string, parameters []command.Parameter, exact bool) {
+	if url == "" {
+		v.fail(file, jsonPath, "is required")
+		return
+	}
+
+	substitutions := strings.Count(url, "%s")
+	if substitutions > len(parameters) {
+		v.fail(file, jsonPath, "has %d %%s but only %d parameters", substitutions, len(parameters))
+	} else if exact && substitutions < len(parameters) {
+		v.fail(file, jsonPath, "has %d %%s but there are %d parameters", substitutions, len(parameters))
+	}
+}
+
+// checkOneOf checks that value is one of options.
+func (v *validator) checkOneOf(file string, jsonPath string, value string, options []string) {
+	for _, option := range options {
+		if value == option {
+			return
+		}
+	}
+
+	quoted := []string{}
+	for _, option := range options {
+		if option != "" {
+			quoted = append(quoted, fmt.Sprintf("%q", option))
+		}
+	}
+	v.fail(file, jsonPath, "%q must be one of %s", value, strings.Join(quoted, ", "))
+}
+
+// addTrigger remembers where a trigger was configured, so duplicates can be found.
+func (v *validator) addTrigger(trigger string, source triggerSource) {
+	if trigger != "" {
+		v.triggers[trigger] = append(v.triggers[trigger], source)
+	}
+}
+
+// checkDuplicateTriggers records every trigger that is used by more than one command.
+func (v *validator) checkDuplicateTriggers() {
+	triggers := make([]string, 0, len(v.triggers))
+	for trigger := range v.triggers {
+		triggers = append(triggers, trigger)
+	}
+	sort.Strings(triggers)
+
+	for _, trigger := range triggers {
+		sources := v.triggers[trigger]
+		if len(sources) < 2 {
+			continue
+		}
+
+		for i, source := range sources {
+			if source.path == "" {
+				continue // Built in commands are reported by what they clash with.
+			}
+
+			others := []string{}
+			for j, other := range sources {
+				if i != j {
+					others = append(others, strings.TrimSpace(other.file+" "+other.path))
+				}
+			}
+			v.fail(source.file, source.path, "trigger %q is also used by %s", trigger, strings.Join(others, ", "))
+		}
+	}
+}
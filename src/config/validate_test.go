@@ -0,0 +1,316 @@
+package config
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+// findProblem returns the problem for file at jsonPath, if there is one.
+func findProblem(problems []ValidationError, file string, jsonPath string) (ValidationError, bool) {
+	for _, problem := range problems {
+		if problem.File == file && problem.Path == jsonPath {
+			return problem, true
+		}
+	}
+	return ValidationError{}, false
+}
+
+func writeFile(t *testing.T, dir string, filename string, content string) {
+	if err := os.WriteFile(path.Join(dir, filename), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	problems := Validate(writeConfigDir(t, "valid"))
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestValidateExampleDir(t *testing.T) {
+	dir := path.Join(t.TempDir(), "example")
+	if err := MakeExampleDir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// An example only includes some files.
+	writeFile(t, dir, settingsFilepath, `{"Token": "token"}`)
+	writeFile(t, dir, oxfordFilepath, `[]`)
+	writeFile(t, dir, adminConfigFilepath, `{"Enabled": true}`)
+
+	if problems := Validate(dir); HasErrors(problems) {
+		t.Errorf("Expected the example to be valid, got %v", problems)
+	}
+}
+
+func TestValidateMissingFile(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	if err := os.Remove(path.Join(dir, regexpFilepath)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := findProblem(Validate(dir), regexpFilepath, ""); !ok {
+		t.Errorf("A missing file wasn't reported")
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, jsonFilepath, "[\n  {\"Trigger\": }\n]")
+
+	problem, ok := findProblem(Validate(dir), jsonFilepath, "")
+	if !ok || !strings.Contains(problem.Reason, "line 2") {
+		t.Errorf("Expected a syntax error on line 2, got %v", problem)
+	}
+}
+
+func TestValidateStructure(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, goqueryFilepath, `[{
+		"Trigger": "gq",
+		"Help": "help",
+		"URL": "https://",
+		"Unknown": true,
+		"TitleSelector": {"Selectors": [1]}
+	}]`)
+
+	problems := Validate(dir)
+	if _, ok := findProblem(problems, goqueryFilepath, "$[0].Unknown"); !ok {
+		t.Errorf("An unknown field wasn't reported, got %v", problems)
+	}
+
+	if _, ok := findProblem(problems, goqueryFilepath, "$[0].TitleSelector.Selectors[0]"); !ok {
+		t.Errorf("A value of the wrong type wasn't reported, got %v", problems)
+	}
+}
+
+func TestValidateCaseInsensitiveFields(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, adminConfigFilepath, `{"enabled": false}`)
+
+	if problems := Validate(dir); len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
+
+func TestValidateGoQuery(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, goqueryFilepath, `[{
+		"Trigger": "Bad Trigger",
+		"URL": "https://%s/%s",
+		"Parameters": [{"Type": "float", "Name": "word", "Description": "A word"}],
+		"TitleSelector": {"Template": "%s", "Selectors": ["h1[", "h2"], "HandleMultiple": "Middle"},
+		"ReplySelector": {"Template": "%s", "Selectors": ["p"], "HandleMultiple": "Range:4-2", "Limit": -1, "Extract": "Inner"},
+		"Color": "red",
+		"Thumbnail": {"Template": "%s", "Selectors": ["img["], "Attribute": "src"},
+		"ImageSelector": {"Template": "%s", "Selectors": ["img.comic"]},
+		"ImageMaxSide": -1
+	}]`)
+
+	problems := Validate(dir)
+	expect := []string{
+		"$[0].Trigger",
+		"$[0].Help",
+		"$[0].URL",
+		"$[0].Parameters[0].Type",
+		"$[0].TitleSelector.Selectors[0]",
+		"$[0].TitleSelector.Template",
+		"$[0].TitleSelector.HandleMultiple",
+		"$[0].ReplySelector.HandleMultiple",
+		"$[0].ReplySelector.Limit",
+		"$[0].ReplySelector.Extract",
+		"$[0].Color",
+		"$[0].Thumbnail.Selectors[0]",
+		"$[0].ImageSelector.Attribute",
+		"$[0].ImageMaxSide",
+	}
+	for _, jsonPath := range expect {
+		if _, ok := findProblem(problems, goqueryFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+
+	if !HasErrors(problems) {
+		t.Errorf("Problems should be errors")
+	}
+}
+
+func TestValidateRegexp(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, regexpFilepath, `[{
+		"Trigger": "rx",
+		"Help": "help",
+		"URL": "https://",
+		"ReplyCapture": "(unclosed",
+		"TitleCapture": "fine"
+	}]`)
+
+	problem, ok := findProblem(Validate(dir), regexpFilepath, "$[0].ReplyCapture")
+	if !ok || !strings.Contains(problem.Reason, "regular expression") {
+		t.Errorf("An invalid regular expression wasn't reported, got %v", problem)
+	}
+}
+
+func TestValidateJSONGetter(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, jsonFilepath, `[{
+		"Trigger": "js",
+		"Help": "help",
+		"URL": "https://",
+		"Message": {"Title": {"Template": "%s %s", "Selectors": ["one"]}},
+		"Token": {"Type": "SHA"},
+		"RateLimit": {"TimesPerInterval": 1},
+		"Color": "#12345",
+		"Footer": {"Template": "%s"}
+	}]`)
+
+	problems := Validate(dir)
+	expect := []string{
+		"$[0].Message.Title.Template",
+		"$[0].Token.Type",
+		"$[0].RateLimit.SecondsPerInterval",
+		"$[0].RateLimit.ID",
+		"$[0].Color",
+		"$[0].Footer.Template",
+	}
+	for _, jsonPath := range expect {
+		if _, ok := findProblem(problems, jsonFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+}
+
+func TestValidateXMLScraper(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, xmlFilepath, `[{
+		"Trigger": "xml",
+		"Help": "help",
+		"URL": "https://%s",
+		"Namespaces": {"atom": "http://www.w3.org/2005/Atom"},
+		"Title": {"Template": "%s", "Selectors": ["//atom:title"]},
+		"Body": {"Template": "%s", "Selectors": ["//entry["], "HandleMultiple": "Index:a"},
+		"Fields": [{"Title": {"Template": "%s %s", "Selectors": ["//a"]}, "Body": {"Template": "%s", "Selectors": ["//b"], "Limit": -1}}]
+	}]`)
+
+	problems := Validate(dir)
+	expect := []string{
+		"$[0].URL",
+		"$[0].Body.Selectors[0]",
+		"$[0].Body.HandleMultiple",
+		"$[0].Fields[0].Title.Template",
+		"$[0].Fields[0].Body.Limit",
+	}
+	for _, jsonPath := range expect {
+		if _, ok := findProblem(problems, xmlFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+
+	if _, ok := findProblem(problems, xmlFilepath, "$[0].Title.Selectors[0]"); ok {
+		t.Errorf("Expected a namespace prefix to be valid")
+	}
+}
+
+func TestValidateDuplicateTriggers(t *testing.T) {
+	dir := writeConfigDir(t, "help")
+	writeFile(t, dir, regexpFilepath, `[{"Trigger": "help", "Help": "help", "URL": "https://"}]`)
+
+	problems := Validate(dir)
+	for _, file := range []string{goqueryFilepath, regexpFilepath} {
+		problem, ok := findProblem(problems, file, "$[0].Trigger")
+		if !ok || !strings.Contains(problem.Reason, "also used by") {
+			t.Errorf("A duplicate trigger wasn't reported for %s, got %v", file, problems)
+		}
+	}
+}
+
+func TestValidateLongHelpIsWarning(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, goqueryFilepath, `[{"Trigger": "gq", "URL": "https://", "Help": "`+strings.Repeat("a", 101)+`"}]`)
+
+	problems := Validate(dir)
+	problem, ok := findProblem(problems, goqueryFilepath, "$[0].Help")
+	if !ok || !problem.Warning {
+		t.Errorf("Expected a warning for long help, got %v", problems)
+	}
+
+	if HasErrors(problems) {
+		t.Errorf("A warning shouldn't be an error")
+	}
+}
+
+func TestValidateDaily(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, dailyFilepath, `[
+		{"Trigger": "wotd", "Help": "help", "Target": "valid", "WordsFile": "words.txt"},
+		{"Trigger": "missing", "Help": "help", "Target": "nothing", "History": -1}
+	]`)
+	writeFile(t, dir, "words.txt", "kumusta\n")
+
+	problems := Validate(dir)
+	for _, jsonPath := range []string{"$[1].Target", "$[1]", "$[1].History"} {
+		if _, ok := findProblem(problems, dailyFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+
+	for _, problem := range problems {
+		if strings.HasPrefix(problem.Path, "$[0]") {
+			t.Errorf("Unexpected problem %s", problem)
+		}
+	}
+}
+
+func TestValidateSettings(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, settingsFilepath, `{"Token": "token", "Telemetry": {"Exporter": "file", "SampleRatio": 2}, "Logging": {"Level": "loud"}, "Feeds": {"PollMinutes": -1, "MaxPosts": -1}, "Scheduler": {"Timezone": "Nowhere/Town"}}`)
+
+	problems := Validate(dir)
+	for _, jsonPath := range []string{"$.Telemetry.Filepath", "$.Telemetry.SampleRatio", "$.Logging.Level", "$.Feeds.PollMinutes", "$.Feeds.MaxPosts", "$.Scheduler.Timezone"} {
+		if _, ok := findProblem(problems, settingsFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+}
+
+func TestValidateFlashcardLookup(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, settingsFilepath, `{"Token": "token", "Flashcards": {"Enabled": true, "Lookup": "valid"}}`)
+	if problems := Validate(dir); HasErrors(problems) {
+		t.Errorf("Expected a lookup command from another file to be found, got %v", problems)
+	}
+
+	writeFile(t, dir, settingsFilepath, `{"Token": "token", "Flashcards": {"Enabled": true, "Lookup": "nothing"}}`)
+	if _, ok := findProblem(Validate(dir), settingsFilepath, "$.Flashcards.Lookup"); !ok {
+		t.Errorf("Expected a missing lookup command to be reported")
+	}
+}
+
+func TestValidateQuiz(t *testing.T) {
+	dir := writeConfigDir(t, "valid")
+	writeFile(t, dir, settingsFilepath, `{"Token": "token", "Quiz": {"Seconds": -1, "Decks": [
+		{"Name": "tagalog", "Questions": [{"Prompt": "hello", "Answers": ["kumusta"]}, {"Prompt": "", "Answers": [" "]}]},
+		{"Name": "Tagalog", "Questions": []}
+	]}}`)
+
+	problems := Validate(dir)
+	for _, jsonPath := range []string{"$.Quiz.Seconds", "$.Quiz.Decks[0].Questions[1].Prompt", "$.Quiz.Decks[0].Questions[1].Answers", "$.Quiz.Decks[1].Name", "$.Quiz.Decks[1].Questions"} {
+		if _, ok := findProblem(problems, settingsFilepath, jsonPath); !ok {
+			t.Errorf("Expected a problem at %s, got %v", jsonPath, problems)
+		}
+	}
+
+	if _, ok := findProblem(problems, settingsFilepath, "$.Quiz.Decks[0].Questions[0].Answers"); ok {
+		t.Errorf("Unexpected problem with a valid question, got %v", problems)
+	}
+}
+
+func TestValidationErrorFormat(t *testing.T) {
+	problem := ValidationError{File: "file.json", Path: "$[0]", Reason: "bad"}
+	if problem.Error() != "file.json: $[0]: error: bad" {
+		t.Errorf("Unexpected format %s", problem.Error())
+	}
+}
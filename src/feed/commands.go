@@ -0,0 +1,197 @@
+package feed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// SubscribeTrigger is a trigger to use for a command that subscribes a channel to a feed.
+const SubscribeTrigger = "subscribe"
+
+// UnsubscribeTrigger is a trigger to use for a command that unsubscribes a channel from a feed.
+const UnsubscribeTrigger = "unsubscribe"
+
+// FilterTrigger is a trigger to use for a command that sets the filters of a subscription.
+const FilterTrigger = "feedfilter"
+
+// FeedsTrigger is a trigger to use for a command that lists a channel's subscriptions.
+const FeedsTrigger = "feeds"
+
+// Triggers are the triggers of the commands returned by Commands.
+var Triggers = []string{SubscribeTrigger, UnsubscribeTrigger, FilterTrigger, FeedsTrigger}
+
+// urlParameter captures the URL of a feed.
+var urlParameter = command.Parameter{Type: "string", Name: "url", Description: "URL of an RSS or Atom feed"}
+
+// Commands returns commands that let admins manage the feeds a channel is subscribed to.
+func (p *Poller) Commands() []command.Command {
+	return []command.Command{
+		{
+			Trigger:    SubscribeTrigger,
+			Parameters: []command.Parameter{urlParameter},
+			Exec:       p.subscribe,
+			Help:       "Post new entries of an RSS or Atom feed to this channel. Only usable by admins.",
+			HelpInput:  "<url>",
+		},
+		{
+			Trigger:    UnsubscribeTrigger,
+			Parameters: []command.Parameter{urlParameter},
+			Exec:       p.unsubscribe,
+			Help:       "Stop posting entries of a feed to this channel. Only usable by admins.",
+			HelpInput:  "<url>",
+		},
+		{
+			Trigger: FilterTrigger,
+			Parameters: []command.Parameter{
+				urlParameter,
+				{Type: "string", Name: "filter", Description: "Words an entry must contain, words starting with - it mustn't, or * for everything"},
+			},
+			Exec:      p.filter,
+			Help:      "Only post entries of a feed containing some words. Words starting with \"-\" exclude entries, and \"*\" removes the filter. Only usable by admins.",
+			HelpInput: "<url> <words>",
+		},
+		{
+			Trigger: FeedsTrigger,
+			Exec:    p.list,
+			Help:    "List the feeds this channel is subscribed to.",
+		},
+	}
+}
+
+// subscribe subscribes the sender's conversation to a feed, skipping the entries already in it.
+func (p *Poller) subscribe(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can subscribe to feeds."})
+	}
+
+	url := strings.TrimSpace(msg[0].(string))
+	feed, err := p.fetch(url)
+	if err != nil {
+		return sink(sender, service.Message{
+			Title:       "Error",
+			Description: fmt.Sprintf("%s couldn't be read as an RSS or Atom feed.", url),
+		})
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	guild := sender.Guild()
+	subscriptions, err := Subscriptions(*p.Storage, guild)
+	if err != nil {
+		return err
+	}
+
+	if find(subscriptions, sender, url) != -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("This channel is already subscribed to %s.", url)})
+	}
+
+	subscriptions = append(subscriptions, Subscription{
+		URL:            url,
+		ConversationID: sender.ConversationID,
+		Title:          feed.Title,
+		Seen:           guids(feed),
+	})
+	if err := SetSubscriptions(*p.Storage, guild, subscriptions); err != nil {
+		return err
+	}
+
+	return sink(sender, service.Message{
+		Title:       "Subscribed",
+		Description: fmt.Sprintf("New entries of %s will be posted here.", title(feed.Title, url)),
+		URL:         url,
+	})
+}
+
+// unsubscribe removes the sender's conversation's subscription to a feed.
+func (p *Poller) unsubscribe(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can unsubscribe from feeds."})
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	url := strings.TrimSpace(msg[0].(string))
+	guild := sender.Guild()
+	subscriptions, err := Subscriptions(*p.Storage, guild)
+	if err != nil {
+		return err
+	}
+
+	index := find(subscriptions, sender, url)
+	if index == -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("This channel isn't subscribed to %s.", url)})
+	}
+
+	subscriptions = append(subscriptions[:index], subscriptions[index+1:]...)
+	if err := SetSubscriptions(*p.Storage, guild, subscriptions); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("This channel has unsubscribed from %s.", url)})
+}
+
+// filter sets the filters of the sender's conversation's subscription to a feed.
+func (p *Poller) filter(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can filter feeds."})
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	url := strings.TrimSpace(msg[0].(string))
+	guild := sender.Guild()
+	subscriptions, err := Subscriptions(*p.Storage, guild)
+	if err != nil {
+		return err
+	}
+
+	index := find(subscriptions, sender, url)
+	if index == -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("This channel isn't subscribed to %s.", url)})
+	}
+
+	subscriptions[index].SetFilter(msg[1].(string))
+	if err := SetSubscriptions(*p.Storage, guild, subscriptions); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("Entries of %s are filtered by: %s", url, subscriptions[index].Filter())})
+}
+
+// list shows the subscriptions of the sender's conversation.
+func (p *Poller) list(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	p.mutex.Lock()
+	subscriptions, err := Subscriptions(*p.Storage, sender.Guild())
+	p.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	reply := service.Message{Title: "Feeds"}
+	for _, subscription := range subscriptions {
+		if subscription.ConversationID == sender.ConversationID {
+			reply.Fields = append(reply.Fields, service.MessageField{
+				Field: title(subscription.Title, subscription.URL),
+				Value: fmt.Sprintf("%s\nFilter: %s", subscription.URL, subscription.Filter()),
+			})
+		}
+	}
+
+	if len(reply.Fields) == 0 {
+		reply.Description = "This channel isn't subscribed to any feeds."
+	}
+	return sink(sender, reply)
+}
+
+// title returns feedTitle, or url if the feed has no title.
+func title(feedTitle string, url string) string {
+	if feedTitle == "" {
+		return url
+	}
+	return feedTitle
+}
@@ -0,0 +1,258 @@
+// Package feed posts new entries of RSS and Atom feeds to conversations that have subscribed to them.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/charset"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+// summaryLength is the most characters of an entry's summary that are posted.
+const summaryLength = 300
+
+// dateLayouts are the layouts that feeds write dates in, other than those read by command.ParseTimestamp.
+var dateLayouts = []string{
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+}
+
+// A Feed is an RSS or Atom feed.
+type Feed struct {
+	Title   string
+	Entries []Entry // Usually newest first.
+}
+
+// An Entry is an item of an RSS feed or an entry of an Atom feed.
+type Entry struct {
+	GUID       string // Identifies the entry. When a feed doesn't give one, the link or title is used.
+	Title      string
+	Link       string
+	Summary    string // Text, without HTML.
+	Author     string
+	Published  time.Time // Zero when unknown.
+	Categories []string
+}
+
+// rssDocument is an RSS 2.0 or RSS 1.0 (RDF) feed.
+type rssDocument struct {
+	Channel struct {
+		Title string    `xml:"title"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Items []rssItem `xml:"item"` // RSS 1.0 has items beside the channel.
+}
+
+// rssItem is an item of an rssDocument.
+type rssItem struct {
+	GUID        string   `xml:"guid"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	PubDate     string   `xml:"pubDate"`
+	Date        string   `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Categories  []string `xml:"category"`
+}
+
+// atomDocument is an Atom feed.
+type atomDocument struct {
+	Title   string      `xml:"title"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// atomEntry is an entry of an atomDocument.
+type atomEntry struct {
+	ID    string `xml:"id"`
+	Title string `xml:"title"`
+	Links []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Summary   string `xml:"summary"`
+	Content   string `xml:"content"`
+	Published string `xml:"published"`
+	Updated   string `xml:"updated"`
+	Author    struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
+	Categories []struct {
+		Term string `xml:"term,attr"`
+	} `xml:"category"`
+}
+
+// Parse reads an RSS or Atom feed.
+func Parse(reader io.Reader) (Feed, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	root, err := rootElement(data)
+	if err != nil {
+		return Feed{}, err
+	}
+
+	switch strings.ToLower(root) {
+	case "rss", "rdf":
+		var document rssDocument
+		if err := decode(data, &document); err != nil {
+			return Feed{}, err
+		}
+		return document.feed(), nil
+	case "feed":
+		var document atomDocument
+		if err := decode(data, &document); err != nil {
+			return Feed{}, err
+		}
+		return document.feed(), nil
+	default:
+		return Feed{}, fmt.Errorf("<%s> isn't an RSS or Atom feed", root)
+	}
+}
+
+// decode reads XML in any character set into out.
+func decode(data []byte, out interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	return decoder.Decode(out)
+}
+
+// rootElement returns the local name of the first element in data.
+func rootElement(data []byte) (string, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.CharsetReader = charset.NewReaderLabel
+	decoder.Strict = false
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("not a feed: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// feed converts an rssDocument to a Feed.
+func (r rssDocument) feed() Feed {
+	feed := Feed{Title: strings.TrimSpace(r.Channel.Title)}
+	for _, item := range append(r.Channel.Items, r.Items...) {
+		author := item.Author
+		if author == "" {
+			author = item.Creator
+		}
+
+		published := item.PubDate
+		if published == "" {
+			published = item.Date
+		}
+
+		feed.Entries = append(feed.Entries, newEntry(item.GUID, item.Title, item.Link, item.Description, author, published, item.Categories))
+	}
+	return feed
+}
+
+// feed converts an atomDocument to a Feed.
+func (a atomDocument) feed() Feed {
+	feed := Feed{Title: strings.TrimSpace(a.Title)}
+	for _, entry := range a.Entries {
+		link := ""
+		for _, candidate := range entry.Links {
+			if candidate.Rel == "" || candidate.Rel == "alternate" {
+				link = candidate.Href
+				break
+			}
+		}
+
+		summary := entry.Summary
+		if summary == "" {
+			summary = entry.Content
+		}
+
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+
+		categories := []string{}
+		for _, category := range entry.Categories {
+			categories = append(categories, category.Term)
+		}
+
+		feed.Entries = append(feed.Entries, newEntry(entry.ID, entry.Title, link, summary, entry.Author.Name, published, categories))
+	}
+	return feed
+}
+
+// newEntry makes an Entry, removing HTML from summary and choosing a GUID if there isn't one.
+func newEntry(guid string, title string, link string, summary string, author string, published string, categories []string) Entry {
+	entry := Entry{
+		GUID:       strings.TrimSpace(guid),
+		Title:      strings.TrimSpace(title),
+		Link:       strings.TrimSpace(link),
+		Summary:    plainText(summary),
+		Author:     strings.TrimSpace(author),
+		Published:  parseDate(strings.TrimSpace(published)),
+		Categories: categories,
+	}
+
+	if entry.GUID == "" {
+		entry.GUID = entry.Link
+	}
+	if entry.GUID == "" {
+		entry.GUID = entry.Title
+	}
+	return entry
+}
+
+// plainText removes HTML from text, and joins its lines.
+func plainText(text string) string {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(text))
+	if err == nil {
+		text = doc.Text()
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// parseDate reads a date written in a feed, returning the zero time if it can't be read.
+func parseDate(date string) time.Time {
+	if timestamp, err := command.ParseTimestamp(date); err == nil {
+		return timestamp
+	}
+
+	for _, layout := range dateLayouts {
+		if timestamp, err := time.Parse(layout, date); err == nil {
+			return timestamp
+		}
+	}
+	return time.Time{}
+}
+
+// Message returns a message showing the entry, which is from a feed named feedTitle.
+func (e Entry) Message(feedTitle string) service.Message {
+	summary := e.Summary
+	if runes := []rune(summary); len(runes) > summaryLength {
+		summary = strings.TrimSpace(string(runes[:summaryLength-1])) + "…"
+	}
+
+	return service.Message{
+		Title:       e.Title,
+		URL:         e.Link,
+		Description: summary,
+		Author:      service.MessageAuthor{Name: feedTitle},
+		Timestamp:   e.Published,
+	}
+}
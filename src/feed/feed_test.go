@@ -0,0 +1,149 @@
+package feed
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/google/go-cmp/cmp"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="utf-8"?>
+<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel>
+		<title>Tagalog News</title>
+		<item>
+			<guid>news-2</guid>
+			<title>Second</title>
+			<link>https://example.com/2</link>
+			<description>&lt;p&gt;A &lt;b&gt;bold&lt;/b&gt;
+			story&lt;/p&gt;</description>
+			<dc:creator>Someone</dc:creator>
+			<pubDate>Tue, 02 Jan 2024 10:00:00 +0000</pubDate>
+			<category>sport</category>
+		</item>
+		<item>
+			<title>First</title>
+			<link>https://example.com/1</link>
+			<pubDate>Mon, 1 Jan 2024 10:00:00 GMT</pubDate>
+		</item>
+	</channel>
+</rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+	<title>Word of the day</title>
+	<entry>
+		<id>tag:example.com,2024:kumusta</id>
+		<title>kumusta</title>
+		<link rel="self" href="https://example.com/self"/>
+		<link href="https://example.com/kumusta"/>
+		<content type="html">&lt;i&gt;How are you?&lt;/i&gt;</content>
+		<updated>2024-01-02T10:00:00Z</updated>
+		<author><name>Someone</name></author>
+		<category term="greeting"/>
+	</entry>
+</feed>`
+
+const rdfFeed = `<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+	<channel><title>Old News</title></channel>
+	<item>
+		<title>Story</title>
+		<link>https://example.com/story</link>
+		<dc:date>2024-01-02T10:00:00Z</dc:date>
+	</item>
+</rdf:RDF>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse(strings.NewReader(rssFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := Feed{
+		Title: "Tagalog News",
+		Entries: []Entry{
+			{
+				GUID:       "news-2",
+				Title:      "Second",
+				Link:       "https://example.com/2",
+				Summary:    "A bold story",
+				Author:     "Someone",
+				Published:  time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+				Categories: []string{"sport"},
+			},
+			{
+				GUID:      "https://example.com/1",
+				Title:     "First",
+				Link:      "https://example.com/1",
+				Published: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(expect, feed, cmp.Comparer(time.Time.Equal)); diff != "" {
+		t.Errorf("Unexpected feed: %s", diff)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse(strings.NewReader(atomFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := Feed{
+		Title: "Word of the day",
+		Entries: []Entry{{
+			GUID:       "tag:example.com,2024:kumusta",
+			Title:      "kumusta",
+			Link:       "https://example.com/kumusta",
+			Summary:    "How are you?",
+			Author:     "Someone",
+			Published:  time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC),
+			Categories: []string{"greeting"},
+		}},
+	}
+
+	if diff := cmp.Diff(expect, feed, cmp.Comparer(time.Time.Equal)); diff != "" {
+		t.Errorf("Unexpected feed: %s", diff)
+	}
+}
+
+func TestParseRDF(t *testing.T) {
+	feed, err := Parse(strings.NewReader(rdfFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feed.Title != "Old News" || len(feed.Entries) != 1 || feed.Entries[0].GUID != "https://example.com/story" || feed.Entries[0].Published.IsZero() {
+		t.Errorf("Unexpected feed %+v", feed)
+	}
+}
+
+func TestParseNotAFeed(t *testing.T) {
+	for _, text := range []string{"<html><body></body></html>", "", "not xml"} {
+		if _, err := Parse(strings.NewReader(text)); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestEntryMessage(t *testing.T) {
+	published := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	entry := Entry{Title: "Title", Link: "https://example.com", Summary: strings.Repeat("a", summaryLength+10), Published: published}
+
+	expect := service.Message{
+		Title:       "Title",
+		URL:         "https://example.com",
+		Description: strings.Repeat("a", summaryLength-1) + "…",
+		Author:      service.MessageAuthor{Name: "Feed"},
+		Timestamp:   published,
+	}
+
+	if diff := cmp.Diff(expect, entry.Message("Feed")); diff != "" {
+		t.Errorf("Unexpected message: %s", diff)
+	}
+}
@@ -0,0 +1,191 @@
+package feed
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// DefaultMaxPosts is how many entries of a feed are posted per poll when MaxPosts isn't set.
+const DefaultMaxPosts = 5
+
+// A Poller checks subscribed feeds for new entries, and posts them to the conversations
+// that subscribed to them.
+type Poller struct {
+	Storage  *storage.Storage   // Where subscriptions are kept.
+	Getter   command.HTMLGetter // Retrieves feeds.
+	MaxPosts int                // Most entries of a feed posted per poll. Newer entries are preferred, the rest are skipped. Defaults to DefaultMaxPosts.
+	router   service.Router     // Posts entries to the service of each subscribed conversation.
+	mutex    sync.Mutex         // Lock when reading and then writing subscriptions.
+}
+
+// AddSender will append a sender that entries are routed to.
+func (p *Poller) AddSender(sender service.Sender) {
+	p.router.AddSender(sender)
+}
+
+// fetch retrieves and reads the feed at url.
+func (p *Poller) fetch(url string) (Feed, error) {
+	_, reader, err := p.Getter(url)
+	if err != nil {
+		return Feed{}, err
+	}
+	defer reader.Close()
+	return Parse(reader)
+}
+
+// Run polls every interval. It returns once stop is closed.
+func (p *Poller) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			// Poll has logged any subscriptions it couldn't read or save, which are polled again next tick.
+			_ = p.Poll()
+		}
+	}
+}
+
+// Poll posts new entries of every subscribed feed. A feed that can't be retrieved
+// or posted is logged and skipped, so one broken feed doesn't stop the others.
+// An error is only returned when subscriptions can't be read or saved.
+func (p *Poller) Poll() error {
+	p.mutex.Lock()
+	guilds, err := subscribedGuilds(*p.Storage)
+	p.mutex.Unlock()
+	if err != nil {
+		slog.Error("unable to read feed subscriptions", "error", err)
+		return err
+	}
+
+	errs := []error{}
+	for _, guild := range guilds {
+		if err := p.pollGuild(guild); err != nil {
+			slog.Error("unable to poll feeds", "guild", guild.GuildID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pollGuild posts new entries of the feeds guild is subscribed to.
+func (p *Poller) pollGuild(guild service.Guild) error {
+	p.mutex.Lock()
+	subscriptions, err := Subscriptions(*p.Storage, guild)
+	p.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Feeds are retrieved without holding the lock, so commands aren't blocked by slow feeds.
+	feeds := make(map[string]Feed)
+	for _, subscription := range subscriptions {
+		if _, ok := feeds[subscription.URL]; ok {
+			continue
+		}
+
+		feed, err := p.fetch(subscription.URL)
+		if err != nil {
+			slog.Warn("unable to retrieve feed", "url", subscription.URL, "error", err)
+			continue
+		}
+		feeds[subscription.URL] = feed
+	}
+
+	// Subscriptions are read again, as they may have changed while feeds were retrieved.
+	p.mutex.Lock()
+	subscriptions, err = Subscriptions(*p.Storage, guild)
+	if err != nil {
+		p.mutex.Unlock()
+		return err
+	}
+
+	type post struct {
+		conversation service.Conversation
+		msg          service.Message
+	}
+	posts := []post{}
+
+	for i, subscription := range subscriptions {
+		feed, ok := feeds[subscription.URL]
+		if !ok {
+			continue
+		}
+
+		conversation := service.Conversation{
+			ServiceID:      guild.ServiceID,
+			ConversationID: subscription.ConversationID,
+			GuildID:        guild.GuildID,
+		}
+
+		// Entries are posted oldest first, as feeds list the newest first.
+		entries := p.newEntries(subscription, feed)
+		for j := len(entries) - 1; j >= 0; j-- {
+			posts = append(posts, post{conversation, entries[j].Message(feed.Title)})
+		}
+		subscriptions[i].Seen = guids(feed)
+	}
+
+	err = SetSubscriptions(*p.Storage, guild, subscriptions)
+	p.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	// Entries are saved as seen before being posted, so an entry is never posted twice.
+	for _, post := range posts {
+		if err := p.router.Route(post.conversation, post.msg); err != nil {
+			slog.Warn("unable to post feed entry", "conversation", post.conversation.ConversationID, "url", post.msg.URL, "error", err)
+		}
+	}
+	return nil
+}
+
+// newEntries returns the entries of feed that subscription hasn't seen and that pass its
+// filters, limited to the newest MaxPosts.
+func (p *Poller) newEntries(subscription Subscription, feed Feed) []Entry {
+	maxPosts := p.MaxPosts
+	if maxPosts <= 0 {
+		maxPosts = DefaultMaxPosts
+	}
+
+	entries := []Entry{}
+	for _, entry := range feed.Entries {
+		if len(entries) == maxPosts {
+			break
+		}
+
+		if !subscription.isSeen(entry.GUID) && subscription.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// guids returns the GUID of each entry in feed.
+func guids(feed Feed) []string {
+	seen := make([]string, 0, len(feed.Entries))
+	for _, entry := range feed.Entries {
+		seen = append(seen, entry.GUID)
+	}
+	return seen
+}
+
+// find returns the index of the subscription of conversation to url, or -1 if there isn't one.
+func find(subscriptions []Subscription, conversation service.Conversation, url string) int {
+	for i, subscription := range subscriptions {
+		if subscription.URL == url && subscription.ConversationID == conversation.ConversationID {
+			return i
+		}
+	}
+	return -1
+}
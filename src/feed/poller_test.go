@@ -0,0 +1,166 @@
+package feed
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+)
+
+// rssWithItems returns an RSS feed of items with titles, newest first.
+func rssWithItems(titles ...string) string {
+	items := ""
+	for _, title := range titles {
+		items += fmt.Sprintf("<item><guid>%s</guid><title>%s</title></item>", title, title)
+	}
+	return fmt.Sprintf(`<rss version="2.0"><channel><title>Test</title>%s</channel></rss>`, items)
+}
+
+// testPoller returns a Poller whose feeds are read from feeds, sending to a DemoSender.
+func testPoller(feeds map[string]string) (*Poller, *demoservice.DemoSender) {
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+
+	poller := Poller{
+		Storage: commandtest.Storage(),
+		Getter: func(url string) (string, io.ReadCloser, error) {
+			content, ok := feeds[url]
+			if !ok {
+				return "", nil, fmt.Errorf("%s not found", url)
+			}
+			return url, io.NopCloser(strings.NewReader(content)), nil
+		},
+		MaxPosts: 2,
+	}
+	poller.AddSender(&demoSender)
+	return &poller, &demoSender
+}
+
+// popTitles returns the titles of every message sent by demoSender.
+func popTitles(demoSender *demoservice.DemoSender) []string {
+	titles := []string{}
+	for !demoSender.IsEmpty() {
+		msg, _ := demoSender.PopMessage()
+		titles = append(titles, msg.Title)
+	}
+	return titles
+}
+
+func TestPoll(t *testing.T) {
+	url := "https://example.com/feed"
+	feeds := map[string]string{url: rssWithItems("one")}
+	poller, demoSender := testPoller(feeds)
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, conversation, commandtest.User, SubscribeTrigger, url); reply.Title != "Subscribed" {
+		t.Fatalf("Expected to subscribe, got %+v", reply)
+	}
+
+	// Entries in the feed when subscribing aren't posted.
+	if err := poller.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if titles := popTitles(demoSender); len(titles) != 0 {
+		t.Errorf("Expected nothing to be posted, got %v", titles)
+	}
+
+	// Only the newest entries are posted, oldest first.
+	feeds[url] = rssWithItems("four", "three", "two", "one")
+	if err := poller.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if titles := strings.Join(popTitles(demoSender), ","); titles != "three,four" {
+		t.Errorf("Expected three,four to be posted, got %s", titles)
+	}
+
+	// Entries are posted once.
+	if err := poller.Poll(); err != nil {
+		t.Fatal(err)
+	}
+	if titles := popTitles(demoSender); len(titles) != 0 {
+		t.Errorf("Expected nothing to be posted, got %v", titles)
+	}
+}
+
+func TestPollFilter(t *testing.T) {
+	url := "https://example.com/feed"
+	feeds := map[string]string{url: rssWithItems()}
+	poller, demoSender := testPoller(feeds)
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	commandtest.Reply(t, poller.Commands(), poller.Storage, conversation, commandtest.User, SubscribeTrigger, url)
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, conversation, commandtest.User, FilterTrigger, url, "kumusta -bye"); !strings.Contains(reply.Description, "kumusta -bye") {
+		t.Errorf("Expected the filter to be set, got %+v", reply)
+	}
+
+	feeds[url] = rssWithItems("kumusta bye", "kumusta", "salamat")
+	if err := poller.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, sentTo := demoSender.PopMessage()
+	if msg.Title != "kumusta" || sentTo.ConversationID != "0" || sentTo.GuildID != "0" || !demoSender.IsEmpty() {
+		t.Errorf("Expected only kumusta to be posted, got %+v to %+v", msg, sentTo)
+	}
+}
+
+func TestPollBrokenFeed(t *testing.T) {
+	working := "https://example.com/feed"
+	feeds := map[string]string{working: rssWithItems(), "https://example.com/broken": rssWithItems()}
+	poller, demoSender := testPoller(feeds)
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	commandtest.Reply(t, poller.Commands(), poller.Storage, conversation, commandtest.User, SubscribeTrigger, "https://example.com/broken")
+	commandtest.Reply(t, poller.Commands(), poller.Storage, conversation, commandtest.User, SubscribeTrigger, working)
+
+	feeds["https://example.com/broken"] = "<html></html>"
+	feeds[working] = rssWithItems("one")
+	if err := poller.Poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if titles := popTitles(demoSender); len(titles) != 1 || titles[0] != "one" {
+		t.Errorf("Expected the working feed to be posted, got %v", titles)
+	}
+}
+
+func TestFeedCommands(t *testing.T) {
+	url := "https://example.com/feed"
+	poller, _ := testPoller(map[string]string{url: rssWithItems()})
+	admin := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+	user := admin
+	user.Admin = false
+
+	for _, trigger := range []string{SubscribeTrigger, UnsubscribeTrigger, FilterTrigger} {
+		if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, user, commandtest.User, trigger, url, "word"); !strings.Contains(reply.Description, "Only admins") {
+			t.Errorf("Expected %s to require an admin, got %+v", trigger, reply)
+		}
+	}
+
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, admin, commandtest.User, SubscribeTrigger, "https://example.com/missing"); reply.Title != "Error" {
+		t.Errorf("Expected a missing feed to be reported, got %+v", reply)
+	}
+
+	commandtest.Reply(t, poller.Commands(), poller.Storage, admin, commandtest.User, SubscribeTrigger, url)
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, admin, commandtest.User, SubscribeTrigger, url); !strings.Contains(reply.Description, "already") {
+		t.Errorf("Expected a second subscription to be refused, got %+v", reply)
+	}
+
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, user, commandtest.User, FeedsTrigger); len(reply.Fields) != 1 || reply.Fields[0].Field != "Test" {
+		t.Errorf("Expected the subscription to be listed, got %+v", reply)
+	}
+
+	other := admin
+	other.ConversationID = "1"
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, other, commandtest.User, FeedsTrigger); len(reply.Fields) != 0 {
+		t.Errorf("Expected subscriptions of other channels not to be listed, got %+v", reply)
+	}
+
+	commandtest.Reply(t, poller.Commands(), poller.Storage, admin, commandtest.User, UnsubscribeTrigger, url)
+	if reply := commandtest.Reply(t, poller.Commands(), poller.Storage, admin, commandtest.User, UnsubscribeTrigger, url); !strings.Contains(reply.Description, "isn't subscribed") {
+		t.Errorf("Expected a missing subscription to be reported, got %+v", reply)
+	}
+}
@@ -0,0 +1,137 @@
+package feed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// SubscriptionsKey is the guild storage key of a guild's subscriptions, stored as JSON.
+const SubscriptionsKey = "feeds"
+
+// GuildsKey is the global storage key of the guilds that have subscriptions, stored as JSON.
+// Storage can't list guilds, so this is how feeds are found when polling.
+const GuildsKey = "feed_guilds"
+
+// A Subscription posts new entries of a feed to a conversation.
+type Subscription struct {
+	URL            string
+	ConversationID string
+	Title          string   // Title of the feed when it was subscribed to.
+	Include        []string // When set, only entries containing one of these words are posted.
+	Exclude        []string // Entries containing any of these words aren't posted.
+	Seen           []string // GUIDs of entries that are in the feed and have been seen, so they aren't posted again.
+}
+
+// Matches returns true if entry passes the subscription's filters. Words are matched
+// case-insensitively against the title, summary and categories.
+func (s Subscription) Matches(entry Entry) bool {
+	text := strings.ToLower(entry.Title + " " + entry.Summary + " " + strings.Join(entry.Categories, " "))
+	for _, word := range s.Exclude {
+		if strings.Contains(text, strings.ToLower(word)) {
+			return false
+		}
+	}
+
+	if len(s.Include) == 0 {
+		return true
+	}
+
+	for _, word := range s.Include {
+		if strings.Contains(text, strings.ToLower(word)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter describes the subscription's filters, as written for the filter command.
+func (s Subscription) Filter() string {
+	words := append([]string{}, s.Include...)
+	for _, word := range s.Exclude {
+		words = append(words, "-"+word)
+	}
+
+	if len(words) == 0 {
+		return "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// SetFilter sets Include and Exclude from words separated by spaces, where words starting with
+// "-" are excluded. "*" removes the filters.
+func (s *Subscription) SetFilter(filter string) {
+	s.Include, s.Exclude = nil, nil
+	for _, word := range strings.Fields(filter) {
+		if word == "*" {
+			continue
+		}
+
+		if excluded, ok := strings.CutPrefix(word, "-"); ok {
+			if excluded != "" {
+				s.Exclude = append(s.Exclude, excluded)
+			}
+		} else {
+			s.Include = append(s.Include, word)
+		}
+	}
+}
+
+// isSeen returns true if the entry with guid has been seen.
+func (s Subscription) isSeen(guid string) bool {
+	for _, seen := range s.Seen {
+		if seen == guid {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscriptions returns the subscriptions of guild.
+func Subscriptions(store storage.Storage, guild service.Guild) ([]Subscription, error) {
+	subscriptions := []Subscription{}
+	if err := storage.GetGuildJSON(store, guild, SubscriptionsKey, &subscriptions); err != nil {
+		return nil, fmt.Errorf("subscriptions of %s: %w", guild.GuildID, err)
+	}
+	return subscriptions, nil
+}
+
+// SetSubscriptions replaces the subscriptions of guild, remembering which guilds have any.
+func SetSubscriptions(store storage.Storage, guild service.Guild, subscriptions []Subscription) error {
+	if err := storage.SetGuildJSON(store, guild, SubscriptionsKey, subscriptions); err != nil {
+		return err
+	}
+
+	guilds, err := subscribedGuilds(store)
+	if err != nil {
+		return err
+	}
+
+	index := -1
+	for i, subscribed := range guilds {
+		if subscribed == guild {
+			index = i
+		}
+	}
+
+	if index == -1 && len(subscriptions) > 0 {
+		guilds = append(guilds, guild)
+	} else if index != -1 && len(subscriptions) == 0 {
+		guilds = append(guilds[:index], guilds[index+1:]...)
+	} else {
+		return nil
+	}
+
+	return storage.SetGlobalJSON(store, GuildsKey, guilds)
+}
+
+// subscribedGuilds returns the guilds that have subscriptions.
+func subscribedGuilds(store storage.Storage) ([]service.Guild, error) {
+	guilds := []service.Guild{}
+	if err := storage.GetGlobalJSON(store, GuildsKey, &guilds); err != nil {
+		return nil, fmt.Errorf("subscribed guilds: %w", err)
+	}
+	return guilds, nil
+}
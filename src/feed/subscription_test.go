@@ -0,0 +1,81 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSubscriptionFilter(t *testing.T) {
+	entry := Entry{Title: "Basketball results", Summary: "The final score", Categories: []string{"Sport"}}
+
+	cases := []struct {
+		filter  string
+		matches bool
+		written string
+	}{
+		{"", true, "*"},
+		{"*", true, "*"},
+		{"sport", true, "sport"},
+		{"politics", false, "politics"},
+		{"politics SCORE", true, "politics SCORE"},
+		{"-basketball", false, "-basketball"},
+		{"sport -final", false, "sport -final"},
+		{"- -", true, "*"},
+	}
+
+	for _, c := range cases {
+		subscription := Subscription{}
+		subscription.SetFilter(c.filter)
+		if subscription.Matches(entry) != c.matches {
+			t.Errorf("Expected filter %q to match: %t", c.filter, c.matches)
+		}
+
+		if subscription.Filter() != c.written {
+			t.Errorf("Expected filter %q to be written as %q, got %q", c.filter, c.written, subscription.Filter())
+		}
+	}
+}
+
+func TestSetSubscriptions(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+	guilds := []service.Guild{{ServiceID: "demo", GuildID: "1"}, {ServiceID: "demo", GuildID: "2"}}
+	subscriptions := []Subscription{{URL: "https://example.com/feed", ConversationID: "0", Include: []string{"word"}}}
+
+	for _, guild := range guilds {
+		if err := SetSubscriptions(store, guild, subscriptions); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stored, err := Subscriptions(store, guilds[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(subscriptions, stored); diff != "" {
+		t.Errorf("Unexpected subscriptions: %s", diff)
+	}
+
+	if err := SetSubscriptions(store, guilds[0], nil); err != nil {
+		t.Fatal(err)
+	}
+
+	subscribed, err := subscribedGuilds(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(guilds[1:], subscribed); diff != "" {
+		t.Errorf("Unexpected guilds: %s", diff)
+	}
+}
+
+func TestSubscriptionsEmpty(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	subscriptions, err := Subscriptions(&tempStorage, service.Guild{ServiceID: "demo", GuildID: "1"})
+	if err != nil || len(subscriptions) != 0 {
+		t.Errorf("Expected no subscriptions, got %v, %v", subscriptions, err)
+	}
+}
@@ -0,0 +1,157 @@
+// Package fixture records responses from websites and APIs, so that commands can
+// later be tested offline by replaying them.
+package fixture
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"unicode/utf8"
+)
+
+// Mode is how a Store handles requests.
+type Mode string
+
+// Modes a Store can have.
+const (
+	Live   Mode = ""       // Make requests, without saving responses.
+	Record Mode = "record" // Make requests, saving each response as a fixture.
+	Replay Mode = "replay" // Make no requests, using saved fixtures instead.
+)
+
+// A Fixture is a saved response to a request.
+type Fixture struct {
+	URL        string // The requested URL.
+	Status     int    // HTTP status code of the response. An unsuccessful status isn't an error, so its body is used as the bot would use it.
+	Redirect   string // URL of the response, which differs from URL if there were redirects.
+	Body       string // The response's body, if it is valid UTF-8.
+	BodyBase64 string `json:",omitempty"` // The response's body encoded as base64, if it isn't valid UTF-8.
+}
+
+// Content returns the response's body.
+func (f Fixture) Content() ([]byte, error) {
+	if f.BodyBase64 != "" {
+		return base64.StdEncoding.DecodeString(f.BodyBase64)
+	}
+	return []byte(f.Body), nil
+}
+
+// A Store saves fixtures to a directory, with a file for each URL.
+type Store struct {
+	Dir    string       // Where fixtures are saved.
+	Mode   Mode         // Whether requests are made, recorded or replayed.
+	Client *http.Client // Makes requests when not replaying. When nil, http.DefaultClient is used.
+}
+
+// ParseMode converts the name of a mode to a Mode.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(name); mode {
+	case Live, Record, Replay:
+		return mode, nil
+	}
+	return Live, fmt.Errorf("unknown fixture mode %q, expected %q or %q", name, Record, Replay)
+}
+
+// Filepath returns where the fixture for url is saved.
+func (s Store) Filepath(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return path.Join(s.Dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load reads the fixture saved for url.
+func (s Store) Load(url string) (Fixture, error) {
+	var fixture Fixture
+	bytes, err := os.ReadFile(s.Filepath(url))
+	if os.IsNotExist(err) {
+		return fixture, fmt.Errorf("no fixture for %s, it can be made in %s mode", url, Record)
+	}
+
+	if err != nil {
+		return fixture, err
+	}
+
+	return fixture, json.Unmarshal(bytes, &fixture)
+}
+
+// Save writes fixture to the directory, replacing any fixture with the same URL.
+func (s Store) Save(fixture Fixture) error {
+	if err := os.MkdirAll(s.Dir, 0755); err != nil {
+		return err
+	}
+
+	bytes, err := json.MarshalIndent(fixture, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.Filepath(fixture.URL), bytes, 0644)
+}
+
+// Get returns the response to a GET request to url, which depending on the mode
+// is either requested, requested and saved, or loaded from a saved fixture.
+func (s Store) Get(url string) (Fixture, error) {
+	if s.Mode == Replay {
+		return s.Load(url)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return Fixture{}, err
+	}
+	defer resp.Body.Close()
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Fixture{}, err
+	}
+
+	fixture := Fixture{
+		URL:      url,
+		Status:   resp.StatusCode,
+		Redirect: resp.Request.URL.String(),
+	}
+	if utf8.Valid(content) {
+		fixture.Body = string(content)
+	} else {
+		fixture.BodyBase64 = base64.StdEncoding.EncodeToString(content)
+	}
+
+	if s.Mode == Record {
+		if err := s.Save(fixture); err != nil {
+			return fixture, err
+		}
+	}
+	return fixture, nil
+}
+
+// HTMLGetter can be used as a command.HTMLGetter.
+func (s Store) HTMLGetter(url string) (redirect string, out io.ReadCloser, err error) {
+	fixture, err := s.Get(url)
+	if err != nil {
+		return "", nil, err
+	}
+
+	content, err := fixture.Content()
+	if err != nil {
+		return "", nil, err
+	}
+	return fixture.Redirect, io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// JSONGetter can be used as a command.JSONGetter.
+func (s Store) JSONGetter(url string) (out io.ReadCloser, err error) {
+	_, out, err = s.HTMLGetter(url)
+	return out, err
+}
@@ -0,0 +1,151 @@
+package fixture
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newServer returns a server with a page at /page, which /redirect redirects to.
+// requests counts how many requests were made.
+func newServer(t *testing.T, requests *int) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		fmt.Fprint(w, "<p>page</p>")
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/binary", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte{0xff, 0xfe, 0x00})
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func read(t *testing.T, reader io.ReadCloser) string {
+	defer reader.Close()
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(bytes)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	dir := t.TempDir()
+
+	recorder := Store{Dir: dir, Mode: Record}
+	redirect, out, err := recorder.HTMLGetter(server.URL + "/redirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body := read(t, out); body != "<p>page</p>" {
+		t.Errorf("Unexpected body %q", body)
+	}
+
+	server.Close()
+	replayer := Store{Dir: dir, Mode: Replay}
+	replayedRedirect, out, err := replayer.HTMLGetter(server.URL + "/redirect")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if body := read(t, out); body != "<p>page</p>" {
+		t.Errorf("Unexpected replayed body %q", body)
+	}
+
+	if replayedRedirect != redirect || redirect != server.URL+"/page" {
+		t.Errorf("Expected redirect %s, got %s", redirect, replayedRedirect)
+	}
+
+	if requests != 1 {
+		t.Errorf("Expected 1 request, got %d", requests)
+	}
+}
+
+func TestReplayMissing(t *testing.T) {
+	replayer := Store{Dir: t.TempDir(), Mode: Replay}
+	if _, err := replayer.JSONGetter("https://example.com"); err == nil {
+		t.Errorf("Expected an error when there is no fixture")
+	}
+}
+
+func TestLiveDoesNotSave(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	dir := t.TempDir()
+
+	live := Store{Dir: dir}
+	if _, err := live.Get(server.URL + "/page"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (Store{Dir: dir}).Load(server.URL + "/page"); err == nil {
+		t.Errorf("Expected no fixture to be saved")
+	}
+}
+
+func TestRecordStatusAndBinary(t *testing.T) {
+	requests := 0
+	server := newServer(t, &requests)
+	store := Store{Dir: t.TempDir(), Mode: Record}
+
+	if _, err := store.Get(server.URL + "/binary"); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err := store.Load(server.URL + "/binary")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := fixture.Content()
+	if err != nil || string(content) != string([]byte{0xff, 0xfe, 0x00}) {
+		t.Errorf("Expected the binary body to be kept, got %v %s", content, err)
+	}
+
+	if _, err := store.Get(server.URL + "/missing"); err != nil {
+		t.Fatal(err)
+	}
+
+	fixture, err = store.Load(server.URL + "/missing")
+	if err != nil || fixture.Status != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d %s", fixture.Status, err)
+	}
+
+	// As with requests made by the bot, the body of an unsuccessful response is still returned.
+	server.Close()
+	replayer := Store{Dir: store.Dir, Mode: Replay}
+	_, reader, err := replayer.HTMLGetter(server.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if body, _ := io.ReadAll(reader); !strings.Contains(string(body), "not found") {
+		t.Errorf("Expected the body of the 404 to be replayed, got %q", body)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for _, name := range []string{"", "record", "replay"} {
+		if mode, err := ParseMode(name); err != nil || string(mode) != name {
+			t.Errorf("Unexpected result for %q: %s %s", name, mode, err)
+		}
+	}
+
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Errorf("Expected an error for an unknown mode")
+	}
+}
@@ -0,0 +1,147 @@
+// Package flashcard lets users keep a personal deck of vocabulary cards, and review them using an SM-2 schedule.
+package flashcard
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// DeckKey is the user storage key of a user's cards, stored as JSON.
+const DeckKey = "flashcards"
+
+// MaxCards is how many cards a deck can have.
+const MaxCards = 1000
+
+// MaxBackLength is how many characters the back of a card can have.
+const MaxBackLength = 1000
+
+// DefaultEase is the ease of a new card, which is how much its interval grows after each correct review.
+const DefaultEase = 2.5
+
+// MinEase is the lowest ease of a card, so that cards that are often forgotten are still spaced out.
+const MinEase = 1.3
+
+// A Card has a word on its front, and what the word means on its back.
+type Card struct {
+	Front       string
+	Back        string
+	Added       time.Time
+	Due         time.Time // When the card should next be reviewed.
+	Interval    int       // Days between the last review and Due.
+	Ease        float64   // How much Interval grows after a correct review.
+	Repetitions int       // How many times in a row the card has been remembered.
+}
+
+// NewCard returns a card that is due at now.
+func NewCard(front string, back string, now time.Time) Card {
+	return Card{
+		Front: strings.TrimSpace(front),
+		Back:  truncate(strings.TrimSpace(back), MaxBackLength),
+		Added: now,
+		Due:   now,
+		Ease:  DefaultEase,
+	}
+}
+
+// Review returns the card after it was reviewed at now, where quality rates how well it was
+// remembered from 0 (not at all) to 5 (perfectly), as in SM-2. Cards rated below 3 are due
+// again at now, and their interval starts again.
+func (c Card) Review(quality int, now time.Time) Card {
+	if quality < 3 {
+		c.Repetitions = 0
+		c.Interval = 0
+		c.Due = now
+		return c
+	}
+
+	switch c.Repetitions {
+	case 0:
+		c.Interval = 1
+	case 1:
+		c.Interval = 6
+	default:
+		c.Interval = int(math.Round(float64(c.Interval) * c.Ease))
+	}
+	c.Repetitions++
+
+	missed := float64(5 - quality)
+	c.Ease = max(MinEase, c.Ease+0.1-missed*(0.08+missed*0.02))
+	c.Due = now.AddDate(0, 0, c.Interval)
+	return c
+}
+
+// find returns the index of the card in cards with front, ignoring case, or -1 if there isn't one.
+func find(cards []Card, front string) int {
+	return slices.IndexFunc(cards, func(card Card) bool { return strings.EqualFold(card.Front, front) })
+}
+
+// nextDue returns the index of the card in cards that has been due the longest at now,
+// or -1 if no card is due.
+func nextDue(cards []Card, now time.Time) int {
+	next := -1
+	for i, card := range cards {
+		if !card.Due.After(now) && (next == -1 || card.Due.Before(cards[next].Due)) {
+			next = i
+		}
+	}
+	return next
+}
+
+// countDue returns how many cards in cards are due at now.
+func countDue(cards []Card, now time.Time) int {
+	count := 0
+	for _, card := range cards {
+		if !card.Due.After(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// merge adds imported to cards. A card that has the same front as one in cards replaces it,
+// keeping the schedule of the card in cards unless the imported card has been reviewed.
+func merge(cards []Card, imported []Card) []Card {
+	for _, card := range imported {
+		index := find(cards, card.Front)
+		if index == -1 {
+			cards = append(cards, card)
+			continue
+		}
+
+		if card.Repetitions == 0 && card.Interval == 0 {
+			card.Due, card.Interval, card.Ease = cards[index].Due, cards[index].Interval, cards[index].Ease
+			card.Repetitions, card.Added = cards[index].Repetitions, cards[index].Added
+		}
+		cards[index] = card
+	}
+	return cards
+}
+
+// truncate shortens text to at most limit characters.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// Deck returns the cards of user.
+func Deck(store storage.Storage, user service.User) ([]Card, error) {
+	cards := []Card{}
+	if err := storage.GetUserJSON(store, user, DeckKey, &cards); err != nil {
+		return nil, fmt.Errorf("deck of %s: %w", user.Name, err)
+	}
+	return cards, nil
+}
+
+// SetDeck replaces the cards of user.
+func SetDeck(store storage.Storage, user service.User, cards []Card) error {
+	return storage.SetUserJSON(store, user, DeckKey, cards)
+}
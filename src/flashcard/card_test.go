@@ -0,0 +1,73 @@
+package flashcard
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReview(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	card := NewCard("kumusta", "hello", now)
+
+	// Intervals follow SM-2: 1 day, 6 days, then the last interval multiplied by the ease.
+	for _, expected := range []int{1, 6, 15, 38} {
+		card = card.Review(4, now)
+		if card.Interval != expected || !card.Due.Equal(now.AddDate(0, 0, expected)) {
+			t.Fatalf("Expected an interval of %d, got %d due %s", expected, card.Interval, card.Due)
+		}
+	}
+
+	if card.Ease != DefaultEase || card.Repetitions != 4 {
+		t.Errorf("Expected a rating of 4 to keep the ease, got %+v", card)
+	}
+
+	card = card.Review(5, now)
+	if math.Abs(card.Ease-2.6) > 1e-9 {
+		t.Errorf("Expected a perfect rating to raise the ease to 2.6, got %g", card.Ease)
+	}
+
+	card = card.Review(1, now)
+	if card.Repetitions != 0 || card.Interval != 0 || !card.Due.Equal(now) || math.Abs(card.Ease-2.6) > 1e-9 {
+		t.Errorf("Expected a forgotten card to start again and be due now, got %+v", card)
+	}
+
+	for i := 0; i < 10; i++ {
+		card = card.Review(3, now)
+	}
+	if card.Ease != MinEase {
+		t.Errorf("Expected the ease not to fall below %g, got %g", MinEase, card.Ease)
+	}
+}
+
+func TestNextDue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cards := []Card{
+		NewCard("later", "b", now.Add(time.Hour)),
+		NewCard("due", "b", now.Add(-time.Hour)),
+		NewCard("overdue", "b", now.Add(-2*time.Hour)),
+	}
+
+	if index := nextDue(cards, now); index != 2 {
+		t.Errorf("Expected the most overdue card to be next, got %d", index)
+	}
+	if count := countDue(cards, now); count != 2 {
+		t.Errorf("Expected 2 cards to be due, got %d", count)
+	}
+	if index := nextDue(cards, now.Add(-3*time.Hour)); index != -1 {
+		t.Errorf("Expected no card to be due, got %d", index)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	reviewed := NewCard("kumusta", "hi", now).Review(5, now)
+	cards := merge([]Card{reviewed}, []Card{NewCard("Kumusta", "hello", now), NewCard("salamat", "thanks", now)})
+
+	if len(cards) != 2 {
+		t.Fatalf("Expected cards with the same front to be replaced, got %+v", cards)
+	}
+	if cards[0].Back != "hello" || !cards[0].Due.Equal(reviewed.Due) || cards[0].Repetitions != 1 {
+		t.Errorf("Expected the back to be replaced and the schedule to be kept, got %+v", cards[0])
+	}
+}
@@ -0,0 +1,518 @@
+package flashcard
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/session"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// ReviewKey is the user storage key of the front of the card a user is reviewing.
+// A review continues across messages, so it can happen in a channel or a direct message.
+const ReviewKey = "flashcard_review"
+
+// SaveTrigger is a trigger to use for a command that saves a card.
+const SaveTrigger = "savecard"
+
+// DeleteTrigger is a trigger to use for a command that deletes a card.
+const DeleteTrigger = "deletecard"
+
+// DeckTrigger is a trigger to use for a command that lists a user's cards.
+const DeckTrigger = "deck"
+
+// ReviewTrigger is a trigger to use for a command that shows the next card that is due.
+const ReviewTrigger = "review"
+
+// RevealTrigger is a trigger to use for a command that shows the back of the card being reviewed.
+const RevealTrigger = "reveal"
+
+// RateTrigger is a trigger to use for a command that rates how well the card being reviewed was remembered.
+const RateTrigger = "rate"
+
+// ExportTrigger is a trigger to use for a command that sends a user's cards as CSV.
+const ExportTrigger = "exportdeck"
+
+// ImportTrigger is a trigger to use for a command that adds cards from CSV.
+const ImportTrigger = "importdeck"
+
+// Triggers are the triggers of the commands returned by Commands.
+var Triggers = []string{SaveTrigger, DeleteTrigger, DeckTrigger, ReviewTrigger, RevealTrigger, RateTrigger, ExportTrigger, ImportTrigger}
+
+// cardsPerPage is how many cards are listed on each page of a deck.
+const cardsPerPage = 10
+
+// maxImportBytes is the largest CSV that's read from a url, which is enough for a deck of cards with the longest backs.
+const maxImportBytes = MaxCards * MaxBackLength
+
+// Steps of a review session, which are after the front or the back of a card is shown.
+const (
+	stepFront = "front"
+	stepBack  = "back"
+)
+
+// showAnswer is the choice that shows the back of a card.
+const showAnswer = "Show answer"
+
+// ratings are the choices that rate a card.
+var ratings = []string{"0", "1", "2", "3", "4", "5"}
+
+// Decks has the commands that let users keep and review a deck of cards.
+type Decks struct {
+	Lookup   string             // Trigger of a command, such as a dictionary, whose reply is the back of a card saved without one.
+	Getter   command.HTMLGetter // Retrieves CSV that is imported from a url.
+	commands []command.Command
+	mutex    sync.Mutex // Lock when reading and then writing a deck, or when using commands.
+}
+
+// SetCommands replaces the commands that the Lookup command is found in when a card is saved
+// without a back. Until it's called, users are asked to write the back themselves.
+func (d *Decks) SetCommands(commands []command.Command) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.commands = commands
+}
+
+// Commands returns commands that let users save, review, list, export and import their cards.
+func (d *Decks) Commands() []command.Command {
+	return []command.Command{
+		{
+			Trigger: SaveTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "card", Description: "A word, then optionally | and what it means"},
+			},
+			Exec:      d.save,
+			Help:      "Save a word to your deck of flashcards. Without a meaning, the word is looked up.",
+			HelpInput: "<word> [| <meaning>]",
+		},
+		{
+			Trigger: DeleteTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "word", Description: "Front of the card to delete"},
+			},
+			Exec:      d.delete,
+			Help:      "Delete a card from your deck.",
+			HelpInput: "<word>",
+		},
+		{
+			Trigger: DeckTrigger,
+			Exec:    d.list,
+			Help:    "List the cards in your deck, and when they're due.",
+		},
+		{
+			Trigger:  ReviewTrigger,
+			Exec:     d.review,
+			Continue: d.continueReview,
+			Help:     "Start reviewing the cards in your deck that are due. Reply to a card to see its back, then reply with a rating.",
+		},
+		{
+			Trigger: RevealTrigger,
+			Exec:    d.reveal,
+			Help:    "Show the back of the card you're reviewing.",
+		},
+		{
+			Trigger: RateTrigger,
+			Parameters: []command.Parameter{
+				{Type: "int", Name: "quality", Description: "From 0 (forgotten) to 5 (perfect)"},
+			},
+			Exec:      d.rate,
+			Help:      "Rate how well you remembered the card you're reviewing, from 0 (forgotten) to 5 (perfect), and show the next card. Cards rated below 3 are shown again.",
+			HelpInput: "<0-5>",
+		},
+		{
+			Trigger: ExportTrigger,
+			Exec:    d.export,
+			Help:    "Get your deck as a CSV file.",
+		},
+		{
+			Trigger: ImportTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "csv", Description: "Rows of a word and its meaning, or a url of a CSV file"},
+			},
+			Exec:      d.importCSV,
+			Help:      "Add cards to your deck from CSV, such as a file from " + ExportTrigger + ". Each row has a word, then its meaning.",
+			HelpInput: "<csv or url>",
+		},
+	}
+}
+
+// save adds a card to the deck of user, replacing any card with the same front.
+func (d *Decks) save(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	front, back, _ := strings.Cut(msg[0].(string), "|")
+	front, back = strings.TrimSpace(front), strings.TrimSpace(back)
+	if front == "" {
+		return sink(sender, service.Message{Title: "Error", Description: "Write the word to save."})
+	}
+
+	if back == "" {
+		var err error
+		if back, err = d.lookup(sender, user, front, storage); err != nil {
+			return sink(sender, service.Message{Title: "Error", Description: err.Error()})
+		}
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cards, err := Deck(*storage, user)
+	if err != nil {
+		return err
+	}
+
+	card := NewCard(front, back, time.Now())
+	if index := find(cards, front); index != -1 {
+		cards = merge(cards, []Card{card})
+	} else if len(cards) >= MaxCards {
+		return sink(sender, service.Message{Description: fmt.Sprintf("Your deck can't have more than %d cards.", MaxCards)})
+	} else {
+		cards = append(cards, card)
+	}
+
+	if err := SetDeck(*storage, user, cards); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Title: fmt.Sprintf("Saved %s", card.Front), Description: card.Back})
+}
+
+// lookup runs the Lookup command with word, returning the text of its first reply.
+func (d *Decks) lookup(sender service.Conversation, user service.User, word string, storage *storage.Storage) (string, error) {
+	d.mutex.Lock()
+	commands := d.commands
+	d.mutex.Unlock()
+
+	for _, cmd := range commands {
+		if d.Lookup == "" || cmd.Trigger != d.Lookup {
+			continue
+		}
+
+		types := make([]string, 0, len(cmd.Parameters))
+		for _, parameter := range cmd.Parameters {
+			types = append(types, parameter.Type)
+		}
+
+		input, err := service.ParseInput(service.ParserBasic(), strings.Fields(word), types)
+		if err != nil {
+			return "", fmt.Errorf("%s can't be looked up", word)
+		}
+
+		replies := []service.Message{}
+		collect := func(_ service.Conversation, reply service.Message) error {
+			replies = append(replies, reply)
+			return nil
+		}
+		if err := cmd.Exec(sender, user, input, storage, collect); err != nil {
+			return "", err
+		}
+
+		if len(replies) == 0 || replies[0].Title == "Error" || messageText(replies[0]) == "" {
+			return "", fmt.Errorf("%s couldn't be looked up, write what it means after |", word)
+		}
+		return messageText(replies[0]), nil
+	}
+	return "", fmt.Errorf("write what %s means after |", word)
+}
+
+// messageText returns the description of msg, or otherwise its fields.
+func messageText(msg service.Message) string {
+	if len(msg.Pages) > 0 {
+		msg = msg.Pages[0]
+	}
+
+	if text := strings.TrimSpace(msg.Description); text != "" {
+		return text
+	}
+
+	lines := []string{}
+	for _, field := range msg.Fields {
+		lines = append(lines, strings.TrimSpace(field.Field+": "+field.Value))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// delete removes a card from the deck of user.
+func (d *Decks) delete(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	front := strings.TrimSpace(msg[0].(string))
+	cards, err := Deck(*storage, user)
+	if err != nil {
+		return err
+	}
+
+	index := find(cards, front)
+	if index == -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("There's no card %s in your deck.", front)})
+	}
+
+	if err := SetDeck(*storage, user, append(cards[:index], cards[index+1:]...)); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("%s has been deleted from your deck.", front)})
+}
+
+// list shows the cards of user, in pages.
+func (d *Decks) list(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	d.mutex.Lock()
+	cards, err := Deck(*storage, user)
+	d.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if len(cards) == 0 {
+		return sink(sender, service.Message{Title: "Deck", Description: fmt.Sprintf("Your deck is empty, add cards with %s.", SaveTrigger)})
+	}
+
+	now := time.Now()
+	pages := []service.Message{}
+	for start := 0; start < len(cards); start += cardsPerPage {
+		page := service.Message{
+			Title:       "Deck",
+			Description: fmt.Sprintf("%d cards, %d due.", len(cards), countDue(cards, now)),
+		}
+
+		for _, card := range cards[start:min(start+cardsPerPage, len(cards))] {
+			due := "due now"
+			if card.Due.After(now) {
+				due = "due " + card.Due.Format(time.DateOnly)
+			}
+			page.Fields = append(page.Fields, service.MessageField{
+				Field: fmt.Sprintf("%s (%s)", card.Front, due),
+				Value: truncate(card.Back, 200),
+			})
+		}
+		pages = append(pages, page)
+	}
+
+	if len(pages) == 1 {
+		return sink(sender, pages[0])
+	}
+	return sink(sender, service.Paginate(pages))
+}
+
+// review shows the front of the next card of user that is due.
+func (d *Decks) review(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cards, err := Deck(*storage, user)
+	if err != nil {
+		return err
+	}
+	return d.showNext(sender, user, cards, storage, sink)
+}
+
+// showNext shows the front of the next card in cards that is due, remembering it as the card user is reviewing.
+func (d *Decks) showNext(sender service.Conversation, user service.User, cards []Card, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	now := time.Now()
+	index := nextDue(cards, now)
+	if index == -1 {
+		if err := (*storage).SetUserValue(user, ReviewKey, ""); err != nil {
+			return err
+		}
+
+		reply := service.Message{Title: "Review finished", Description: fmt.Sprintf("Your deck is empty, add cards with %s.", SaveTrigger)}
+		if len(cards) > 0 {
+			next := cards[0].Due
+			for _, card := range cards {
+				if card.Due.Before(next) {
+					next = card.Due
+				}
+			}
+			reply.Description = fmt.Sprintf("No cards are due. The next is due %s.", next.Format(time.RFC1123))
+		}
+		return sink(sender, reply)
+	}
+
+	if err := (*storage).SetUserValue(user, ReviewKey, cards[index].Front); err != nil {
+		return err
+	}
+	if err := session.Start(*storage, sender, user, session.Session{Trigger: ReviewTrigger, Step: stepFront}); err != nil {
+		return err
+	}
+
+	return sink(sender, service.Message{
+		Title:       cards[index].Front,
+		Description: fmt.Sprintf("Think of what it means, then reply or use %s to check.", RevealTrigger),
+		Footer:      fmt.Sprintf("%d cards due. Reply %s to stop.", countDue(cards, now), session.CancelWord),
+		Choices:     []string{showAnswer},
+	})
+}
+
+// continueReview shows the back of a card when the user replies to its front, and rates it when they reply to its back.
+func (d *Decks) continueReview(active session.Session, sender service.Conversation, user service.User, text string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if active.Step == stepFront {
+		return d.revealCard(sender, user, storage, sink)
+	}
+
+	// The rating can follow the trigger of rate, as a session receives messages that start with a trigger.
+	words := strings.Fields(text)
+	quality := -1
+	if len(words) > 0 {
+		if number, err := strconv.Atoi(words[len(words)-1]); err == nil {
+			quality = number
+		}
+	}
+
+	if quality < 0 || quality > 5 {
+		if err := session.Start(*storage, sender, user, active); err != nil {
+			return err
+		}
+		return sink(sender, service.Message{Title: "Error", Description: "Rate from 0 (forgotten) to 5 (perfect).", Choices: ratings})
+	}
+	return d.rateCard(sender, user, quality, storage, sink)
+}
+
+// reviewing returns the index in cards of the card user is reviewing, or -1 if there isn't one.
+func reviewing(store storage.Storage, user service.User, cards []Card) int {
+	value, ok := store.GetUserValue(user, ReviewKey)
+	front, isText := value.(string)
+	if !ok || !isText || front == "" {
+		return -1
+	}
+	return find(cards, front)
+}
+
+// reveal shows the back of the card user is reviewing.
+func (d *Decks) reveal(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	return d.revealCard(sender, user, storage, sink)
+}
+
+// revealCard shows the back of the card user is reviewing, and asks for a rating.
+func (d *Decks) revealCard(sender service.Conversation, user service.User, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	d.mutex.Lock()
+	cards, err := Deck(*storage, user)
+	d.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	index := reviewing(*storage, user, cards)
+	if index == -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("You aren't reviewing a card, start with %s.", ReviewTrigger)})
+	}
+
+	if err := session.Start(*storage, sender, user, session.Session{Trigger: ReviewTrigger, Step: stepBack}); err != nil {
+		return err
+	}
+
+	return sink(sender, service.Message{
+		Title:       cards[index].Front,
+		Description: cards[index].Back,
+		Footer:      "Reply with how well you remembered it, from 0 (forgotten) to 5 (perfect).",
+		Choices:     ratings,
+	})
+}
+
+// rate reschedules the card user is reviewing, then shows the next card that is due.
+func (d *Decks) rate(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	return d.rateCard(sender, user, msg[0].(int), storage, sink)
+}
+
+// rateCard reschedules the card user is reviewing using quality, then shows the next card that is due.
+func (d *Decks) rateCard(sender service.Conversation, user service.User, quality int, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if quality < 0 || quality > 5 {
+		return sink(sender, service.Message{Title: "Error", Description: "Rate from 0 (forgotten) to 5 (perfect)."})
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cards, err := Deck(*storage, user)
+	if err != nil {
+		return err
+	}
+
+	index := reviewing(*storage, user, cards)
+	if index == -1 {
+		return sink(sender, service.Message{Description: fmt.Sprintf("You aren't reviewing a card, start with %s.", ReviewTrigger)})
+	}
+
+	card := cards[index].Review(quality, time.Now())
+	cards[index] = card
+	if err := SetDeck(*storage, user, cards); err != nil {
+		return err
+	}
+
+	result := fmt.Sprintf("%s is due again %s.", card.Front, card.Due.Format(time.DateOnly))
+	if quality < 3 {
+		result = fmt.Sprintf("%s will be shown again.", card.Front)
+	}
+	if err := sink(sender, service.Message{Description: result}); err != nil {
+		return err
+	}
+	return d.showNext(sender, user, cards, storage, sink)
+}
+
+// export sends the cards of user as a CSV file.
+func (d *Decks) export(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	d.mutex.Lock()
+	cards, err := Deck(*storage, user)
+	d.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	data, err := ExportCSV(cards)
+	if err != nil {
+		return err
+	}
+
+	return sink(sender, service.Message{
+		Title:       "Deck",
+		Description: fmt.Sprintf("%d cards. Use %s with this file to add them to a deck.", len(cards), ImportTrigger),
+		Attachments: []service.Attachment{{Name: "deck.csv", ContentType: "text/csv", Data: data}},
+	})
+}
+
+// importCSV adds cards from CSV, or from a url of a CSV file, to the deck of user.
+func (d *Decks) importCSV(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	text := strings.TrimSpace(msg[0].(string))
+	if strings.HasPrefix(text, "http://") || strings.HasPrefix(text, "https://") {
+		_, reader, err := d.Getter(text)
+		if err != nil {
+			return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s couldn't be retrieved.", text)})
+		}
+		defer reader.Close()
+
+		var buffer bytes.Buffer
+		if _, err := buffer.ReadFrom(io.LimitReader(reader, maxImportBytes+1)); err != nil {
+			return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s couldn't be retrieved.", text)})
+		}
+		if buffer.Len() > maxImportBytes {
+			return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s is too large to import.", text)})
+		}
+		text = buffer.String()
+	}
+
+	imported, err := ImportCSV(strings.NewReader(text), time.Now())
+	if err != nil {
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("The CSV couldn't be read: %s", err)})
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	cards, err := Deck(*storage, user)
+	if err != nil {
+		return err
+	}
+
+	cards = merge(cards, imported)
+	if len(cards) > MaxCards {
+		return sink(sender, service.Message{Description: fmt.Sprintf("Your deck can't have more than %d cards.", MaxCards)})
+	}
+
+	if err := SetDeck(*storage, user, cards); err != nil {
+		return err
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("%d cards have been imported, your deck has %d cards.", len(imported), len(cards))})
+}
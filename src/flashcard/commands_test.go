@@ -0,0 +1,212 @@
+package flashcard
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// define is a command that replies with what a word means.
+var define = command.Command{
+	Trigger:    "define",
+	Parameters: []command.Parameter{{Type: "string"}},
+	Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		if msg[0].(string) == "unknown" {
+			return sink(sender, service.Message{Title: "Error", Description: "Not found."})
+		}
+		return sink(sender, service.Message{Title: msg[0].(string), Description: "meaning of " + msg[0].(string)})
+	},
+}
+
+// testDecks returns Decks that look up words using define, and storage for their commands.
+func testDecks() (*Decks, *storage.Storage) {
+	decks := &Decks{
+		Lookup: define.Trigger,
+		Getter: func(url string) (string, io.ReadCloser, error) {
+			switch url {
+			case "https://example.com/deck.csv":
+				return url, io.NopCloser(strings.NewReader("paalam,goodbye\n")), nil
+			case "https://example.com/large.csv":
+				return url, io.NopCloser(strings.NewReader(strings.Repeat("a", maxImportBytes+1))), nil
+			}
+			return url, nil, fmt.Errorf("not found")
+		},
+	}
+	decks.SetCommands([]command.Command{define})
+	return decks, commandtest.Storage()
+}
+
+func TestSaveCard(t *testing.T) {
+	decks, store := testDecks()
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "kumusta | hello")
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "salamat")
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "unknown"); reply[0].Title != "Error" {
+		t.Errorf("Expected a word that can't be looked up to be reported, got %+v", reply)
+	}
+
+	cards, err := Deck(*store, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 || cards[0].Back != "hello" || cards[1].Back != "meaning of salamat" {
+		t.Errorf("Expected a card with a given meaning and one looked up, got %+v", cards)
+	}
+
+	other := service.User{Name: "Other_User", ServiceID: demoservice.ServiceID}
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, other, DeckTrigger); len(reply[0].Fields) != 0 {
+		t.Errorf("Expected decks of other users not to be listed, got %+v", reply)
+	}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, DeleteTrigger, "Kumusta")
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, user, DeckTrigger); len(reply[0].Fields) != 1 || !strings.HasPrefix(reply[0].Fields[0].Field, "salamat") {
+		t.Errorf("Expected a card to be deleted, got %+v", reply)
+	}
+}
+
+func TestReviewSession(t *testing.T) {
+	decks, store := testDecks()
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, user, RateTrigger, 5); !strings.Contains(reply[0].Description, "aren't reviewing") {
+		t.Errorf("Expected rating without a review to be refused, got %+v", reply)
+	}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "kumusta | hello")
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "salamat | thanks")
+
+	front := commandtest.Exec(t, decks.Commands(), store, conversation, user, ReviewTrigger)[0]
+	if front.Title != "kumusta" {
+		t.Fatalf("Expected the first card to be shown, got %+v", front)
+	}
+
+	if back := commandtest.Exec(t, decks.Commands(), store, conversation, user, RevealTrigger)[0]; back.Description != "hello" {
+		t.Errorf("Expected the back to be revealed, got %+v", back)
+	}
+
+	// A forgotten card is shown again after the other due cards.
+	replies := commandtest.Exec(t, decks.Commands(), store, conversation, user, RateTrigger, 1)
+	if len(replies) != 2 || replies[1].Title != "salamat" {
+		t.Fatalf("Expected the next card to be shown, got %+v", replies)
+	}
+
+	replies = commandtest.Exec(t, decks.Commands(), store, conversation, user, RateTrigger, 4)
+	if len(replies) != 2 || replies[1].Title != "kumusta" {
+		t.Fatalf("Expected the forgotten card to be shown again, got %+v", replies)
+	}
+
+	replies = commandtest.Exec(t, decks.Commands(), store, conversation, user, RateTrigger, 5)
+	if len(replies) != 2 || replies[1].Title != "Review finished" {
+		t.Fatalf("Expected the review to finish, got %+v", replies)
+	}
+
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, user, RevealTrigger); !strings.Contains(reply[0].Description, "aren't reviewing") {
+		t.Errorf("Expected the review to have ended, got %+v", reply)
+	}
+
+	cards, err := Deck(*store, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, card := range cards {
+		if card.Interval != 1 || card.Repetitions != 1 {
+			t.Errorf("Expected %s to be due in a day, got %+v", card.Front, card)
+		}
+	}
+}
+
+// replyToSession sends text from user to their session, returning the replies.
+func replyToSession(t *testing.T, decks *Decks, store *storage.Storage, user service.User, text string) []service.Message {
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	replies := demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+	handled, err := command.RouteToSession(decks.Commands(), nil, conversation, user, text, store, replies.SendMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !handled {
+		t.Fatalf("Expected %q to be sent to a session", text)
+	}
+
+	messages := []service.Message{}
+	for !replies.IsEmpty() {
+		reply, _ := replies.PopMessage()
+		messages = append(messages, reply)
+	}
+	return messages
+}
+
+func TestReviewByReplying(t *testing.T) {
+	decks, store := testDecks()
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "kumusta | hello")
+	if front := commandtest.Exec(t, decks.Commands(), store, conversation, user, ReviewTrigger)[0]; len(front.Choices) != 1 {
+		t.Fatalf("Expected a choice that shows the answer, got %+v", front)
+	}
+
+	back := replyToSession(t, decks, store, user, showAnswer)[0]
+	if back.Description != "hello" || len(back.Choices) != 6 {
+		t.Fatalf("Expected the back to be revealed with ratings, got %+v", back)
+	}
+
+	if reply := replyToSession(t, decks, store, user, "great")[0]; reply.Title != "Error" {
+		t.Errorf("Expected a reply that isn't a rating to be refused, got %+v", reply)
+	}
+
+	replies := replyToSession(t, decks, store, user, "4")
+	if len(replies) != 2 || replies[1].Title != "Review finished" {
+		t.Fatalf("Expected the review to finish, got %+v", replies)
+	}
+
+	cards, err := Deck(*store, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cards[0].Repetitions != 1 {
+		t.Errorf("Expected the card to be rated, got %+v", cards[0])
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	decks, store := testDecks()
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+	other := service.User{Name: "Other_User", ServiceID: demoservice.ServiceID}
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, user, SaveTrigger, "kumusta | hello")
+	export := commandtest.Exec(t, decks.Commands(), store, conversation, user, ExportTrigger)[0]
+	if len(export.Attachments) != 1 || export.Attachments[0].ContentType != "text/csv" {
+		t.Fatalf("Expected a CSV file, got %+v", export)
+	}
+
+	commandtest.Exec(t, decks.Commands(), store, conversation, other, ImportTrigger, string(export.Attachments[0].Data))
+	commandtest.Exec(t, decks.Commands(), store, conversation, other, ImportTrigger, "https://example.com/deck.csv")
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, other, ImportTrigger, "https://example.com/missing.csv"); reply[0].Title != "Error" {
+		t.Errorf("Expected a url that can't be retrieved to be reported, got %+v", reply)
+	}
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, other, ImportTrigger, "https://example.com/large.csv"); !strings.Contains(reply[0].Description, "too large") {
+		t.Errorf("Expected a CSV that's too large to be refused, got %+v", reply)
+	}
+	if reply := commandtest.Exec(t, decks.Commands(), store, conversation, other, ImportTrigger, "kumusta"); reply[0].Title != "Error" {
+		t.Errorf("Expected invalid CSV to be reported, got %+v", reply)
+	}
+
+	cards, err := Deck(*store, other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cards) != 2 || cards[0].Front != "kumusta" || cards[1].Front != "paalam" {
+		t.Errorf("Expected cards to be imported, got %+v", cards)
+	}
+}
@@ -0,0 +1,110 @@
+package flashcard
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvHeader names the columns of an exported deck.
+var csvHeader = []string{"front", "back", "due", "interval", "ease", "repetitions"}
+
+// ExportCSV writes cards as CSV, with a header and a row for each card.
+func ExportCSV(cards []Card) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, card := range cards {
+		err := writer.Write([]string{
+			card.Front,
+			card.Back,
+			card.Due.Format(time.RFC3339),
+			strconv.Itoa(card.Interval),
+			strconv.FormatFloat(card.Ease, 'f', -1, 64),
+			strconv.Itoa(card.Repetitions),
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+// ImportCSV reads cards written as CSV, such as by ExportCSV. Each row has a front and a back,
+// and optionally the due, interval, ease and repetitions columns of ExportCSV to keep a schedule.
+// Cards without a schedule are due at now. A header row starting with "front" is skipped.
+func ImportCSV(reader io.Reader, now time.Time) ([]Card, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	cards := []Card{}
+	for row := 1; ; row++ {
+		record, err := csvReader.Read()
+		if errors.Is(err, io.EOF) {
+			return cards, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if row == 1 && strings.EqualFold(strings.TrimSpace(record[0]), csvHeader[0]) {
+			continue
+		}
+
+		card, err := parseRecord(record, now)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		cards = append(cards, card)
+	}
+}
+
+// parseRecord reads a card from a row of CSV, as described by ImportCSV.
+func parseRecord(record []string, now time.Time) (Card, error) {
+	if len(record) < 2 || strings.TrimSpace(record[0]) == "" || strings.TrimSpace(record[1]) == "" {
+		return Card{}, fmt.Errorf("a front and a back are required")
+	}
+
+	card := NewCard(record[0], record[1], now)
+	if len(record) == 2 {
+		return card, nil
+	}
+
+	if len(record) != len(csvHeader) {
+		return Card{}, fmt.Errorf("expected 2 or %d columns, got %d", len(csvHeader), len(record))
+	}
+
+	due, err := time.Parse(time.RFC3339, strings.TrimSpace(record[2]))
+	if err != nil {
+		return Card{}, fmt.Errorf("due: %w", err)
+	}
+
+	interval, err := strconv.Atoi(strings.TrimSpace(record[3]))
+	if err != nil || interval < 0 {
+		return Card{}, fmt.Errorf("interval must be a whole number of days")
+	}
+
+	ease, err := strconv.ParseFloat(strings.TrimSpace(record[4]), 64)
+	if err != nil || ease < MinEase {
+		return Card{}, fmt.Errorf("ease must be a number of at least %g", MinEase)
+	}
+
+	repetitions, err := strconv.Atoi(strings.TrimSpace(record[5]))
+	if err != nil || repetitions < 0 {
+		return Card{}, fmt.Errorf("repetitions must be a whole number")
+	}
+
+	card.Due, card.Interval, card.Ease, card.Repetitions = due, interval, ease, repetitions
+	return card, nil
+}
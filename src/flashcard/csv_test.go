@@ -0,0 +1,68 @@
+package flashcard
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCSVRoundTrip(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cards := []Card{
+		NewCard("kumusta", "hello, how are you?", now).Review(4, now),
+		NewCard("salamat", "thank you\n(formal: salamat po)", now),
+	}
+
+	data, err := ExportCSV(cards)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportCSV(bytes.NewReader(data), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Added isn't exported.
+	for i := range cards {
+		cards[i].Added = now
+	}
+	if diff := cmp.Diff(cards, imported); diff != "" {
+		t.Errorf("Unexpected cards (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	cards, err := ImportCSV(strings.NewReader("kumusta, hello\n\"paalam\", \"goodbye\"\n"), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Card{NewCard("kumusta", "hello", now), NewCard("paalam", "goodbye", now)}
+	if diff := cmp.Diff(expected, cards); diff != "" {
+		t.Errorf("Unexpected cards (-want +got):\n%s", diff)
+	}
+}
+
+func TestImportCSVInvalid(t *testing.T) {
+	inputs := []string{
+		"kumusta",
+		"kumusta,",
+		",hello",
+		"kumusta,hello,2024-01-01",
+		"kumusta,hello,tomorrow,1,2.5,1",
+		"kumusta,hello,2024-01-01T00:00:00Z,-1,2.5,1",
+		"kumusta,hello,2024-01-01T00:00:00Z,1,1,1",
+		"\"kumusta,hello",
+	}
+
+	for _, input := range inputs {
+		if _, err := ImportCSV(strings.NewReader(input), time.Now()); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
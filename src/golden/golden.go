@@ -0,0 +1,166 @@
+// Package golden runs test cases against a configured bot, checking that each
+// input gets the expected replies.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+// TestsFilepath is the file in a configuration directory that has test cases.
+const TestsFilepath = "golden_tests.json"
+
+// A Case is an input sent to the bot, and what the bot is expected to reply with.
+type Case struct {
+	Name         string            `json:",omitempty"` // Shown when reporting results. Defaults to Input.
+	Input        string            // Message sent to the bot, such as "!define word".
+	User         string            `json:",omitempty"` // Name of the user sending Input. Defaults to "Test_User".
+	Conversation string            `json:",omitempty"` // ID of the conversation Input is sent to. Defaults to "0".
+	Guild        string            `json:",omitempty"` // ID of the guild Input is sent in.
+	Admin        bool              `json:",omitempty"` // When true, Input is sent by an admin.
+	Replies      []ExpectedMessage // Each reply the bot is expected to send, in order.
+	Error        *Matcher          `json:",omitempty"` // When set, the command is expected to fail with a matching error.
+}
+
+// DisplayName returns the name of a case used in reports.
+func (c Case) DisplayName() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Input
+}
+
+// An ExpectedMessage describes a reply. Missing matchers match anything.
+type ExpectedMessage struct {
+	Title       *Matcher        `json:",omitempty"`
+	Description *Matcher        `json:",omitempty"`
+	URL         *Matcher        `json:",omitempty"`
+	Fields      []ExpectedField `json:",omitempty"` // When present, the reply must have exactly these fields.
+}
+
+// An ExpectedField describes a field of a reply. Missing matchers match anything.
+type ExpectedField struct {
+	Field *Matcher `json:",omitempty"`
+	Value *Matcher `json:",omitempty"`
+}
+
+// A Matcher checks text. In JSON, it is either a string that text must equal,
+// or an object such as {"Contains": "word"} or {"Regexp": "^[a-z]+$"}.
+type Matcher struct {
+	Equals   *string `json:",omitempty"` // Text must be exactly this.
+	Contains string  `json:",omitempty"` // Text must contain this.
+	Regexp   string  `json:",omitempty"` // Text must match this regular expression.
+}
+
+// Exactly returns a Matcher for text that equals value.
+func Exactly(value string) *Matcher {
+	return &Matcher{Equals: &value}
+}
+
+// Match returns true if text is matched.
+func (m Matcher) Match(text string) (bool, error) {
+	if m.Equals != nil && text != *m.Equals {
+		return false, nil
+	}
+
+	if m.Contains != "" && !strings.Contains(text, m.Contains) {
+		return false, nil
+	}
+
+	if m.Regexp != "" {
+		re, err := regexp.Compile(m.Regexp)
+		if err != nil {
+			return false, err
+		}
+		return re.MatchString(text), nil
+	}
+	return true, nil
+}
+
+// String describes what a Matcher expects.
+func (m Matcher) String() string {
+	parts := []string{}
+	if m.Equals != nil {
+		parts = append(parts, fmt.Sprintf("%q", *m.Equals))
+	}
+	if m.Contains != "" {
+		parts = append(parts, fmt.Sprintf("containing %q", m.Contains))
+	}
+	if m.Regexp != "" {
+		parts = append(parts, fmt.Sprintf("matching /%s/", m.Regexp))
+	}
+
+	if len(parts) == 0 {
+		return "anything"
+	}
+	return strings.Join(parts, " and ")
+}
+
+// UnmarshalJSON reads a Matcher from either a string or an object.
+func (m *Matcher) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*m = Matcher{Equals: &text}
+		return nil
+	}
+
+	type matcher Matcher // Doesn't have UnmarshalJSON.
+	return json.Unmarshal(data, (*matcher)(m))
+}
+
+// MarshalJSON writes a Matcher that only checks equality as a string.
+func (m Matcher) MarshalJSON() ([]byte, error) {
+	if m.Equals != nil && m.Contains == "" && m.Regexp == "" {
+		return json.Marshal(*m.Equals)
+	}
+
+	type matcher Matcher // Doesn't have MarshalJSON.
+	return json.Marshal(matcher(m))
+}
+
+// Expect returns an ExpectedMessage that exactly matches msg.
+func Expect(msg service.Message) ExpectedMessage {
+	expected := ExpectedMessage{
+		Title:       Exactly(msg.Title),
+		Description: Exactly(msg.Description),
+		URL:         Exactly(msg.URL),
+	}
+
+	for _, field := range msg.Fields {
+		expected.Fields = append(expected.Fields, ExpectedField{
+			Field: Exactly(field.Field),
+			Value: Exactly(field.Value),
+		})
+	}
+	return expected
+}
+
+// LoadCases reads test cases from a file.
+func LoadCases(filepath string) ([]Case, error) {
+	var cases []Case
+	bytes, err := os.ReadFile(filepath)
+	if err != nil {
+		return cases, err
+	}
+
+	return cases, json.Unmarshal(bytes, &cases)
+}
+
+// SaveCases writes test cases to a file.
+func SaveCases(filepath string, cases []Case) error {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "\t")
+	if err := encoder.Encode(cases); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath, buffer.Bytes(), 0644)
+}
@@ -0,0 +1,256 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/session"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// getCommands returns an "echo" command that replies with its input and a field
+// describing the sender, a "pages" command that replies with two pages, an "ask" command
+// that replies to the next message, and a "fail" command that returns an error.
+func getCommands() []command.Command {
+	return []command.Command{
+		{
+			Trigger:    "echo",
+			Parameters: []command.Parameter{{Type: "string", Name: "text"}},
+			Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+				return sink(sender, service.Message{
+					Title:       "Echo",
+					Description: msg[0].(string),
+					Fields: []service.MessageField{
+						{Field: "user", Value: user.Name},
+						{Field: "admin", Value: map[bool]string{true: "yes", false: "no"}[sender.Admin]},
+					},
+				})
+			},
+		},
+		{
+			Trigger: "pages",
+			Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+				return sink(sender, service.Paginate([]service.Message{{Title: "One"}, {Title: "Two"}}))
+			},
+		},
+		{
+			Trigger: "ask",
+			Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+				if err := session.Start(*storage, sender, user, session.Session{Trigger: "ask"}); err != nil {
+					return err
+				}
+				return sink(sender, service.Message{Title: "What's your name?"})
+			},
+			Continue: func(_ session.Session, sender service.Conversation, user service.User, text string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+				return sink(sender, service.Message{Title: "Hello " + text})
+			},
+		},
+		{
+			Trigger: "fail",
+			Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+				return errors.New("failed on purpose")
+			},
+		},
+	}
+}
+
+func getStorage(t *testing.T) *storage.Storage {
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	if err := _storage.SetDefaultGuildValue("prefix", "!"); err != nil {
+		t.Fatal(err)
+	}
+	return &_storage
+}
+
+func parseCases(t *testing.T, text string) []Case {
+	var cases []Case
+	if err := json.Unmarshal([]byte(text), &cases); err != nil {
+		t.Fatal(err)
+	}
+	return cases
+}
+
+func TestRunPasses(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Input": "!echo hello", "Replies": [{"Title": "Echo", "Description": "hello"}]},
+		{"Input": "!echo hello", "Replies": [{"Description": {"Contains": "ell"}}]},
+		{"Input": "!echo hello", "Replies": [{"Description": {"Regexp": "^h.*o$"}}]},
+		{"Input": "!echo hi", "User": "someone", "Admin": true, "Replies": [{"Fields": [
+			{"Field": "user", "Value": "someone"},
+			{"Field": "admin", "Value": "yes"}
+		]}]},
+		{"Input": "!fail", "Replies": [], "Error": {"Contains": "on purpose"}}
+	]`)
+
+	results := Run(getCommands(), getStorage(t), cases)
+	for _, result := range results {
+		if !result.Passed() {
+			t.Errorf("%s failed: %v", result.Case.DisplayName(), result.Failures)
+		}
+	}
+
+	if !Passed(results) {
+		t.Errorf("Expected every case to pass")
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Name": "title", "Input": "!echo hello", "Replies": [{"Title": "Other"}]},
+		{"Name": "count", "Input": "!echo hello", "Replies": []},
+		{"Name": "fields", "Input": "!echo hello", "Replies": [{"Fields": [{"Field": "user"}]}]},
+		{"Name": "unexpected error", "Input": "!fail"},
+		{"Name": "missing error", "Input": "!echo hello", "Replies": [{}], "Error": "failed on purpose"},
+		{"Name": "unknown", "Input": "!unknown"},
+		{"Name": "bad regexp", "Input": "!echo hello", "Replies": [{"Title": {"Regexp": "("}}]}
+	]`)
+
+	results := Run(getCommands(), getStorage(t), cases)
+	for _, result := range results {
+		if result.Passed() {
+			t.Errorf("Expected %s to fail", result.Case.DisplayName())
+		}
+	}
+
+	expect := []string{`reply 1 title: expected "Other", got "Echo"`}
+	if diff := cmp.Diff(expect, results[0].Failures); diff != "" {
+		t.Errorf("Unexpected failures: %s", diff)
+	}
+}
+
+func TestRunPages(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Input": "!pages", "Replies": [{"Title": "One"}]},
+		{"Input": "!next", "Replies": [{"Title": "Two"}]},
+		{"Input": "!next", "Conversation": "other", "Replies": [{"Description": {"Contains": "no pages"}}]}
+	]`)
+
+	for _, result := range Run(getCommands(), getStorage(t), cases) {
+		if !result.Passed() {
+			t.Errorf("%s failed: %v", result.Case.DisplayName(), result.Failures)
+		}
+	}
+}
+
+func TestRunSession(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Input": "!ask", "Replies": [{"Title": "What's your name?"}]},
+		{"Input": "alice", "User": "someone", "Replies": [], "Error": {"Contains": "prefix"}},
+		{"Input": "!echo alice", "Replies": [{"Title": "Hello !echo alice"}]},
+		{"Input": "!ask", "Replies": [{"Title": "What's your name?"}]},
+		{"Input": "cancel", "Replies": [{"Description": "Cancelled."}]}
+	]`)
+
+	for _, result := range Run(getCommands(), getStorage(t), cases) {
+		if !result.Passed() {
+			t.Errorf("%s failed: %v", result.Case.DisplayName(), result.Failures)
+		}
+	}
+}
+
+func TestRunPrefix(t *testing.T) {
+	cases := parseCases(t, `[{"Input": "echo hello", "Replies": []}]`)
+	results := Run(getCommands(), getStorage(t), cases)
+	if results[0].Err == nil {
+		t.Errorf("Expected an error when the prefix is missing")
+	}
+}
+
+func TestMatcherJSON(t *testing.T) {
+	cases := parseCases(t, `[{"Input": "!echo", "Replies": [{"Title": "exact", "Description": {"Contains": "part"}}]}]`)
+	reply := cases[0].Replies[0]
+	if reply.Title.Equals == nil || *reply.Title.Equals != "exact" || reply.Description.Contains != "part" {
+		t.Fatalf("Unexpected matchers: %+v", reply)
+	}
+
+	bytes, err := json.Marshal(reply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(bytes) != `{"Title":"exact","Description":{"Contains":"part"}}` {
+		t.Errorf("Unexpected JSON %s", bytes)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	filepath := path.Join(t.TempDir(), TestsFilepath)
+	cases := parseCases(t, `[
+		{"Input": "!echo kept", "Replies": [{"Description": {"Contains": "kep"}}]},
+		{"Input": "!echo changed", "Replies": [{"Title": "Wrong"}]},
+		{"Input": "!fail", "Replies": []}
+	]`)
+
+	results := Run(getCommands(), getStorage(t), cases)
+	if err := SaveCases(filepath, Update(results)); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := LoadCases(filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated[0].Replies[0].Description.Contains != "kep" {
+		t.Errorf("Expected a passing case to keep its matchers")
+	}
+
+	if *updated[1].Replies[0].Title.Equals != "Echo" || *updated[1].Replies[0].Fields[0].Value.Equals != "Test_User" {
+		t.Errorf("Expected a failing case to expect the reply, got %+v", updated[1].Replies[0])
+	}
+
+	if updated[2].Error == nil || *updated[2].Error.Equals != "failed on purpose" {
+		t.Errorf("Expected a failing case to expect the error")
+	}
+
+	if !Passed(Run(getCommands(), getStorage(t), updated)) {
+		t.Errorf("Expected updated cases to pass")
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Name": "passes", "Input": "!echo a", "Replies": [{"Description": "a"}]},
+		{"Name": "fails", "Input": "!echo a", "Replies": [{"Description": "b"}]}
+	]`)
+
+	var buffer bytes.Buffer
+	if err := WriteText(&buffer, Run(getCommands(), getStorage(t), cases)); err != nil {
+		t.Fatal(err)
+	}
+
+	report := buffer.String()
+	for _, expect := range []string{"PASS  passes", "FAIL  fails", `reply 1 description: expected "b", got "a"`, "1 passed, 1 failed"} {
+		if !strings.Contains(report, expect) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expect, report)
+		}
+	}
+}
+
+func TestWriteJUnit(t *testing.T) {
+	cases := parseCases(t, `[
+		{"Name": "passes", "Input": "!echo a", "Replies": [{"Description": "a"}]},
+		{"Name": "fails", "Input": "!echo a", "Replies": [{"Description": "b"}]}
+	]`)
+
+	var buffer bytes.Buffer
+	if err := WriteJUnit(&buffer, "suite", Run(getCommands(), getStorage(t), cases)); err != nil {
+		t.Fatal(err)
+	}
+
+	report := buffer.String()
+	for _, expect := range []string{`<testsuite name="suite" tests="2" failures="1"`, `<testcase name="passes"`, `<failure message=`} {
+		if !strings.Contains(report, expect) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expect, report)
+		}
+	}
+}
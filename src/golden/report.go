@@ -0,0 +1,116 @@
+package golden
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Passed returns true if every result passed.
+func Passed(results []Result) bool {
+	for _, result := range results {
+		if !result.Passed() {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteText writes a readable report of results to out, describing how each failing case differs.
+func WriteText(out io.Writer, results []Result) error {
+	failed := 0
+	for _, result := range results {
+		status := "PASS"
+		if !result.Passed() {
+			status = "FAIL"
+			failed++
+		}
+
+		if _, err := fmt.Fprintf(out, "%s  %s (%s)\n", status, result.Case.DisplayName(), result.Duration.Round(time.Millisecond)); err != nil {
+			return err
+		}
+
+		if result.Passed() {
+			continue
+		}
+
+		lines := append([]string{"input: " + result.Case.Input}, result.Failures...)
+		for _, line := range lines {
+			if _, err := fmt.Fprintf(out, "      %s\n", line); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(out, "\n%d passed, %d failed\n", len(results)-failed, failed)
+	return err
+}
+
+// junitSuite is a <testsuite> element of a JUnit XML report.
+type junitSuite struct {
+	XMLName  xml.Name    `xml:"testsuite"`
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// junitCase is a <testcase> element of a JUnit XML report.
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+// junitFailure is a <failure> element of a JUnit XML report.
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results to out as JUnit XML, which CI systems can display.
+// name is the name of the test suite, such as the configuration directory.
+func WriteJUnit(out io.Writer, name string, results []Result) error {
+	suite := junitSuite{Name: name, Tests: len(results)}
+	var total time.Duration
+	for _, result := range results {
+		total += result.Duration
+		testCase := junitCase{
+			Name:      result.Case.DisplayName(),
+			ClassName: name,
+			Time:      seconds(result.Duration),
+		}
+
+		if !result.Passed() {
+			suite.Failures++
+			testCase.Failure = &junitFailure{
+				Message: result.Failures[0],
+				Text:    strings.Join(append([]string{"input: " + result.Case.Input}, result.Failures...), "\n"),
+			}
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Time = seconds(total)
+
+	if _, err := io.WriteString(out, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(out)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(out, "\n")
+	return err
+}
+
+// seconds formats a duration as JUnit expects.
+func seconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
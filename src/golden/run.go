@@ -0,0 +1,212 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// ServiceID identifies conversations and users made by the runner.
+const ServiceID = "Golden"
+
+// defaultUser sends inputs of cases without a User.
+const defaultUser = "Test_User"
+
+// A Result is the outcome of running a Case.
+type Result struct {
+	Case     Case
+	Replies  []service.Message // What the bot replied with.
+	Err      error             // What the command returned, if anything.
+	Failures []string          // How the replies differ from the expectation. Empty if the case passed.
+	Duration time.Duration
+}
+
+// Passed returns true if the bot replied as expected.
+func (r Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Run sends the input of each case to commands in order, checking the replies.
+// Cases share storage, so a case can depend on an earlier case (for example, setting a prefix).
+// Replies with pages are replaced by their first page, and unless a command already has its
+// trigger, a command is added to send the next page.
+func Run(commands []command.Command, storage *storage.Storage, cases []Case) []Result {
+	pages := service.NewPageStore(service.PageExpiry)
+	if !hasTrigger(commands, command.NextPageTrigger) {
+		commands = append(commands[:len(commands):len(commands)], command.NextPageCommand(pages))
+	}
+
+	results := make([]Result, 0, len(cases))
+	for _, testCase := range cases {
+		start := time.Now()
+		replies, err := execute(commands, storage, pages, testCase)
+		result := Result{
+			Case:     testCase,
+			Replies:  replies,
+			Err:      err,
+			Duration: time.Since(start),
+		}
+		result.Failures = check(testCase, replies, err)
+		results = append(results, result)
+	}
+	return results
+}
+
+// Update returns cases where every failing case expects what the bot replied with.
+// Passing cases are unchanged, so their matchers are kept.
+func Update(results []Result) []Case {
+	cases := make([]Case, 0, len(results))
+	for _, result := range results {
+		testCase := result.Case
+		if !result.Passed() {
+			testCase.Replies = []ExpectedMessage{}
+			for _, reply := range result.Replies {
+				testCase.Replies = append(testCase.Replies, Expect(reply))
+			}
+
+			testCase.Error = nil
+			if result.Err != nil {
+				testCase.Error = Exactly(result.Err.Error())
+			}
+		}
+		cases = append(cases, testCase)
+	}
+	return cases
+}
+
+// hasTrigger returns true if a command has trigger.
+func hasTrigger(commands []command.Command, trigger string) bool {
+	for _, cmd := range commands {
+		if cmd.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// execute sends a case's input to the command with a matching trigger, or to the session of its user,
+// returning the replies.
+func execute(commands []command.Command, storage *storage.Storage, pages *service.PageStore, testCase Case) ([]service.Message, error) {
+	conversation := service.Conversation{
+		ServiceID:      ServiceID,
+		ConversationID: testCase.Conversation,
+		GuildID:        testCase.Guild,
+		Admin:          testCase.Admin,
+	}
+	if conversation.ConversationID == "" {
+		conversation.ConversationID = "0"
+	}
+
+	user := service.User{Name: testCase.User, ServiceID: ServiceID}
+	if user.Name == "" {
+		user.Name = defaultUser
+	}
+
+	replies := []service.Message{}
+	sink := func(destination service.Conversation, msg service.Message) error {
+		replies = append(replies, pages.Fallback(destination, msg, command.Prefix(storage, destination)))
+		return nil
+	}
+
+	// A case can answer a question asked by the case before it.
+	handled, err := command.RouteToSession(commands, nil, conversation, user, testCase.Input, storage, sink)
+	if handled {
+		return replies, err
+	}
+
+	tokens := strings.Split(testCase.Input, " ")
+	prefix := command.Prefix(storage, conversation)
+
+	if !strings.HasPrefix(tokens[0], prefix) {
+		return nil, fmt.Errorf("input doesn't start with the prefix %q", prefix)
+	}
+
+	trigger := strings.TrimPrefix(tokens[0], prefix)
+	for _, cmd := range commands {
+		if cmd.Trigger != trigger {
+			continue
+		}
+
+		types := []string{}
+		for _, parameter := range cmd.Parameters {
+			types = append(types, parameter.Type)
+		}
+
+		parser := service.ParserBasic()
+		parser["user"] = parser["string"]
+		parser["role"] = parser["string"]
+		input, err := service.ParseInput(parser, tokens[1:], types)
+		if err != nil {
+			return nil, err
+		}
+
+		err = cmd.Exec(conversation, user, input, storage, sink)
+		return replies, err
+	}
+
+	return nil, fmt.Errorf("no command has the trigger %q", trigger)
+}
+
+// check compares replies to what testCase expects, returning each difference.
+func check(testCase Case, replies []service.Message, err error) []string {
+	failures := []string{}
+	if testCase.Error != nil {
+		if err == nil {
+			failures = append(failures, fmt.Sprintf("error: expected %s, got no error", testCase.Error))
+		} else {
+			failures = append(failures, match("error", testCase.Error, err.Error())...)
+		}
+	} else if err != nil {
+		failures = append(failures, fmt.Sprintf("error: expected no error, got %q", err))
+	}
+
+	if len(replies) != len(testCase.Replies) {
+		failures = append(failures, fmt.Sprintf("expected %d replies, got %d", len(testCase.Replies), len(replies)))
+	}
+
+	for i := 0; i < len(replies) && i < len(testCase.Replies); i++ {
+		expected := testCase.Replies[i]
+		reply := replies[i]
+		where := fmt.Sprintf("reply %d", i+1)
+
+		failures = append(failures, match(where+" title", expected.Title, reply.Title)...)
+		failures = append(failures, match(where+" description", expected.Description, reply.Description)...)
+		failures = append(failures, match(where+" url", expected.URL, reply.URL)...)
+
+		if expected.Fields == nil {
+			continue
+		}
+
+		if len(reply.Fields) != len(expected.Fields) {
+			failures = append(failures, fmt.Sprintf("%s: expected %d fields, got %d", where, len(expected.Fields), len(reply.Fields)))
+		}
+
+		for j := 0; j < len(reply.Fields) && j < len(expected.Fields); j++ {
+			fieldWhere := fmt.Sprintf("%s field %d", where, j+1)
+			failures = append(failures, match(fieldWhere+" name", expected.Fields[j].Field, reply.Fields[j].Field)...)
+			failures = append(failures, match(fieldWhere+" value", expected.Fields[j].Value, reply.Fields[j].Value)...)
+		}
+	}
+	return failures
+}
+
+// match returns a failure if matcher doesn't match text. A nil matcher matches anything.
+func match(where string, matcher *Matcher, text string) []string {
+	if matcher == nil {
+		return nil
+	}
+
+	ok, err := matcher.Match(text)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %s", where, err)}
+	}
+
+	if !ok {
+		return []string{fmt.Sprintf("%s: expected %s, got %q", where, matcher, text)}
+	}
+	return nil
+}
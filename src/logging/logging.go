@@ -0,0 +1,123 @@
+// Package logging builds structured loggers for the bot using log/slog.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+// Destinations that aren't files.
+const (
+	Stdout = "stdout"
+	Stderr = "stderr"
+)
+
+// DefaultFilepath is the file logs are written to when no destinations are configured.
+const DefaultFilepath = "logging.log"
+
+// Config describes how logs are formatted and where they are written.
+type Config struct {
+	Format       string   // "text" or "json". Defaults to "text".
+	Level        string   // "debug", "info", "warn" or "error". Defaults to "info".
+	Destinations []string // "stdout", "stderr" or filepaths. Defaults to stdout and logging.log.
+	MaxSizeMB    int      // Files are rotated once they reach this size. When 0, files are never rotated.
+	MaxBackups   int      // How many rotated files are kept, older files are removed. When 0, all are kept.
+}
+
+// New returns a logger described by config.
+// The returned closer must be called to close any files that are logged to.
+func New(config Config) (*slog.Logger, io.Closer, error) {
+	level, err := parseLevel(config.Level)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	destinations := config.Destinations
+	if len(destinations) == 0 {
+		destinations = []string{Stdout, DefaultFilepath}
+	}
+
+	closers := multiCloser{}
+	writers := []io.Writer{}
+	for _, destination := range destinations {
+		switch destination {
+		case Stdout:
+			writers = append(writers, os.Stdout)
+		case Stderr:
+			writers = append(writers, os.Stderr)
+		default:
+			file, err := OpenRotatingFile(destination, int64(config.MaxSizeMB)*1024*1024, config.MaxBackups)
+			if err != nil {
+				closers.Close()
+				return nil, nil, err
+			}
+			writers = append(writers, file)
+			closers = append(closers, file)
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	writer := io.MultiWriter(writers...)
+
+	var handler slog.Handler
+	switch strings.ToLower(config.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(writer, options)
+	case "json":
+		handler = slog.NewJSONHandler(writer, options)
+	default:
+		closers.Close()
+		return nil, nil, fmt.Errorf("unknown log format %q", config.Format)
+	}
+
+	return slog.New(handler), closers, nil
+}
+
+// parseLevel converts a level's name to a slog.Level.
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	return level, level.UnmarshalText([]byte(name))
+}
+
+// CommandLogger returns a logger with fields describing a command being executed.
+// If ctx has a span, its trace ID is included.
+func CommandLogger(ctx context.Context, logger *slog.Logger, conversation service.Conversation, user service.User, trigger string) *slog.Logger {
+	attrs := []any{}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		attrs = append(attrs, slog.String("trace_id", spanContext.TraceID().String()))
+	}
+
+	attrs = append(attrs,
+		slog.String("service", conversation.ServiceID),
+		slog.String("guild", conversation.GuildID),
+		slog.String("channel", conversation.ConversationID),
+		slog.String("user", user.Name),
+		slog.String("trigger", trigger),
+	)
+	return logger.With(attrs...)
+}
+
+// multiCloser closes several closers.
+type multiCloser []io.Closer
+
+// Close closes every closer, returning the first error encountered.
+func (m multiCloser) Close() error {
+	var firstErr error
+	for _, closer := range m {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+func TestNewJSON(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "test.log")
+	logger, closer, err := New(Config{Format: "json", Level: "warn", Destinations: []string{filepath}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown", "key", "value")
+	closer.Close()
+
+	content, err := os.ReadFile(filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(content, &entry); err != nil {
+		t.Fatalf("Expected a single json entry, got %q", content)
+	}
+
+	if entry["msg"] != "shown" || entry["key"] != "value" {
+		t.Errorf("Unexpected entry %v", entry)
+	}
+}
+
+func TestNewBadConfig(t *testing.T) {
+	if _, _, err := New(Config{Level: "loud", Destinations: []string{Stdout}}); err == nil {
+		t.Errorf("A bad level was accepted")
+	}
+
+	if _, _, err := New(Config{Format: "xml", Destinations: []string{Stdout}}); err == nil {
+		t.Errorf("A bad format was accepted")
+	}
+}
+
+func TestCommandLogger(t *testing.T) {
+	var buffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buffer, nil))
+
+	conversation := service.Conversation{ServiceID: "CLI", ConversationID: "channel", GuildID: "guild"}
+	user := service.User{Name: "user", ServiceID: "CLI"}
+	CommandLogger(context.Background(), logger, conversation, user, "help").Info("executed")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buffer.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+
+	expect := map[string]string{
+		"service": "CLI",
+		"guild":   "guild",
+		"channel": "channel",
+		"user":    "user",
+		"trigger": "help",
+	}
+	for key, value := range expect {
+		if entry[key] != value {
+			t.Errorf("Expected %s to be %s, got %v", key, value, entry[key])
+		}
+	}
+
+	if _, ok := entry["trace_id"]; ok {
+		t.Errorf("A trace ID was logged without a span")
+	}
+}
@@ -0,0 +1,116 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a file that is renamed once it reaches a size limit, so that
+// logging can continue in a new file.
+//
+// The current file is always at the original filepath. Older files have a numbered
+// suffix, where ".1" is the most recently rotated.
+type RotatingFile struct {
+	filepath   string
+	maxSize    int64 // When 0, the file is never rotated.
+	maxBackups int   // When 0, all rotated files are kept.
+	file       *os.File
+	size       int64
+	mutex      sync.Mutex // Lock when calling any public function.
+}
+
+// OpenRotatingFile opens filepath for appending, rotating it once it exceeds maxSize bytes.
+func OpenRotatingFile(filepath string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	r := &RotatingFile{
+		filepath:   filepath,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the file at filepath, remembering its current size.
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.filepath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	return nil
+}
+
+// Write appends p to the file, first rotating if p would exceed the size limit.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (r *RotatingFile) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.file.Close()
+}
+
+// rotate shifts each backup up by one, then moves the current file to the first backup.
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+
+	last := r.maxBackups
+	if last == 0 {
+		for last = 1; exists(r.backup(last)); last++ {
+		}
+	}
+
+	if err := os.Remove(r.backup(last)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	for i := last - 1; i > 0; i-- {
+		if err := os.Rename(r.backup(i), r.backup(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := os.Rename(r.filepath, r.backup(1)); err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+// backup returns the filepath of the i'th rotated file.
+func (r *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", r.filepath, i)
+}
+
+// exists returns true if a file exists at filepath.
+func exists(filepath string) bool {
+	_, err := os.Stat(filepath)
+	return err == nil
+}
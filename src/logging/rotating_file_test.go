@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"os"
+	"path"
+	"testing"
+)
+
+func TestRotatingFileRotates(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "test.log")
+	file, err := OpenRotatingFile(filepath, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := file.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expect := map[string]string{
+		filepath:        "fourth\n",
+		filepath + ".1": "third\n",
+		filepath + ".2": "second\n",
+	}
+	for name, content := range expect {
+		bytes, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bytes) != content {
+			t.Errorf("%s contained %q, expected %q", name, bytes, content)
+		}
+	}
+
+	if exists(filepath + ".3") {
+		t.Errorf("Too many backups were kept")
+	}
+}
+
+func TestRotatingFileKeepsAllBackups(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "test.log")
+	file, err := OpenRotatingFile(filepath, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for i := 0; i < 4; i++ {
+		if _, err := file.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{filepath, filepath + ".1", filepath + ".2", filepath + ".3"} {
+		if !exists(name) {
+			t.Errorf("%s was expected to exist", name)
+		}
+	}
+}
+
+func TestRotatingFileNoLimit(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "test.log")
+	file, err := OpenRotatingFile(filepath, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	for i := 0; i < 100; i++ {
+		if _, err := file.Write([]byte("line\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if exists(filepath + ".1") {
+		t.Errorf("A file without a limit was rotated")
+	}
+}
+
+func TestRotatingFileAppends(t *testing.T) {
+	filepath := path.Join(t.TempDir(), "test.log")
+	if err := os.WriteFile(filepath, []byte("existing\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := OpenRotatingFile(filepath, 100, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := file.Write([]byte("new\n")); err != nil {
+		t.Fatal(err)
+	}
+	file.Close()
+
+	bytes, _ := os.ReadFile(filepath)
+	if string(bytes) != "existing\nnew\n" {
+		t.Errorf("Unexpected content %q", bytes)
+	}
+}
@@ -1,4 +1,11 @@
 // package main runs a bot.
+//
+// Usage:
+//
+//	boby <dir>           Run the bot using the configuration files in dir.
+//	boby validate <dir>  Check the configuration files in dir, exiting with 1 if they are invalid.
+//	boby schema <file>   Write a JSON Schema for a configuration file, such as goquery_scraper_config.json.
+//	boby test <dir>      Run the cases in dir/golden_tests.json, exiting with 1 if any fail. Use -h for options.
 package main
 
 import (
@@ -6,54 +13,95 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
-	"io"
+	"fmt"
+	"log/slog"
 	"os"
 	"os/signal"
 	"path"
+	"strings"
 
 	"log"
 	"syscall"
+	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"go.opentelemetry.io/otel"
 
+	"github.com/BKrajancic/boby/m/v2/src/access"
+	"github.com/BKrajancic/boby/m/v2/src/command"
 	"github.com/BKrajancic/boby/m/v2/src/config"
+	"github.com/BKrajancic/boby/m/v2/src/feed"
+	"github.com/BKrajancic/boby/m/v2/src/flashcard"
+	"github.com/BKrajancic/boby/m/v2/src/logging"
+	"github.com/BKrajancic/boby/m/v2/src/quiz"
+	"github.com/BKrajancic/boby/m/v2/src/routine"
 	"github.com/BKrajancic/boby/m/v2/src/service/discordservice"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
+	"github.com/BKrajancic/boby/m/v2/src/tag"
+	"github.com/BKrajancic/boby/m/v2/src/telemetry"
+	"github.com/BKrajancic/boby/m/v2/src/utils"
 )
 
 func main() {
-	// Initialize OpenTelemetry tracing
-	ctx := context.Background()
-	shutdown, err := InitTracer(ctx, "boby")
-	if err != nil {
-		log.Fatalf("failed to initialize OpenTelemetry: %v", err)
+	exampleDir := "example"
+	if len(os.Args) == 1 {
+		log.Panicf("missing argument")
 	}
-	defer func() {
-		if err := shutdown(ctx); err != nil {
-			log.Printf("failed to shutdown OpenTelemetry: %v", err)
+
+	if os.Args[1] == "validate" {
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: boby validate <dir>")
+			os.Exit(2)
 		}
-	}()
+		os.Exit(validate(os.Args[2], os.Stdout))
+	}
 
-	tracer := otel.Tracer("boby/main")
-	ctx, startupSpan := tracer.Start(ctx, "Startup")
-	f, err := os.OpenFile("logging.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-	if err != nil {
-		log.Fatalf("error opening file: %v", err)
+	if os.Args[1] == "test" {
+		os.Exit(test(os.Args[2:], os.Stdout, os.Stderr))
 	}
-	defer f.Close()
-	log.SetOutput(io.MultiWriter(os.Stdout, f))
 
-	exampleDir := "example"
-	if len(os.Args) == 1 {
-		log.Panicf("missing argument")
+	if os.Args[1] == "schema" {
+		if len(os.Args) != 3 {
+			fmt.Fprintln(os.Stderr, "usage: boby schema <file>")
+			fmt.Fprintf(os.Stderr, "files: %s\n", strings.Join(config.SchemaNames(), ", "))
+			os.Exit(2)
+		}
+		os.Exit(schema(os.Args[2], os.Stdout, os.Stderr))
 	}
 
 	folder := os.Args[1]
-	_, err = os.Stat(folder)
+	_, err := os.Stat(folder)
 	if os.IsNotExist(err) {
 		panic(err)
 	}
 
+	settings, err := config.GetSettings(folder)
+	if err != nil {
+		log.Panicf("An error occurred when loading settings: %s", err)
+	}
+
+	logger, closeLogger, err := logging.New(settings.Logging)
+	if err != nil {
+		log.Panicf("An error occurred when setting up logging: %s", err)
+	}
+	defer closeLogger.Close()
+	slog.SetDefault(logger)
+
+	// Initialize OpenTelemetry tracing
+	ctx := context.Background()
+	shutdown, err := telemetry.InitTracer(ctx, "boby", settings.Telemetry)
+	if err != nil {
+		slog.Warn("failed to initialize OpenTelemetry, continuing without telemetry", "error", err)
+	}
+	defer func() {
+		if err := shutdown(ctx); err != nil {
+			slog.Error("failed to shutdown OpenTelemetry", "error", err)
+		}
+	}()
+
+	tracer := otel.Tracer("boby/main")
+	ctx, startupSpan := tracer.Start(ctx, "Startup")
+
 	// Trace storage loading
 	_, storageSpan := tracer.Start(ctx, "LoadStorage")
 	storage, err := loadGobStorage(path.Join(folder, "storage.gob"))
@@ -69,20 +117,50 @@ func main() {
 
 	// Trace config loading
 	_, configSpan := tracer.Start(ctx, "LoadConfig")
-	commands, err := config.ConfiguredBot(folder, &storage)
+	reloader := config.Reloader{ConfigDir: folder, Storage: &storage}
+	poller := feed.Poller{Storage: &storage, Getter: utils.HTMLGetWithHTTP, MaxPosts: settings.Feeds.MaxPosts}
+	if settings.Feeds.PollMinutes > 0 {
+		reloader.Extra = poller.Commands()
+	}
+	scheduler := routine.Scheduler{Storage: &storage, Timezone: settings.Scheduler.Timezone}
+	if settings.Scheduler.Enabled {
+		reloader.Extra = append(reloader.Extra, scheduler.Commands()...)
+	}
+	if settings.Scheduler.Reminders {
+		reloader.Extra = append(reloader.Extra, scheduler.ReminderCommands()...)
+	}
+	decks := flashcard.Decks{Lookup: settings.Flashcards.Lookup, Getter: utils.HTMLGetWithHTTP}
+	if settings.Flashcards.Enabled {
+		reloader.Extra = append(reloader.Extra, decks.Commands()...)
+	}
+	quizzes := quiz.Quiz{Storage: &storage, Decks: settings.Quiz.Decks, Seconds: settings.Quiz.Seconds, Rounds: settings.Quiz.Rounds}
+	if len(settings.Quiz.Decks) > 0 {
+		reloader.Extra = append(reloader.Extra, quizzes.Commands()...)
+	}
+	tagger := tag.Tagger{}
+	if settings.Tags.Enabled {
+		reloader.Extra = append(reloader.Extra, tagger.Commands()...)
+	}
+	guard := access.Guard{}
+	if settings.Access.Enabled {
+		reloader.Extra = append(reloader.Extra, guard.Commands()...)
+	}
+	commands, err := reloader.Commands()
 	configSpan.End()
 	if err != nil {
-		err = config.MakeExampleDir(exampleDir)
-		if err != nil {
-			log.Panicf("An error occurred when loading the configuration files, and also when creating an example: %s", err)
+		for _, problem := range config.Validate(folder) {
+			slog.Error("invalid configuration", "problem", problem.Error())
+		}
+
+		if exampleErr := config.MakeExampleDir(exampleDir); exampleErr != nil {
+			log.Panicf("An error occurred when loading the configuration files, and also when creating an example: %s", exampleErr)
 		}
 		log.Panicf("An error occurred when loading the configuration files: %s", err)
 	}
 
 	// Trace Discord service startup
 	_, discordSpan := tracer.Start(ctx, "StartDiscordService")
-	discordConfig := path.Join(folder, "config.json")
-	discordSubject, _, discord, err := discordservice.NewDiscords(discordConfig)
+	discordSubject, discordSender, discord, err := newDiscords(folder)
 	discordSpan.End()
 	if err != nil {
 		log.Panicf("An error occurred when loading discord: %s", err)
@@ -90,7 +168,7 @@ func main() {
 
 	err = discord.UpdateGameStatus(0, "Bot is reloading...")
 	if err != nil {
-		log.Println("Unable to set the game status", err)
+		slog.Warn("unable to set the game status", "error", err)
 	}
 
 	defer discordSubject.Close() // Cleanly close down the Discord session.
@@ -100,25 +178,86 @@ func main() {
 		discordSubject.Register(commands[i])
 	}
 
+	if settings.Tags.Enabled {
+		tagger.OnChange = discordSubject.UpdateGuild
+		discordSubject.AddGuildCommander(&tagger)
+	}
+
+	if settings.Access.Enabled {
+		discordSubject.AddFilter(guard.Filter)
+		scheduler.Filter = guard.Filter
+	}
+
 	err = discordSubject.Load()
 	if err != nil {
 		log.Fatalf("Unable to load DiscordSubject, exiting. Err: %s", err)
 	}
 
-	discordSubject.UnloadUselessCommands()
+	scheduler.SetCommands(commands)
+	decks.SetCommands(commands)
+	tagger.SetCommands(commands)
+	guard.SetCommands(commands)
+	reloader.OnReload = func(commands []command.Command) {
+		discordSubject.SetCommands(commands)
+		scheduler.SetCommands(commands)
+		decks.SetCommands(commands)
+		tagger.SetCommands(commands)
+		guard.SetCommands(commands)
+	}
+	stopWatching := make(chan struct{})
+	defer close(stopWatching)
+	if settings.WatchSeconds > 0 {
+		go reloader.Watch(time.Duration(settings.WatchSeconds)*time.Second, stopWatching)
+	}
+
+	if settings.Feeds.PollMinutes > 0 {
+		poller.AddSender(discordSender)
+		go poller.Run(time.Duration(settings.Feeds.PollMinutes)*time.Minute, stopWatching)
+	}
+
+	if len(settings.Quiz.Decks) > 0 {
+		quizzes.AddSender(discordSender)
+		discordSubject.AddListener(quizzes.Listen)
+	}
+
+	if settings.Scheduler.Enabled || settings.Scheduler.Reminders {
+		scheduler.AddSender(discordSender)
+		go scheduler.Run(time.Minute, stopWatching)
+	}
 
 	err = discord.UpdateGameStatus(0, "/help")
 	if err != nil {
-		log.Println("Unable to set the game status", err)
+		slog.Warn("unable to set the game status", "error", err)
 	}
 
-	log.Println("bot has loaded")
+	slog.Info("bot has loaded")
 
 	startupSpan.End()
 
 	sc := make(chan os.Signal, 1)
-	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, syscall.SIGTERM)
-	<-sc
+	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	for sig := range sc {
+		if sig != syscall.SIGHUP {
+			break
+		}
+
+		// Errors are logged by Reload, and the current commands are kept.
+		_ = reloader.Reload()
+	}
+}
+
+// newDiscords connects to discord, using the token from the unified configuration file
+// in folder if there is one, and otherwise from config.json.
+func newDiscords(folder string) (*discordservice.DiscordSubject, *discordservice.DiscordSender, *discordgo.Session, error) {
+	if _, ok := config.UnifiedFilepath(folder); ok {
+		botConfig, err := config.LoadBotConfig(folder)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return discordservice.NewDiscordsWithConfig(botConfig.Discord)
+	}
+
+	return discordservice.NewDiscords(path.Join(folder, "config.json"))
 }
 
 // loadGobStorage loads a file used for storage.
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/BKrajancic/boby/m/v2/src/config"
+)
+
+// schema writes a JSON Schema for the configuration file named filename to out.
+// Returns the exit code of the program, which is 2 if there is no such configuration file.
+func schema(filename string, out io.Writer, errOut io.Writer) int {
+	generated, err := config.GenerateSchema(filename)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(generated); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/BKrajancic/boby/m/v2/src/config"
+	"github.com/BKrajancic/boby/m/v2/src/fixture"
+	"github.com/BKrajancic/boby/m/v2/src/golden"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// test runs the golden test cases in a configuration directory, writing a report to out.
+// args are the arguments following "test". Returns the exit code of the program,
+// which is 1 if a case failed and 2 if the tests couldn't be run.
+func test(args []string, out io.Writer, errOut io.Writer) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(errOut)
+	update := flags.Bool("update", false, "rewrite the expectations of failing cases to match the replies")
+	junit := flags.String("junit", "", "also write results as JUnit XML to this file")
+	fixtures := flags.String("fixtures", "", "\"record\" or \"replay\" responses from websites using <dir>/fixtures")
+	flags.Usage = func() {
+		fmt.Fprintln(errOut, "usage: boby test [-update] [-junit file] [-fixtures record|replay] <dir>")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 {
+		if err == nil {
+			flags.Usage()
+		}
+		return 2
+	}
+	dir := flags.Arg(0)
+
+	mode, err := fixture.ParseMode(*fixtures)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	getters := config.DefaultGetters
+	if mode != fixture.Live {
+		store := fixture.Store{Dir: path.Join(dir, "fixtures"), Mode: mode}
+		getters = config.Getters{HTML: store.HTMLGetter, JSON: store.JSONGetter}
+	}
+
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	if err := _storage.SetDefaultGuildValue("prefix", "!"); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	commands, err := config.ConfiguredBotWithGetters(dir, &_storage, getters)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	testsFilepath := path.Join(dir, golden.TestsFilepath)
+	cases, err := golden.LoadCases(testsFilepath)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	results := golden.Run(commands, &_storage, cases)
+	if err := golden.WriteText(out, results); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 2
+	}
+
+	if *junit != "" {
+		file, err := os.Create(*junit)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 2
+		}
+		defer file.Close()
+
+		if err := golden.WriteJUnit(file, dir, results); err != nil {
+			fmt.Fprintln(errOut, err)
+			return 2
+		}
+	}
+
+	if *update {
+		if err := golden.SaveCases(testsFilepath, golden.Update(results)); err != nil {
+			fmt.Fprintln(errOut, err)
+			return 2
+		}
+		fmt.Fprintf(out, "Updated %s.\n", testsFilepath)
+		return 0
+	}
+
+	if !golden.Passed(results) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/BKrajancic/boby/m/v2/src/config"
+)
+
+// validate checks the configuration files in dir, writing each problem to out.
+// Returns the exit code of the program, which is 1 if there are errors.
+func validate(dir string, out io.Writer) int {
+	problems := config.Validate(dir)
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+
+	if config.HasErrors(problems) {
+		fmt.Fprintf(out, "%s is invalid.\n", dir)
+		return 1
+	}
+
+	fmt.Fprintf(out, "%s is valid.\n", dir)
+	return 0
+}
@@ -0,0 +1,96 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// QuizTrigger is a trigger to use for a command that starts a quiz.
+const QuizTrigger = "quiz"
+
+// StopTrigger is a trigger to use for a command that stops a quiz.
+const StopTrigger = "stopquiz"
+
+// LeaderboardTrigger is a trigger to use for a command that shows who has scored the most points.
+const LeaderboardTrigger = "leaderboard"
+
+// Triggers are the triggers of the commands returned by Commands.
+var Triggers = []string{QuizTrigger, StopTrigger, LeaderboardTrigger}
+
+// Commands returns commands that start and stop quizzes, and show leaderboards.
+func (q *Quiz) Commands() []command.Command {
+	names := []string{}
+	for _, deck := range q.Decks {
+		names = append(names, deck.Name)
+	}
+
+	return []command.Command{
+		{
+			Trigger: QuizTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "deck", Description: "Deck to ask questions from: " + strings.Join(names, ", ")},
+			},
+			Exec:      q.start,
+			Help:      fmt.Sprintf("Start a quiz in this channel, where the first correct answer to each question scores a point. Decks: %s.", strings.Join(names, ", ")),
+			HelpInput: "<deck>",
+		},
+		{
+			Trigger: StopTrigger,
+			Exec:    q.stop,
+			Help:    "Stop the quiz in this channel. Only usable by admins and who started the quiz.",
+		},
+		{
+			Trigger: LeaderboardTrigger,
+			Exec:    q.leaderboard,
+			Help:    "Show who has scored the most quiz points in this server, this week and of all time.",
+		},
+	}
+}
+
+// start starts a quiz in the sender's conversation.
+func (q *Quiz) start(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	question, err := q.Start(sender, user, strings.TrimSpace(msg[0].(string)))
+	if err != nil {
+		return sink(sender, service.Message{Title: "Error", Description: err.Error()})
+	}
+	return sink(sender, question)
+}
+
+// stop stops the quiz in the sender's conversation.
+func (q *Quiz) stop(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	results, err := q.Stop(sender, user)
+	if err != nil {
+		return sink(sender, service.Message{Description: err.Error()})
+	}
+	return sink(sender, results)
+}
+
+// leaderboard shows who has scored the most points in the sender's guild.
+func (q *Quiz) leaderboard(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	scores, err := Scores(*q.Storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	reply := service.Message{
+		Title:  "Leaderboard",
+		Footer: fmt.Sprintf("You have scored %d points in every server.", Points(*q.Storage, user)),
+	}
+	for _, board := range []struct {
+		name   string
+		weekly bool
+	}{{"This week", true}, {"All time", false}} {
+		value := "Nobody has scored any points."
+		if ranks := Leaderboard(scores, board.weekly, now, leaderboardLength); len(ranks) > 0 {
+			value = rankLines(ranks)
+		}
+		reply.Fields = append(reply.Fields, service.MessageField{Field: board.name, Value: value})
+	}
+	return sink(sender, reply)
+}
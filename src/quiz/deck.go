@@ -0,0 +1,62 @@
+// Package quiz runs vocabulary quizzes in a channel, where the first correct answer to each question scores a point.
+package quiz
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// A Deck is a set of questions that a quiz is made of.
+type Deck struct {
+	Name      string     // Name used to start a quiz of this deck.
+	Questions []Question // Questions asked in a random order.
+}
+
+// A Question is a prompt, such as a word to translate, and its correct answers.
+type Question struct {
+	Prompt  string   // Shown when the question is asked.
+	Answers []string // Answers that are correct, ignoring case, accents and punctuation.
+}
+
+// Correct returns true if answer is one of the answers of q, ignoring case, accents and punctuation.
+func (q Question) Correct(answer string) bool {
+	normalized := normalize(answer)
+	if normalized == "" {
+		return false
+	}
+
+	for _, correct := range q.Answers {
+		if normalize(correct) == normalized {
+			return true
+		}
+	}
+	return false
+}
+
+// answer returns the first answer of q that can be written, or "" if it has none.
+func (q Question) answer() string {
+	for _, answer := range q.Answers {
+		if normalize(answer) != "" {
+			return answer
+		}
+	}
+	return ""
+}
+
+// normalize makes text lowercase, and removes accents, punctuation and repeated spaces.
+func normalize(text string) string {
+	removeAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	unaccented, _, err := transform.String(removeAccents, text)
+	if err != nil {
+		unaccented = text
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(unaccented), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	})
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,22 @@
+package quiz
+
+import "testing"
+
+func TestCorrect(t *testing.T) {
+	question := Question{Prompt: "thank you", Answers: []string{"salamat", "Maraming salamat po"}}
+	for _, answer := range []string{"salamat", "SALAMAT", " salamat! ", "maraming salamat, po", "sálamat"} {
+		if !question.Correct(answer) {
+			t.Errorf("Expected %q to be correct", answer)
+		}
+	}
+
+	for _, answer := range []string{"", "!", "salamat po", "salamats"} {
+		if question.Correct(answer) {
+			t.Errorf("Expected %q to be incorrect", answer)
+		}
+	}
+
+	if !(Question{Answers: []string{"Ñandú"}}).Correct("nandu") {
+		t.Errorf("Expected accents to be ignored in answers")
+	}
+}
@@ -0,0 +1,249 @@
+package quiz
+
+import (
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// DefaultSeconds is how long a question can be answered for, unless Quiz.Seconds is set.
+const DefaultSeconds = 30
+
+// DefaultRounds is how many questions a quiz asks, unless Quiz.Rounds is set.
+const DefaultRounds = 10
+
+// leaderboardLength is how many users are shown on a leaderboard.
+const leaderboardLength = 10
+
+// A Quiz runs a game in each conversation that starts one, posting questions and scoring answers.
+type Quiz struct {
+	Storage *storage.Storage
+	Decks   []Deck
+	Seconds int              // How long each question can be answered for. Defaults to DefaultSeconds.
+	Rounds  int              // How many questions each game asks. Defaults to DefaultRounds.
+	router  service.Router   // Posts questions and results to the service of each game's conversation.
+	games   map[string]*game // Games being played, keyed by gameKey.
+	mutex   sync.Mutex       // Lock when using games.
+}
+
+// A game is a quiz being played in a conversation.
+type game struct {
+	conversation service.Conversation
+	deck         string
+	starter      service.User   // Who started the game, who can also stop it.
+	questions    []Question     // Questions left to ask, where the first is being asked.
+	round        int            // Which question is being asked, counting from 1.
+	rounds       int            // How many questions are asked.
+	points       map[string]int // Points scored in this game, keyed by user name.
+	timer        *time.Timer    // Ends the question being asked.
+}
+
+// gameKey returns the key of the game in conversation.
+func gameKey(conversation service.Conversation) string {
+	return conversation.ServiceID + "/" + conversation.ConversationID
+}
+
+// AddSender adds a sender that questions and results are posted with.
+func (q *Quiz) AddSender(sender service.Sender) {
+	q.router.AddSender(sender)
+}
+
+// deck returns the deck named name, ignoring case.
+func (q *Quiz) deck(name string) (Deck, bool) {
+	for _, deck := range q.Decks {
+		if strings.EqualFold(deck.Name, name) {
+			return deck, true
+		}
+	}
+	return Deck{}, false
+}
+
+// Start starts a game of the deck named name in conversation, returning the first question.
+func (q *Quiz) Start(conversation service.Conversation, user service.User, name string) (service.Message, error) {
+	deck, ok := q.deck(name)
+	if !ok {
+		return service.Message{}, fmt.Errorf("there is no deck %q", name)
+	}
+
+	// Questions that can't be answered are skipped, as nobody could score them.
+	questions := []Question{}
+	for _, question := range deck.Questions {
+		if question.answer() != "" {
+			questions = append(questions, question)
+		}
+	}
+	if len(questions) == 0 {
+		return service.Message{}, fmt.Errorf("the deck %q has no questions with answers", deck.Name)
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.games == nil {
+		q.games = map[string]*game{}
+	}
+
+	key := gameKey(conversation)
+	if _, ok := q.games[key]; ok {
+		return service.Message{}, fmt.Errorf("a quiz is already being played here")
+	}
+
+	rand.Shuffle(len(questions), func(i, j int) { questions[i], questions[j] = questions[j], questions[i] })
+
+	rounds := q.Rounds
+	if rounds <= 0 {
+		rounds = DefaultRounds
+	}
+
+	g := &game{
+		conversation: conversation,
+		deck:         deck.Name,
+		starter:      user,
+		questions:    questions,
+		round:        1,
+		rounds:       min(rounds, len(questions)),
+		points:       map[string]int{},
+	}
+	q.games[key] = g
+	return q.ask(key, g), nil
+}
+
+// Stop ends the game in conversation, returning its results. Only the user who started it, or an admin, can stop it.
+func (q *Quiz) Stop(conversation service.Conversation, user service.User) (service.Message, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	key := gameKey(conversation)
+	g, ok := q.games[key]
+	if !ok {
+		return service.Message{}, fmt.Errorf("no quiz is being played here")
+	}
+
+	if !conversation.Admin && g.starter != user {
+		return service.Message{}, fmt.Errorf("only admins or who started the quiz can stop it")
+	}
+
+	g.timer.Stop()
+	delete(q.games, key)
+	return g.results(), nil
+}
+
+// Listen checks whether a message answers the question being asked in conversation. The first correct
+// answer scores a point, and the next question is posted. It can be added to a service as a command.Listener.
+// Points that can't be saved, or messages that can't be posted, are logged rather than returned, so a
+// service keeps listening.
+func (q *Quiz) Listen(conversation service.Conversation, user service.User, text string, _ *storage.Storage, _ func(service.Conversation, service.Message) error) error {
+	q.mutex.Lock()
+	key := gameKey(conversation)
+	g, ok := q.games[key]
+	if !ok || !g.questions[0].Correct(text) {
+		q.mutex.Unlock()
+		return nil
+	}
+
+	g.timer.Stop()
+	g.points[user.Name]++
+	if err := award(*q.Storage, conversation.Guild(), user, time.Now()); err != nil {
+		slog.Error("unable to save quiz points", "guild", conversation.GuildID, "user", user.Name, "error", err)
+	}
+
+	messages := []service.Message{{
+		Description: fmt.Sprintf("<@%s> got it! The answer was %s.", user.Name, g.questions[0].answer()),
+	}}
+	messages = append(messages, q.advance(key, g))
+	q.mutex.Unlock()
+
+	if err := q.post(conversation, messages); err != nil {
+		slog.Error("unable to post quiz answer", "conversation", conversation.ConversationID, "error", err)
+	}
+	return nil
+}
+
+// timeUp ends round of g, the game with key, if nobody has answered it, and posts the next question.
+func (q *Quiz) timeUp(key string, g *game, round int) error {
+	q.mutex.Lock()
+	if q.games[key] != g || g.round != round {
+		q.mutex.Unlock()
+		return nil
+	}
+
+	messages := []service.Message{{
+		Description: fmt.Sprintf("Time's up! The answer was %s.", g.questions[0].answer()),
+	}}
+	messages = append(messages, q.advance(key, g))
+	q.mutex.Unlock()
+
+	return q.post(g.conversation, messages)
+}
+
+// post sends messages to conversation in order.
+func (q *Quiz) post(conversation service.Conversation, messages []service.Message) error {
+	for _, msg := range messages {
+		if err := q.router.Route(conversation, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// advance moves the game with key to its next question, returning the question, or the results
+// if the game has finished. Lock before using.
+func (q *Quiz) advance(key string, g *game) service.Message {
+	g.questions = g.questions[1:]
+	g.round++
+	if g.round > g.rounds {
+		delete(q.games, key)
+		return g.results()
+	}
+	return q.ask(key, g)
+}
+
+// ask returns the question being asked in the game with key, and ends it once time is up. Lock before using.
+func (q *Quiz) ask(key string, g *game) service.Message {
+	seconds := q.Seconds
+	if seconds <= 0 {
+		seconds = DefaultSeconds
+	}
+
+	round := g.round
+	g.timer = time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		if err := q.timeUp(key, g, round); err != nil {
+			slog.Error("unable to end quiz question", "conversation", g.conversation.ConversationID, "error", err)
+		}
+	})
+
+	return service.Message{
+		Title:       fmt.Sprintf("%s: question %d of %d", g.deck, g.round, g.rounds),
+		Description: g.questions[0].Prompt,
+		Footer:      fmt.Sprintf("Answer within %d seconds.", seconds),
+	}
+}
+
+// results returns a message with who scored the most points in g.
+func (g *game) results() service.Message {
+	scores := map[string]Score{}
+	for user, points := range g.points {
+		scores[user] = Score{Total: points}
+	}
+
+	msg := service.Message{Title: "Quiz finished", Description: "Nobody scored any points."}
+	if ranks := Leaderboard(scores, false, time.Now(), leaderboardLength); len(ranks) > 0 {
+		msg.Description = rankLines(ranks)
+	}
+	return msg
+}
+
+// rankLines writes ranks with a line for each user.
+func rankLines(ranks []Rank) string {
+	lines := []string{}
+	for i, rank := range ranks {
+		lines = append(lines, fmt.Sprintf("%d. <@%s>: %d", i+1, rank.User, rank.Points))
+	}
+	return strings.Join(lines, "\n")
+}
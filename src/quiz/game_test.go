@@ -0,0 +1,177 @@
+package quiz
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+)
+
+// testQuiz returns a quiz of a deck named tagalog, whose questions don't end on their own,
+// and the sender that it posts with.
+func testQuiz() (*Quiz, *demoservice.DemoSender) {
+	demoSender := &demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+	quiz := &Quiz{
+		Storage: commandtest.Storage(),
+		Seconds: 3600,
+		Decks: []Deck{{Name: "tagalog", Questions: []Question{
+			{Prompt: "hello", Answers: []string{"kumusta"}},
+			{Prompt: "thank you", Answers: []string{"salamat"}},
+			{Prompt: "goodbye", Answers: []string{"paalam"}},
+			{Prompt: "unanswerable", Answers: []string{" ", "?"}},
+			{Prompt: "blank"},
+		}}},
+	}
+	quiz.AddSender(demoSender)
+	return quiz, demoSender
+}
+
+// answerTo returns the answer of the question with prompt in quiz.
+func answerTo(quiz *Quiz, prompt string) string {
+	for _, question := range quiz.Decks[0].Questions {
+		if question.Prompt == prompt {
+			return question.Answers[0]
+		}
+	}
+	return ""
+}
+
+func TestQuiz(t *testing.T) {
+	quiz, demoSender := testQuiz()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	alice := service.User{Name: "1", ServiceID: demoservice.ServiceID}
+	bob := service.User{Name: "2", ServiceID: demoservice.ServiceID}
+
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "french"); reply.Title != "Error" {
+		t.Errorf("Expected an unknown deck to be reported, got %+v", reply)
+	}
+
+	question := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "Tagalog")
+	if !strings.HasSuffix(question.Title, "question 1 of 3") {
+		t.Fatalf("Expected the first question, got %+v", question)
+	}
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "tagalog"); reply.Title != "Error" {
+		t.Errorf("Expected a second quiz in a conversation to be refused, got %+v", reply)
+	}
+
+	// Wrong answers, and answers in another conversation, are ignored.
+	other := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "1", GuildID: "0"}
+	for _, listen := range []struct {
+		conversation service.Conversation
+		text         string
+	}{{conversation, "wrong"}, {other, answerTo(quiz, question.Description)}} {
+		if err := quiz.Listen(listen.conversation, bob, listen.text, quiz.Storage, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !demoSender.IsEmpty() {
+		t.Fatalf("Expected wrong answers to be ignored")
+	}
+
+	if err := quiz.Listen(conversation, bob, strings.ToUpper(answerTo(quiz, question.Description))+"!", quiz.Storage, nil); err != nil {
+		t.Fatal(err)
+	}
+	if reply, _ := demoSender.PopMessage(); !strings.HasPrefix(reply.Description, "<@2> got it") {
+		t.Errorf("Expected bob to score, got %+v", reply)
+	}
+	question, _ = demoSender.PopMessage()
+	if !strings.HasSuffix(question.Title, "question 2 of 3") {
+		t.Fatalf("Expected the second question, got %+v", question)
+	}
+
+	// Nobody answers the second question in time.
+	quiz.mutex.Lock()
+	key := gameKey(conversation)
+	g := quiz.games[key]
+	quiz.mutex.Unlock()
+	if err := quiz.timeUp(key, g, 2); err != nil {
+		t.Fatal(err)
+	}
+	if reply, _ := demoSender.PopMessage(); !strings.HasPrefix(reply.Description, "Time's up! The answer was "+answerTo(quiz, question.Description)) {
+		t.Errorf("Expected the answer to be given when time is up, got %+v", reply)
+	}
+	question, _ = demoSender.PopMessage()
+
+	// A question that has already ended isn't ended again.
+	if err := quiz.timeUp(key, g, 2); err != nil {
+		t.Fatal(err)
+	}
+	if !demoSender.IsEmpty() {
+		t.Errorf("Expected an ended question not to be ended again")
+	}
+
+	if err := quiz.Listen(conversation, bob, answerTo(quiz, question.Description), quiz.Storage, nil); err != nil {
+		t.Fatal(err)
+	}
+	demoSender.PopMessage()
+	if results, _ := demoSender.PopMessage(); results.Title != "Quiz finished" || results.Description != "1. <@2>: 2" {
+		t.Errorf("Expected the results after the last question, got %+v", results)
+	}
+
+	leaderboard := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, bob, LeaderboardTrigger)
+	if leaderboard.Fields[0].Value != "1. <@2>: 2" || leaderboard.Fields[1].Value != "1. <@2>: 2" || !strings.Contains(leaderboard.Footer, "2 points") {
+		t.Errorf("Expected bob to lead, got %+v", leaderboard)
+	}
+}
+
+func TestStopQuiz(t *testing.T) {
+	quiz, _ := testQuiz()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	alice := service.User{Name: "1", ServiceID: demoservice.ServiceID}
+	bob := service.User{Name: "2", ServiceID: demoservice.ServiceID}
+
+	commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "tagalog")
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, bob, StopTrigger); reply.Title == "Quiz finished" {
+		t.Errorf("Expected only who started the quiz to stop it")
+	}
+
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, StopTrigger); reply.Title != "Quiz finished" {
+		t.Errorf("Expected the quiz to be stopped, got %+v", reply)
+	}
+
+	commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "tagalog")
+	conversation.Admin = true
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, bob, StopTrigger); reply.Title != "Quiz finished" {
+		t.Errorf("Expected an admin to stop the quiz, got %+v", reply)
+	}
+}
+
+// failingSender is a Sender whose messages fail to send.
+type failingSender struct {
+	demoservice.DemoSender
+}
+
+func (f *failingSender) SendMessage(destination service.Conversation, msg service.Message) error {
+	return fmt.Errorf("unreachable")
+}
+
+func TestListenLogsErrors(t *testing.T) {
+	quiz, _ := testQuiz()
+	quiz.router = service.Router{}
+	quiz.AddSender(&failingSender{demoservice.DemoSender{ServiceID: demoservice.ServiceID}})
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	alice := service.User{Name: "1", ServiceID: demoservice.ServiceID}
+
+	question := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "tagalog")
+	if err := quiz.Listen(conversation, alice, answerTo(quiz, question.Description), quiz.Storage, nil); err != nil {
+		t.Errorf("Expected an answer that can't be posted not to be returned, got %s", err)
+	}
+
+	if points := Points(*quiz.Storage, alice); points != 1 {
+		t.Errorf("Expected the answer to score, got %d points", points)
+	}
+}
+
+func TestQuizWithoutAnswers(t *testing.T) {
+	quiz, _ := testQuiz()
+	quiz.Decks = append(quiz.Decks, Deck{Name: "empty", Questions: []Question{{Prompt: "blank", Answers: []string{""}}}})
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	alice := service.User{Name: "1", ServiceID: demoservice.ServiceID}
+
+	if reply := commandtest.Reply(t, quiz.Commands(), quiz.Storage, conversation, alice, QuizTrigger, "empty"); reply.Title != "Error" {
+		t.Errorf("Expected a deck without answers not to start, got %+v", reply)
+	}
+}
@@ -0,0 +1,105 @@
+package quiz
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// ScoresKey is the guild storage key of the points of each user in a guild, stored as JSON.
+const ScoresKey = "quiz_scores"
+
+// PointsKey is the user storage key of the points a user has scored in every guild.
+const PointsKey = "quiz_points"
+
+// A Score is the points of a user in a guild.
+type Score struct {
+	Total  int
+	Week   string // The week of Weekly, as written by week.
+	Weekly int    // Points scored in Week.
+}
+
+// A Rank is a user's place on a leaderboard.
+type Rank struct {
+	User   string // Name of the user.
+	Points int
+}
+
+// week returns the ISO week of t, such as "2024-W01".
+func week(t time.Time) string {
+	year, number := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, number)
+}
+
+// Scores returns the points of each user in guild, keyed by their name.
+func Scores(store storage.Storage, guild service.Guild) (map[string]Score, error) {
+	scores := map[string]Score{}
+	if err := storage.GetGuildJSON(store, guild, ScoresKey, &scores); err != nil {
+		return nil, fmt.Errorf("quiz scores of %s: %w", guild.GuildID, err)
+	}
+	return scores, nil
+}
+
+// Points returns the points user has scored in every guild.
+func Points(store storage.Storage, user service.User) int {
+	value, ok := store.GetUserValue(user, PointsKey)
+	if text, isText := value.(string); ok && isText {
+		if points, err := strconv.Atoi(text); err == nil {
+			return points
+		}
+	}
+	return 0
+}
+
+// award adds a point to the score of user in guild at now, and to the points of user.
+func award(store storage.Storage, guild service.Guild, user service.User, now time.Time) error {
+	scores, err := Scores(store, guild)
+	if err != nil {
+		return err
+	}
+
+	score := scores[user.Name]
+	if score.Week != week(now) {
+		score.Week, score.Weekly = week(now), 0
+	}
+	score.Total++
+	score.Weekly++
+	scores[user.Name] = score
+
+	if err := storage.SetGuildJSON(store, guild, ScoresKey, scores); err != nil {
+		return err
+	}
+	return store.SetUserValue(user, PointsKey, strconv.Itoa(Points(store, user)+1))
+}
+
+// Leaderboard returns the users of scores with the most points, most first. When weekly is true,
+// only points scored in the week of now count.
+func Leaderboard(scores map[string]Score, weekly bool, now time.Time, limit int) []Rank {
+	ranks := []Rank{}
+	for user, score := range scores {
+		points := score.Total
+		if weekly {
+			points = 0
+			if score.Week == week(now) {
+				points = score.Weekly
+			}
+		}
+
+		if points > 0 {
+			ranks = append(ranks, Rank{User: user, Points: points})
+		}
+	}
+
+	slices.SortFunc(ranks, func(a Rank, b Rank) int {
+		if a.Points != b.Points {
+			return b.Points - a.Points
+		}
+		return strings.Compare(a.User, b.User)
+	})
+	return ranks[:min(limit, len(ranks))]
+}
@@ -0,0 +1,63 @@
+package quiz
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+func TestAward(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+
+	guild := service.Guild{ServiceID: "demo", GuildID: "0"}
+	otherGuild := service.Guild{ServiceID: "demo", GuildID: "1"}
+	user := service.User{Name: "1", ServiceID: "demo"}
+	monday := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	nextMonday := monday.AddDate(0, 0, 7)
+
+	for _, point := range []struct {
+		guild service.Guild
+		now   time.Time
+	}{{guild, monday}, {guild, monday}, {otherGuild, monday}, {guild, nextMonday}} {
+		if err := award(store, point.guild, user, point.now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scores, err := Scores(store, guild)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Score{Total: 3, Week: "2024-W02", Weekly: 1}, scores[user.Name]); diff != "" {
+		t.Errorf("Unexpected score (-want +got):\n%s", diff)
+	}
+
+	if points := Points(store, user); points != 4 {
+		t.Errorf("Expected points of every guild to be counted, got %d", points)
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	scores := map[string]Score{
+		"a": {Total: 5, Week: week(now), Weekly: 1},
+		"b": {Total: 2, Week: week(now), Weekly: 2},
+		"c": {Total: 9, Week: "2023-W52", Weekly: 9},
+		"d": {Total: 2},
+	}
+
+	expected := []Rank{{"c", 9}, {"a", 5}, {"b", 2}}
+	if diff := cmp.Diff(expected, Leaderboard(scores, false, now, 3)); diff != "" {
+		t.Errorf("Unexpected all time leaderboard (-want +got):\n%s", diff)
+	}
+
+	expected = []Rank{{"b", 2}, {"a", 1}}
+	if diff := cmp.Diff(expected, Leaderboard(scores, true, now, 10)); diff != "" {
+		t.Errorf("Unexpected weekly leaderboard (-want +got):\n%s", diff)
+	}
+}
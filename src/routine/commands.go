@@ -0,0 +1,211 @@
+package routine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// ScheduleTrigger is a trigger to use for a command that schedules a message.
+const ScheduleTrigger = "schedule"
+
+// ScheduleCommandTrigger is a trigger to use for a command that schedules running a command.
+const ScheduleCommandTrigger = "schedulecommand"
+
+// UnscheduleTrigger is a trigger to use for a command that deletes a scheduled job.
+const UnscheduleTrigger = "unschedule"
+
+// SchedulesTrigger is a trigger to use for a command that lists a channel's scheduled jobs.
+const SchedulesTrigger = "schedules"
+
+// Triggers are the triggers of the commands returned by Commands.
+var Triggers = []string{ScheduleTrigger, ScheduleCommandTrigger, UnscheduleTrigger, SchedulesTrigger}
+
+// timezonePrefix starts the timezone of a schedule given to a command, such as "TZ=Asia/Manila".
+const timezonePrefix = "TZ="
+
+// Commands returns commands that let admins manage the jobs scheduled in a channel.
+func (s *Scheduler) Commands() []command.Command {
+	return []command.Command{
+		{
+			Trigger: ScheduleTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "job", Description: "When to post, then | and the message, such as: TZ=Asia/Manila 0 9 * * 1-5 | Good morning"},
+			},
+			Exec:      s.schedule(false),
+			Help:      "Post a message in this channel on a schedule, which is a cron expression (such as \"0 9 * * 1-5\"), a descriptor (such as \"@daily\") or an interval (such as \"@every 6h\"). Only usable by admins.",
+			HelpInput: "[TZ=<timezone>] <schedule> | <message>",
+		},
+		{
+			Trigger: ScheduleCommandTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "job", Description: "When to run, then | and the command, such as: @daily | define kumusta"},
+			},
+			Exec:      s.schedule(true),
+			Help:      "Run a command in this channel on a schedule, as with " + ScheduleTrigger + ". Only usable by admins.",
+			HelpInput: "[TZ=<timezone>] <schedule> | <trigger> <input>",
+		},
+		{
+			Trigger: UnscheduleTrigger,
+			Parameters: []command.Parameter{
+				{Type: "int", Name: "id", Description: "ID of the scheduled job, as listed by " + SchedulesTrigger},
+			},
+			Exec:      s.unschedule,
+			Help:      "Delete a scheduled job of this channel. Only usable by admins.",
+			HelpInput: "<id>",
+		},
+		{
+			Trigger: SchedulesTrigger,
+			Exec:    s.list,
+			Help:    "List the jobs scheduled in this channel.",
+		},
+	}
+}
+
+// parseJob reads a job written as "[TZ=<timezone>] <schedule> | <post>".
+func parseJob(text string) (Job, error) {
+	when, post, ok := strings.Cut(text, "|")
+	when, post = strings.TrimSpace(when), strings.TrimSpace(post)
+	if !ok || when == "" || post == "" {
+		return Job{}, fmt.Errorf("write a schedule, then | and what to post")
+	}
+
+	job := Job{When: when, Post: post}
+	if timezone, ok := strings.CutPrefix(when, timezonePrefix); ok {
+		job.Timezone, job.When, _ = strings.Cut(timezone, " ")
+		job.When = strings.TrimSpace(job.When)
+	}
+	return job, nil
+}
+
+// schedule returns the Exec of a command that schedules a job, which runs a command when isCommand is true.
+func (s *Scheduler) schedule(isCommand bool) func(service.Conversation, service.User, []interface{}, *storage.Storage, func(service.Conversation, service.Message) error) error {
+	return func(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		if !sender.Admin {
+			return sink(sender, service.Message{Description: "Only admins can schedule messages."})
+		}
+
+		job, err := parseJob(msg[0].(string))
+		if err != nil {
+			return sink(sender, service.Message{Title: "Error", Description: err.Error()})
+		}
+		job.ConversationID = sender.ConversationID
+		job.Command = isCommand
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if isCommand && !s.hasCommand(job.Post) {
+			return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("There is no command %s.", strings.Fields(job.Post)[0])})
+		}
+
+		job.Next, err = s.next(job, time.Now())
+		if err != nil {
+			return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%q isn't a schedule: %s", job.When, err)})
+		}
+
+		guild := sender.Guild()
+		jobs, err := Jobs(*s.Storage, guild)
+		if err != nil {
+			return err
+		}
+
+		job.ID = 1
+		for _, existing := range jobs {
+			job.ID = max(job.ID, existing.ID+1)
+		}
+
+		if err := SetJobs(*s.Storage, guild, append(jobs, job)); err != nil {
+			return err
+		}
+
+		return sink(sender, service.Message{
+			Title:       fmt.Sprintf("Scheduled job %d", job.ID),
+			Description: fmt.Sprintf("Next runs %s.", job.Next.Format(time.RFC1123)),
+		})
+	}
+}
+
+// hasCommand returns true if the trigger that post starts with is a command jobs can run.
+func (s *Scheduler) hasCommand(post string) bool {
+	trigger := strings.Fields(post)[0]
+	for _, cmd := range s.commands {
+		if cmd.Trigger == trigger {
+			return true
+		}
+	}
+	return false
+}
+
+// unschedule deletes a job scheduled in the sender's conversation.
+func (s *Scheduler) unschedule(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can delete scheduled jobs."})
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := msg[0].(int)
+	guild := sender.Guild()
+	jobs, err := Jobs(*s.Storage, guild)
+	if err != nil {
+		return err
+	}
+
+	for i, job := range jobs {
+		if job.ID == id && job.ConversationID == sender.ConversationID {
+			if err := SetJobs(*s.Storage, guild, append(jobs[:i], jobs[i+1:]...)); err != nil {
+				return err
+			}
+			return sink(sender, service.Message{Description: fmt.Sprintf("Scheduled job %d has been deleted.", id)})
+		}
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("There is no scheduled job %d in this channel.", id)})
+}
+
+// list shows the jobs scheduled in the sender's conversation.
+func (s *Scheduler) list(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	s.mutex.Lock()
+	jobs, err := Jobs(*s.Storage, sender.Guild())
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	reply := service.Message{Title: "Scheduled jobs"}
+	for _, job := range jobs {
+		if job.ConversationID != sender.ConversationID {
+			continue
+		}
+
+		when := job.When
+		if job.Timezone != "" {
+			when = timezonePrefix + job.Timezone + " " + when
+		}
+
+		action := "Posts"
+		if job.Command {
+			action = "Runs"
+		}
+
+		value := fmt.Sprintf("%s: %s", action, job.Post)
+		if job.Broken != "" {
+			value += fmt.Sprintf("\nDoesn't run, as it can't be scheduled: %s", job.Broken)
+		}
+
+		reply.Fields = append(reply.Fields, service.MessageField{
+			Field: fmt.Sprintf("%d: %s", job.ID, when),
+			Value: value,
+		})
+	}
+
+	if len(reply.Fields) == 0 {
+		reply.Description = "There are no jobs scheduled in this channel."
+	}
+	return sink(sender, reply)
+}
@@ -0,0 +1,76 @@
+package routine
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// JobsKey is the guild storage key of a guild's scheduled jobs, stored as JSON.
+const JobsKey = "schedules"
+
+// GuildsKey is the global storage key of the guilds that have scheduled jobs, stored as JSON.
+// Storage can't list guilds, so this is how jobs are found when running them.
+const GuildsKey = "schedule_guilds"
+
+// A Job posts a message, or runs a command, in a conversation on a schedule.
+type Job struct {
+	ID             int
+	ConversationID string
+	When           string    // When the job runs, as read by ParseSchedule.
+	Timezone       string    // Timezone of When, such as "Asia/Manila". Empty for UTC.
+	Post           string    // Text that is posted, or when Command is true, a trigger followed by its input.
+	Command        bool      // When true, Post is run as a command, and its reply is posted.
+	Next           time.Time // When the job next runs. Jobs missed while the bot was offline run once it starts.
+	Broken         string    // Why the job can't be scheduled, such as an unknown timezone. A broken job doesn't run, but is kept so an admin can see and delete it.
+}
+
+// Jobs returns the scheduled jobs of guild.
+func Jobs(store storage.Storage, guild service.Guild) ([]Job, error) {
+	jobs := []Job{}
+	if err := storage.GetGuildJSON(store, guild, JobsKey, &jobs); err != nil {
+		return nil, fmt.Errorf("scheduled jobs of %s: %w", guild.GuildID, err)
+	}
+	return jobs, nil
+}
+
+// SetJobs replaces the scheduled jobs of guild, remembering which guilds have any.
+func SetJobs(store storage.Storage, guild service.Guild, jobs []Job) error {
+	if err := storage.SetGuildJSON(store, guild, JobsKey, jobs); err != nil {
+		return err
+	}
+
+	return updateIndex(store, GuildsKey, guild, len(jobs) > 0)
+}
+
+// scheduledGuilds returns the guilds that have scheduled jobs.
+func scheduledGuilds(store storage.Storage) ([]service.Guild, error) {
+	guilds := []service.Guild{}
+	if err := storage.GetGlobalJSON(store, GuildsKey, &guilds); err != nil {
+		return nil, fmt.Errorf("scheduled guilds: %w", err)
+	}
+	return guilds, nil
+}
+
+// updateIndex adds item to, or when included is false removes item from, the list stored as JSON at the global key.
+// Storage can't list guilds or users, so these lists are how stored values are found.
+func updateIndex[T comparable](store storage.Storage, key string, item T, included bool) error {
+	items := []T{}
+	if err := storage.GetGlobalJSON(store, key, &items); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+
+	index := slices.Index(items, item)
+	if index == -1 && included {
+		items = append(items, item)
+	} else if index != -1 && !included {
+		items = slices.Delete(items, index, index+1)
+	} else {
+		return nil
+	}
+
+	return storage.SetGlobalJSON(store, key, items)
+}
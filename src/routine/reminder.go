@@ -0,0 +1,233 @@
+package routine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// RemindersKey is the user storage key of a user's reminders, stored as JSON.
+const RemindersKey = "reminders"
+
+// UsersKey is the global storage key of the users that have reminders, stored as JSON.
+const UsersKey = "reminder_users"
+
+// MaxReminders is how many reminders a user can have at once.
+const MaxReminders = 25
+
+// MaxReminderDelay is how far ahead a reminder can be set.
+const MaxReminderDelay = 366 * 24 * time.Hour
+
+// maxAttempts is how many times delivering a reminder is tried before it's dropped.
+const maxAttempts = 3
+
+// retryDelay is how long to wait before trying to deliver a reminder again.
+const retryDelay = time.Minute
+
+// sendTimeout is how long a reminder is being sent before it's assumed that sending was interrupted,
+// such as by the bot stopping, so it's sent again.
+const sendTimeout = 10 * time.Minute
+
+// clockLayouts are the ways a time of day can be written, after being made lowercase.
+var clockLayouts = []string{"15:04", "3pm", "3:04pm", "3 pm", "3:04 pm"}
+
+// dateLayouts are the ways a date, with or without a time, can be written, after being made lowercase.
+var dateLayouts = []string{"2006-01-02t15:04", "2006-01-02 15:04", "2006-01-02 3pm", "2006-01-02 3:04pm", "2006-01-02"}
+
+// durationUnits are the units of a duration written in words, such as "2 hours".
+var durationUnits = map[string]time.Duration{
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// A Reminder is a message a user asked to be sent to them later.
+type Reminder struct {
+	ID           int
+	Conversation service.Conversation // Where the reminder was set, and where it's posted unless Direct is true.
+	Direct       bool                 // When true, the reminder is sent privately to the user.
+	Text         string
+	Created      time.Time
+	Due          time.Time
+	Attempts     int       // How many times delivering the reminder failed.
+	Sending      time.Time // When the reminder started being sent, so it isn't sent twice at once. Zero when it isn't being sent.
+}
+
+// sending returns true if the reminder started being sent recently enough that it's still being sent at now.
+func (r Reminder) sending(now time.Time) bool {
+	return !r.Sending.IsZero() && now.Sub(r.Sending) < sendTimeout
+}
+
+// Message returns the message that delivers the reminder.
+func (r Reminder) Message() service.Message {
+	return service.Message{
+		Title:       "Reminder",
+		Description: r.Text,
+		Footer:      fmt.Sprintf("Set %s", r.Created.Format(time.RFC1123)),
+		Timestamp:   r.Due,
+	}
+}
+
+// ParseReminder reads a reminder written as "[me] [privately] <when> to <text>", at now.
+// <when> is relative, such as "in 2h" or "in 1 day 3 hours", or absolute, such as "at 17:30",
+// "tomorrow at 9am" or "on 2024-05-01 09:00". Times of day are in location.
+func ParseReminder(input string, now time.Time, location *time.Location) (Reminder, error) {
+	reminder := Reminder{Created: now}
+	words := strings.Fields(input)
+	for len(words) > 0 {
+		word := strings.ToLower(words[0])
+		if word == "privately" || word == "dm" {
+			reminder.Direct = true
+		} else if word != "me" {
+			break
+		}
+		words = words[1:]
+	}
+
+	text := strings.Join(words, " ")
+	index := strings.Index(strings.ToLower(text), " to ")
+	if index == -1 {
+		return Reminder{}, fmt.Errorf("write when, then \"to\" and what to be reminded of, such as: in 2h to review vocabulary")
+	}
+
+	reminder.Text = strings.TrimSpace(text[index+len(" to "):])
+	if reminder.Text == "" {
+		return Reminder{}, fmt.Errorf("write what to be reminded of after \"to\"")
+	}
+
+	due, err := parseWhen(strings.TrimSpace(text[:index]), now, location)
+	if err != nil {
+		return Reminder{}, err
+	}
+
+	if !due.After(now) {
+		return Reminder{}, fmt.Errorf("%s has already passed", due.In(location).Format(time.RFC1123))
+	}
+	if due.Sub(now) > MaxReminderDelay {
+		return Reminder{}, fmt.Errorf("reminders can't be set more than a year ahead")
+	}
+
+	reminder.Due = due
+	return reminder, nil
+}
+
+// parseWhen reads when a reminder is due, as written for ParseReminder.
+func parseWhen(when string, now time.Time, location *time.Location) (time.Time, error) {
+	lower := strings.ToLower(when)
+	if duration, ok := strings.CutPrefix(lower, "in "); ok {
+		delay, err := parseDuration(duration)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(delay), nil
+	}
+
+	local := now.In(location)
+	tomorrow := false
+	if rest, ok := strings.CutPrefix(lower, "tomorrow"); ok {
+		tomorrow = true
+		lower = strings.TrimSpace(rest)
+		if lower == "" {
+			return now.AddDate(0, 0, 1), nil
+		}
+	}
+
+	lower = strings.TrimPrefix(lower, "on ")
+	lower = strings.TrimSpace(strings.TrimPrefix(lower, "at "))
+
+	for _, layout := range clockLayouts {
+		clock, err := time.Parse(layout, lower)
+		if err != nil {
+			continue
+		}
+
+		due := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		if tomorrow || !due.After(now) {
+			due = due.AddDate(0, 0, 1)
+		}
+		return due, nil
+	}
+
+	if !tomorrow {
+		// A time with an offset, such as "2024-05-01T09:00:00+08:00", isn't in location.
+		if due, err := time.Parse(time.RFC3339, strings.ToUpper(lower)); err == nil {
+			return due, nil
+		}
+
+		for _, layout := range dateLayouts {
+			if due, err := time.ParseInLocation(layout, lower, location); err == nil {
+				return due, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q isn't a time, try something like \"in 2h\", \"at 17:30\" or \"on 2024-05-01 09:00\"", when)
+}
+
+// parseDuration reads a duration such as "2h30m", "90 minutes" or "an hour and 30 minutes".
+func parseDuration(text string) (time.Duration, error) {
+	if duration, err := time.ParseDuration(strings.ReplaceAll(text, " ", "")); err == nil && duration > 0 {
+		return duration, nil
+	}
+
+	words := strings.Fields(strings.ReplaceAll(text, ",", " "))
+	total := time.Duration(0)
+	for len(words) > 0 {
+		if words[0] == "and" {
+			words = words[1:]
+			continue
+		}
+
+		if len(words) < 2 {
+			return 0, fmt.Errorf("%q isn't a duration, try something like \"2h\" or \"3 days\"", text)
+		}
+
+		amount, err := strconv.Atoi(words[0])
+		if words[0] == "a" || words[0] == "an" {
+			amount, err = 1, nil
+		}
+
+		unit, ok := durationUnits[words[1]]
+		if err != nil || !ok || amount <= 0 {
+			return 0, fmt.Errorf("%q isn't a duration, try something like \"2h\" or \"3 days\"", text)
+		}
+
+		total += time.Duration(amount) * unit
+		words = words[2:]
+	}
+
+	if total == 0 {
+		return 0, fmt.Errorf("%q isn't a duration, try something like \"2h\" or \"3 days\"", text)
+	}
+	return total, nil
+}
+
+// Reminders returns the reminders of user.
+func Reminders(store storage.Storage, user service.User) ([]Reminder, error) {
+	reminders := []Reminder{}
+	if err := storage.GetUserJSON(store, user, RemindersKey, &reminders); err != nil {
+		return nil, fmt.Errorf("reminders of %s: %w", user.Name, err)
+	}
+	return reminders, nil
+}
+
+// SetReminders replaces the reminders of user, remembering which users have any.
+func SetReminders(store storage.Storage, user service.User, reminders []Reminder) error {
+	if err := storage.SetUserJSON(store, user, RemindersKey, reminders); err != nil {
+		return err
+	}
+	return updateIndex(store, UsersKey, user, len(reminders) > 0)
+}
+
+// remindedUsers returns the users that have reminders.
+func remindedUsers(store storage.Storage) ([]service.User, error) {
+	users := []service.User{}
+	if err := storage.GetGlobalJSON(store, UsersKey, &users); err != nil {
+		return nil, fmt.Errorf("reminded users: %w", err)
+	}
+	return users, nil
+}
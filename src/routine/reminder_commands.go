@@ -0,0 +1,141 @@
+package routine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// RemindTrigger is a trigger to use for a command that sets a reminder.
+const RemindTrigger = "remind"
+
+// RemindersTrigger is a trigger to use for a command that lists a user's reminders.
+const RemindersTrigger = "reminders"
+
+// CancelReminderTrigger is a trigger to use for a command that cancels a reminder.
+const CancelReminderTrigger = "cancelreminder"
+
+// ReminderTriggers are the triggers of the commands returned by ReminderCommands.
+var ReminderTriggers = []string{RemindTrigger, RemindersTrigger, CancelReminderTrigger}
+
+// ReminderCommands returns commands that let users set, list and cancel their reminders.
+func (s *Scheduler) ReminderCommands() []command.Command {
+	return []command.Command{
+		{
+			Trigger: RemindTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "reminder", Description: "When, then \"to\" and what to be reminded of, such as: in 2h to review vocabulary"},
+			},
+			Exec:      s.remind,
+			Help:      "Set a reminder, such as \"me in 2h to review vocabulary\", \"at 17:30 to practise\" or \"privately tomorrow at 9am to study\". Private reminders are sent as a direct message.",
+			HelpInput: "[me] [privately] <when> to <text>",
+		},
+		{
+			Trigger: RemindersTrigger,
+			Exec:    s.listReminders,
+			Help:    "List your reminders.",
+		},
+		{
+			Trigger: CancelReminderTrigger,
+			Parameters: []command.Parameter{
+				{Type: "int", Name: "id", Description: "ID of the reminder, as listed by " + RemindersTrigger},
+			},
+			Exec:      s.cancelReminder,
+			Help:      "Cancel one of your reminders.",
+			HelpInput: "<id>",
+		},
+	}
+}
+
+// remind sets a reminder for user.
+func (s *Scheduler) remind(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	location, err := LoadLocation(s.Timezone)
+	if err != nil {
+		return err
+	}
+
+	reminder, err := ParseReminder(msg[0].(string), time.Now(), location)
+	if err != nil {
+		return sink(sender, service.Message{Title: "Error", Description: err.Error()})
+	}
+	reminder.Conversation = sender
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	reminders, err := Reminders(*s.Storage, user)
+	if err != nil {
+		return err
+	}
+
+	if len(reminders) >= MaxReminders {
+		return sink(sender, service.Message{Description: fmt.Sprintf("You can't have more than %d reminders.", MaxReminders)})
+	}
+
+	reminder.ID = 1
+	for _, existing := range reminders {
+		reminder.ID = max(reminder.ID, existing.ID+1)
+	}
+
+	if err := SetReminders(*s.Storage, user, append(reminders, reminder)); err != nil {
+		return err
+	}
+
+	return sink(sender, service.Message{
+		Title:       fmt.Sprintf("Reminder %d set", reminder.ID),
+		Description: fmt.Sprintf("You'll be reminded %s.", reminder.Due.In(location).Format(time.RFC1123)),
+	})
+}
+
+// listReminders shows the reminders of user.
+func (s *Scheduler) listReminders(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	location, err := LoadLocation(s.Timezone)
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	reminders, err := Reminders(*s.Storage, user)
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	reply := service.Message{Title: "Reminders"}
+	for _, reminder := range reminders {
+		reply.Fields = append(reply.Fields, service.MessageField{
+			Field: fmt.Sprintf("%d: %s", reminder.ID, reminder.Due.In(location).Format(time.RFC1123)),
+			Value: reminder.Text,
+		})
+	}
+
+	if len(reply.Fields) == 0 {
+		reply.Description = "You have no reminders."
+	}
+	return sink(sender, reply)
+}
+
+// cancelReminder removes one of the reminders of user.
+func (s *Scheduler) cancelReminder(sender service.Conversation, user service.User, msg []interface{}, _ *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	id := msg[0].(int)
+	reminders, err := Reminders(*s.Storage, user)
+	if err != nil {
+		return err
+	}
+
+	for i, reminder := range reminders {
+		if reminder.ID == id {
+			if err := SetReminders(*s.Storage, user, append(reminders[:i], reminders[i+1:]...)); err != nil {
+				return err
+			}
+			return sink(sender, service.Message{Description: fmt.Sprintf("Reminder %d has been cancelled.", id)})
+		}
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("You have no reminder %d.", id)})
+}
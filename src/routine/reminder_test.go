@@ -0,0 +1,256 @@
+package routine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+)
+
+func TestParseReminder(t *testing.T) {
+	manila, err := time.LoadLocation("Asia/Manila")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, manila)
+
+	cases := []struct {
+		input  string
+		due    time.Time
+		text   string
+		direct bool
+	}{
+		{"me in 2h to review vocabulary", now.Add(2 * time.Hour), "review vocabulary", false},
+		{"in 1 day 3 hours to practise", now.Add(27 * time.Hour), "practise", false},
+		{"in an hour and 30 minutes to Go to class", now.Add(90 * time.Minute), "Go to class", false},
+		{"me privately in 1h30m to study", now.Add(90 * time.Minute), "study", true},
+		{"at 17:30 to practise", time.Date(2024, 1, 1, 17, 30, 0, 0, manila), "practise", false},
+		{"at 9:15 to practise", time.Date(2024, 1, 2, 9, 15, 0, 0, manila), "practise", false},
+		{"tomorrow at 9am to study", time.Date(2024, 1, 2, 9, 0, 0, 0, manila), "study", false},
+		{"tomorrow to study", now.AddDate(0, 0, 1), "study", false},
+		{"on 2024-05-01 09:00 to study", time.Date(2024, 5, 1, 9, 0, 0, 0, manila), "study", false},
+		{"on 2024-05-01 to study", time.Date(2024, 5, 1, 0, 0, 0, 0, manila), "study", false},
+		{"2024-05-01T09:00:00Z to study", time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC), "study", false},
+	}
+
+	for _, c := range cases {
+		reminder, err := ParseReminder(c.input, now, manila)
+		if err != nil {
+			t.Errorf("%s: %s", c.input, err)
+			continue
+		}
+
+		if !reminder.Due.Equal(c.due) || reminder.Text != c.text || reminder.Direct != c.direct {
+			t.Errorf("Expected %q to be due %s with %q (direct %t), got %s with %q (direct %t)", c.input, c.due, c.text, c.direct, reminder.Due, reminder.Text, reminder.Direct)
+		}
+	}
+}
+
+func TestParseReminderInvalid(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC)
+	inputs := []string{
+		"in 2h",
+		"in 2h to ",
+		"in soon to study",
+		"in 0 minutes to study",
+		"in 2 fortnights to study",
+		"whenever to study",
+		"on 2023-05-01 to study",
+		"in 400 days to study",
+	}
+
+	for _, input := range inputs {
+		if _, err := ParseReminder(input, now, time.UTC); err == nil {
+			t.Errorf("Expected %q to be rejected", input)
+		}
+	}
+}
+
+// failingSender is a DirectSender whose messages fail to send.
+type failingSender struct {
+	demoservice.DemoSender
+}
+
+func (f *failingSender) SendMessage(destination service.Conversation, msg service.Message) error {
+	return fmt.Errorf("unreachable")
+}
+
+func TestDeliverReminders(t *testing.T) {
+	scheduler, demoSender := testScheduler()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+
+	for _, input := range []string{"in 1h to first", "privately in 2h to second", "in 3h to third"} {
+		if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, input); !strings.HasSuffix(reply.Title, "set") {
+			t.Fatalf("Expected a reminder to be set, got %+v", reply)
+		}
+	}
+
+	later := time.Now().Add(150 * time.Minute)
+	if err := scheduler.RunDue(later); err != nil {
+		t.Fatal(err)
+	}
+
+	first, sentTo := demoSender.PopMessage()
+	if first.Description != "first" || sentTo != conversation {
+		t.Errorf("Expected first to be sent to %+v, got %+v to %+v", conversation, first, sentTo)
+	}
+
+	second, sentTo := demoSender.PopMessage()
+	if second.Description != "second" || sentTo.ConversationID != user.Name {
+		t.Errorf("Expected second to be sent privately, got %+v to %+v", second, sentTo)
+	}
+
+	// Reminders are delivered once.
+	if err := scheduler.RunDue(later); err != nil {
+		t.Fatal(err)
+	}
+	if !demoSender.IsEmpty() {
+		t.Errorf("Expected reminders to be delivered once")
+	}
+
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindersTrigger); len(reply.Fields) != 1 || reply.Fields[0].Value != "third" {
+		t.Errorf("Expected only third to be left, got %+v", reply)
+	}
+}
+
+func TestDeliverRemindersRetries(t *testing.T) {
+	scheduler, _ := testScheduler()
+	scheduler.router = service.Router{}
+	scheduler.AddSender(&failingSender{demoservice.DemoSender{ServiceID: demoservice.ServiceID}})
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+
+	commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "in 1h to study")
+	now := time.Now().Add(time.Hour)
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		reminders, err := Reminders(*scheduler.Storage, user)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reminders) != 1 {
+			t.Fatalf("Expected the reminder to be kept before attempt %d, got %+v", attempt, reminders)
+		}
+
+		if err := scheduler.RunDue(now); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(retryDelay)
+	}
+
+	if reminders, _ := Reminders(*scheduler.Storage, user); len(reminders) != 0 {
+		t.Errorf("Expected the reminder to be dropped after %d attempts, got %+v", maxAttempts, reminders)
+	}
+}
+
+// callbackSender calls onSend with each message it sends.
+type callbackSender struct {
+	demoservice.DemoSender
+	onSend func(msg service.Message)
+}
+
+func (c *callbackSender) SendMessage(destination service.Conversation, msg service.Message) error {
+	c.onSend(msg)
+	return c.DemoSender.SendMessage(destination, msg)
+}
+
+func TestDeliverRemindersKeepsUntilSent(t *testing.T) {
+	scheduler, _ := testScheduler()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+
+	sent := []string{}
+	sender := &callbackSender{DemoSender: demoservice.DemoSender{ServiceID: demoservice.ServiceID}}
+	sender.onSend = func(msg service.Message) {
+		sent = append(sent, msg.Description)
+		reminders, err := Reminders(*scheduler.Storage, user)
+		if err != nil || len(reminders) != 1 || reminders[0].Sending.IsZero() {
+			t.Errorf("Expected the reminder to be kept and marked while being sent, got %+v %s", reminders, err)
+		}
+
+		// The reminder is cancelled while being sent, and its ID is reused by a new reminder.
+		commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, CancelReminderTrigger, 1)
+		if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "in 5h to later"); reply.Title != "Reminder 1 set" {
+			t.Errorf("Expected the ID to be reused, got %+v", reply)
+		}
+	}
+	scheduler.router = service.Router{}
+	scheduler.AddSender(sender)
+
+	commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "in 1h to study")
+	if err := scheduler.RunDue(time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	reminders, err := Reminders(*scheduler.Storage, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sent) != 1 || len(reminders) != 1 || reminders[0].Text != "later" {
+		t.Errorf("Expected only the new reminder to be kept, sent %v and kept %+v", sent, reminders)
+	}
+}
+
+func TestDeliverRemindersInterrupted(t *testing.T) {
+	scheduler, demoSender := testScheduler()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+
+	now := time.Now()
+	interrupted := Reminder{ID: 1, Conversation: conversation, Text: "study", Created: now, Due: now, Sending: now}
+	if err := SetReminders(*scheduler.Storage, user, []Reminder{interrupted}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := scheduler.RunDue(now.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if !demoSender.IsEmpty() {
+		t.Errorf("Expected a reminder being sent not to be sent again")
+	}
+
+	if err := scheduler.RunDue(now.Add(sendTimeout)); err != nil {
+		t.Fatal(err)
+	}
+	if posted := popDescriptions(demoSender); len(posted) != 1 || posted[0] != "study" {
+		t.Errorf("Expected an interrupted reminder to be sent again, got %v", posted)
+	}
+
+	if reminders, _ := Reminders(*scheduler.Storage, user); len(reminders) != 0 {
+		t.Errorf("Expected the reminder to be removed once sent, got %+v", reminders)
+	}
+}
+
+func TestReminderCommands(t *testing.T) {
+	scheduler, _ := testScheduler()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0"}
+	user := service.User{Name: "Test_User", ServiceID: demoservice.ServiceID}
+	other := service.User{Name: "Other_User", ServiceID: demoservice.ServiceID}
+
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "whenever to study"); reply.Title != "Error" {
+		t.Errorf("Expected an invalid time to be reported, got %+v", reply)
+	}
+
+	for i := 0; i < MaxReminders; i++ {
+		commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "in 1h to study")
+	}
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindTrigger, "in 1h to study"); !strings.Contains(reply.Description, "more than") {
+		t.Errorf("Expected too many reminders to be refused, got %+v", reply)
+	}
+
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, other, CancelReminderTrigger, 1); !strings.Contains(reply.Description, "no reminder") {
+		t.Errorf("Expected reminders of other users not to be cancelled, got %+v", reply)
+	}
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, other, RemindersTrigger); len(reply.Fields) != 0 {
+		t.Errorf("Expected reminders of other users not to be listed, got %+v", reply)
+	}
+
+	commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, CancelReminderTrigger, 1)
+	if reply := commandtest.Reply(t, scheduler.ReminderCommands(), scheduler.Storage, conversation, user, RemindersTrigger); len(reply.Fields) != MaxReminders-1 {
+		t.Errorf("Expected a reminder to be cancelled, got %d reminders", len(reply.Fields))
+	}
+}
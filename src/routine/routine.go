@@ -0,0 +1,19 @@
+// Package routine sends messages on a schedule, rather than in reply to a command.
+package routine
+
+import (
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+// Heartbeat sends a message every now and again using parameter route.
+// This is only useful for testing purposes.
+func Heartbeat(delay time.Duration, destination service.Conversation, msg service.Message, route func(service.Conversation, service.Message) error) {
+	for range time.Tick(delay) {
+		err := route(destination, msg)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -8,17 +8,6 @@ import (
 	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
 )
 
-// Heartbeat sends a message every now and again using parameter route.
-// This is only useful for testing purposes.
-func Heartbeat(delay time.Duration, destination service.Conversation, msg service.Message, route func(service.Conversation, service.Message) error) {
-	for range time.Tick(delay) {
-		err := route(destination, msg)
-		if err != nil {
-			panic(err)
-		}
-	}
-}
-
 // Test if the heartbeat routine works.
 // Heartbeat is really only for testing purposes.
 func TestHeartbeat(t *testing.T) {
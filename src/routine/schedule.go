@@ -0,0 +1,69 @@
+package routine
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// everyPrefix starts a schedule that repeats after an interval, such as "@every 6h".
+const everyPrefix = "@every "
+
+// ParseSchedule reads when a job runs, using timezone for times of day.
+// when is either a cron expression with five fields, such as "0 9 * * 1-5",
+// a descriptor such as "@daily", or an interval such as "@every 6h".
+// Intervals are counted from midnight in timezone, so "@every 6h" runs at 00:00, 06:00, 12:00 and 18:00.
+// An empty timezone is UTC.
+func ParseSchedule(when string, timezone string) (cron.Schedule, error) {
+	location, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	when = strings.TrimSpace(when)
+	if interval, ok := strings.CutPrefix(when, everyPrefix); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, err
+		}
+
+		if every < time.Minute {
+			return nil, fmt.Errorf("%s is shorter than a minute", every)
+		}
+		return intervalSchedule{every: every, location: location}, nil
+	}
+
+	schedule, err := cron.ParseStandard(when)
+	if err != nil {
+		return nil, err
+	}
+
+	if spec, ok := schedule.(*cron.SpecSchedule); ok {
+		spec.Location = location
+	}
+	return schedule, nil
+}
+
+// LoadLocation returns the timezone named timezone, such as "Asia/Manila". An empty timezone is UTC.
+func LoadLocation(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(timezone)
+}
+
+// intervalSchedule runs every interval, counted from midnight in location.
+type intervalSchedule struct {
+	every    time.Duration
+	location *time.Location
+}
+
+// Next returns the first time after t that is a whole number of intervals after midnight.
+func (i intervalSchedule) Next(t time.Time) time.Time {
+	local := t.In(i.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, i.location)
+	intervals := local.Sub(midnight)/i.every + 1
+	return midnight.Add(intervals * i.every)
+}
@@ -0,0 +1,62 @@
+package routine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	manila, err := time.LoadLocation("Asia/Manila")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC) // A Monday.
+
+	cases := []struct {
+		when     string
+		timezone string
+		expect   time.Time
+	}{
+		{"0 9 * * *", "", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 9 * * *", "Asia/Manila", time.Date(2024, 1, 2, 9, 0, 0, 0, manila)},
+		{"*/15 * * * *", "", time.Date(2024, 1, 1, 10, 45, 0, 0, time.UTC)},
+		{"0 9 * * 6", "", time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC)},
+		{"@daily", "Asia/Manila", time.Date(2024, 1, 2, 0, 0, 0, 0, manila)},
+		{"@every 6h", "", time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"@every 6h", "Asia/Manila", time.Date(2024, 1, 2, 0, 0, 0, 0, manila)},
+		{"@every 25h", "", time.Date(2024, 1, 2, 1, 0, 0, 0, time.UTC)},
+	}
+
+	for _, c := range cases {
+		schedule, err := ParseSchedule(c.when, c.timezone)
+		if err != nil {
+			t.Errorf("%s: %s", c.when, err)
+			continue
+		}
+
+		if next := schedule.Next(now); !next.Equal(c.expect) {
+			t.Errorf("Expected %s in %q to next run at %s, got %s", c.when, c.timezone, c.expect, next)
+		}
+	}
+}
+
+func TestParseScheduleInvalid(t *testing.T) {
+	cases := []struct {
+		when     string
+		timezone string
+	}{
+		{"", ""},
+		{"0 9 * *", ""},
+		{"61 * * * *", ""},
+		{"@every 30s", ""},
+		{"@every often", ""},
+		{"@sometimes", ""},
+		{"0 9 * * *", "Nowhere/Town"},
+	}
+
+	for _, c := range cases {
+		if _, err := ParseSchedule(c.when, c.timezone); err == nil {
+			t.Errorf("Expected %q in %q to be rejected", c.when, c.timezone)
+		}
+	}
+}
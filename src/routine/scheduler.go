@@ -0,0 +1,298 @@
+package routine
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// SchedulerUser is the user that scheduled commands are run as.
+var SchedulerUser = service.User{Name: "Scheduler"}
+
+// A Scheduler runs scheduled jobs when they are due, posting to the conversations that scheduled them.
+type Scheduler struct {
+	Storage  *storage.Storage // Where jobs are kept.
+	Timezone string           // Timezone of jobs that don't give one. Empty for UTC.
+	Filter   command.Filter   // Decides whether a job's command can run in its conversation, such as when a guild disabled it. When nil, every command can run.
+	router   service.Router   // Posts jobs and reminders to the service of each conversation.
+	commands []command.Command
+	mutex    sync.Mutex // Lock when reading and then writing jobs, or when using commands.
+}
+
+// AddSender will append a sender that messages are routed to.
+func (s *Scheduler) AddSender(sender service.Sender) {
+	s.router.AddSender(sender)
+}
+
+// SetCommands replaces the commands that jobs can run, such as after the configuration is reloaded.
+// A job whose command was removed fails with an error that's logged when it's due.
+func (s *Scheduler) SetCommands(commands []command.Command) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.commands = commands
+}
+
+// Run checks for due jobs every interval. It returns once stop is closed.
+func (s *Scheduler) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			// RunDue has logged any jobs or reminders it couldn't read or save, which are tried again next tick.
+			_ = s.RunDue(now)
+		}
+	}
+}
+
+// RunDue runs every job that was due at or before now, and schedules when it next runs.
+// Reminders that are due are delivered.
+// A job that fails is logged and skipped, so one broken job doesn't stop the others.
+// An error is only returned when jobs or reminders can't be read or saved.
+func (s *Scheduler) RunDue(now time.Time) error {
+	s.mutex.Lock()
+	guilds, err := scheduledGuilds(*s.Storage)
+	s.mutex.Unlock()
+	if err != nil {
+		slog.Error("unable to read scheduled jobs", "error", err)
+		return err
+	}
+
+	errs := []error{}
+	if err := s.deliverReminders(now); err != nil {
+		slog.Error("unable to deliver reminders", "error", err)
+		errs = append(errs, err)
+	}
+
+	for _, guild := range guilds {
+		if err := s.runGuild(guild, now); err != nil {
+			slog.Error("unable to run scheduled jobs", "guild", guild.GuildID, "error", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runGuild runs the jobs of guild that are due at now.
+func (s *Scheduler) runGuild(guild service.Guild, now time.Time) error {
+	s.mutex.Lock()
+	jobs, err := Jobs(*s.Storage, guild)
+	if err != nil {
+		s.mutex.Unlock()
+		return err
+	}
+
+	due, broken := []Job{}, 0
+	for i, job := range jobs {
+		if job.Broken != "" || job.Next.After(now) {
+			continue
+		}
+
+		next, err := s.next(job, now)
+		if err != nil {
+			// A job that can no longer be scheduled is flagged once, rather than every time it's checked.
+			slog.Warn("unable to schedule job", "guild", guild.GuildID, "job", job.ID, "error", err)
+			jobs[i].Broken = err.Error()
+			broken++
+			continue
+		}
+		jobs[i].Next = next
+		due = append(due, jobs[i])
+	}
+
+	commands := s.commands
+	if len(due) == 0 && broken == 0 {
+		s.mutex.Unlock()
+		return nil
+	}
+
+	// Jobs are saved as run before running them, so a job is never run twice for the same time.
+	err = SetJobs(*s.Storage, guild, jobs)
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, job := range due {
+		conversation := service.Conversation{
+			ServiceID:      guild.ServiceID,
+			ConversationID: job.ConversationID,
+			GuildID:        guild.GuildID,
+		}
+		if err := s.run(job, conversation, commands); err != nil {
+			slog.Warn("unable to run scheduled job", "guild", guild.GuildID, "job", job.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// deliverReminders sends every reminder due at or before now. Reminders are marked as being sent before
+// they're sent, so a reminder isn't sent twice, and are only removed once they've been sent.
+// A reminder that can't be sent is tried again later, up to maxAttempts times.
+func (s *Scheduler) deliverReminders(now time.Time) error {
+	s.mutex.Lock()
+	users, err := remindedUsers(*s.Storage)
+	s.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	errs := []error{}
+	for _, user := range users {
+		s.mutex.Lock()
+		due, err := s.claimDueReminders(user, now)
+		s.mutex.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(due) == 0 {
+			continue
+		}
+
+		sent := map[int]bool{}
+		for _, reminder := range due {
+			err := s.deliver(user, reminder)
+			if err != nil {
+				slog.Warn("unable to deliver reminder", "user", user.Name, "reminder", reminder.ID, "attempts", reminder.Attempts+1, "error", err)
+			}
+			sent[reminder.ID] = err == nil
+		}
+
+		s.mutex.Lock()
+		err = s.finishReminders(user, sent, now)
+		s.mutex.Unlock()
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// claimDueReminders marks the reminders of user that are due at or before now as being sent, returning them.
+// Reminders that are already being sent aren't returned.
+func (s *Scheduler) claimDueReminders(user service.User, now time.Time) ([]Reminder, error) {
+	reminders, err := Reminders(*s.Storage, user)
+	if err != nil {
+		return nil, err
+	}
+
+	due := []Reminder{}
+	for i, reminder := range reminders {
+		if reminder.Due.After(now) || reminder.sending(now) {
+			continue
+		}
+
+		reminders[i].Sending = now
+		due = append(due, reminders[i])
+	}
+
+	if len(due) == 0 {
+		return nil, nil
+	}
+	return due, SetReminders(*s.Storage, user, reminders)
+}
+
+// finishReminders removes the reminders of user that were sent, by ID, and schedules those that weren't to be
+// tried again, unless they've been tried maxAttempts times. Only reminders that started being sent at now are
+// changed, so a reminder cancelled while being sent, whose ID was then reused, is left alone.
+func (s *Scheduler) finishReminders(user service.User, sent map[int]bool, now time.Time) error {
+	reminders, err := Reminders(*s.Storage, user)
+	if err != nil {
+		return err
+	}
+
+	remaining := []Reminder{}
+	for _, reminder := range reminders {
+		success, ok := sent[reminder.ID]
+		if !ok || !reminder.Sending.Equal(now) {
+			remaining = append(remaining, reminder)
+			continue
+		}
+
+		if success {
+			continue
+		}
+
+		reminder.Attempts++
+		reminder.Due = now.Add(retryDelay)
+		reminder.Sending = time.Time{}
+		if reminder.Attempts < maxAttempts {
+			remaining = append(remaining, reminder)
+		}
+	}
+	return SetReminders(*s.Storage, user, remaining)
+}
+
+// deliver sends reminder to user, privately if asked to and the user's service can.
+func (s *Scheduler) deliver(user service.User, reminder Reminder) error {
+	if reminder.Direct {
+		return s.router.RouteDirect(user, reminder.Conversation, reminder.Message())
+	}
+	return s.router.Route(reminder.Conversation, reminder.Message())
+}
+
+// next returns when job next runs after now.
+func (s *Scheduler) next(job Job, now time.Time) (time.Time, error) {
+	timezone := job.Timezone
+	if timezone == "" {
+		timezone = s.Timezone
+	}
+
+	schedule, err := ParseSchedule(job.When, timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return schedule.Next(now), nil
+}
+
+// run posts the message of job to conversation, or runs its command using one of commands.
+func (s *Scheduler) run(job Job, conversation service.Conversation, commands []command.Command) error {
+	if !job.Command {
+		return s.router.Route(conversation, service.Message{Description: job.Post})
+	}
+
+	tokens := strings.Fields(job.Post)
+	if len(tokens) == 0 {
+		return fmt.Errorf("job %d has no command", job.ID)
+	}
+
+	for _, cmd := range commands {
+		if cmd.Trigger != tokens[0] {
+			continue
+		}
+
+		types := make([]string, 0, len(cmd.Parameters))
+		for _, parameter := range cmd.Parameters {
+			types = append(types, parameter.Type)
+		}
+
+		input, err := service.ParseInput(service.ParserBasic(), tokens[1:], types)
+		if err != nil {
+			return fmt.Errorf("input of %s: %w", cmd.Trigger, err)
+		}
+
+		// A command that can't be used in the conversation is explained instead of run, as it would be for a user.
+		if s.Filter != nil {
+			if reason := s.Filter(s.Storage, conversation, cmd.Trigger); reason != nil {
+				return s.router.Route(conversation, service.Message{Description: fmt.Sprintf("Scheduled job %d didn't run, as %s.", job.ID, reason)})
+			}
+		}
+
+		user := SchedulerUser
+		user.ServiceID = conversation.ServiceID
+		return cmd.Exec(conversation, user, input, s.Storage, s.router.Route)
+	}
+	return fmt.Errorf("there is no command %s", tokens[0])
+}
@@ -0,0 +1,228 @@
+package routine
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+	"github.com/google/go-cmp/cmp"
+)
+
+// echo is a command that replies with its input.
+var echo = command.Command{
+	Trigger:    "echo",
+	Parameters: []command.Parameter{{Type: "string"}},
+	Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return sink(sender, service.Message{Description: user.Name + ": " + msg[0].(string)})
+	},
+}
+
+// testScheduler returns a Scheduler that can run echo, sending to a DemoSender.
+func testScheduler() (*Scheduler, *demoservice.DemoSender) {
+	demoSender := demoservice.DemoSender{ServiceID: demoservice.ServiceID}
+
+	scheduler := Scheduler{Storage: commandtest.Storage()}
+	scheduler.AddSender(&demoSender)
+	scheduler.SetCommands([]command.Command{echo})
+	return &scheduler, &demoSender
+}
+
+// popDescriptions returns the descriptions of every message sent by demoSender.
+func popDescriptions(demoSender *demoservice.DemoSender) []string {
+	descriptions := []string{}
+	for !demoSender.IsEmpty() {
+		msg, _ := demoSender.PopMessage()
+		descriptions = append(descriptions, msg.Description)
+	}
+	return descriptions
+}
+
+func TestParseJob(t *testing.T) {
+	cases := map[string]Job{
+		"0 9 * * * | Good morning":                {When: "0 9 * * *", Post: "Good morning"},
+		"TZ=Asia/Manila @daily | echo a | b":      {When: "@daily", Timezone: "Asia/Manila", Post: "echo a | b"},
+		"  @every 1h   |   Hello  ":               {When: "@every 1h", Post: "Hello"},
+		"TZ=Asia/Manila 0 9 * * 1-5 | Magandang ": {When: "0 9 * * 1-5", Timezone: "Asia/Manila", Post: "Magandang"},
+	}
+
+	for text, expect := range cases {
+		job, err := parseJob(text)
+		if err != nil {
+			t.Errorf("%s: %s", text, err)
+		} else if diff := cmp.Diff(expect, job); diff != "" {
+			t.Errorf("Unexpected job for %q: %s", text, diff)
+		}
+	}
+
+	for _, text := range []string{"@daily", "@daily |", "| Hello"} {
+		if _, err := parseJob(text); err == nil {
+			t.Errorf("Expected %q to be rejected", text)
+		}
+	}
+}
+
+func TestRunDue(t *testing.T) {
+	scheduler, demoSender := testScheduler()
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, conversation, commandtest.User, ScheduleTrigger, "@every 1h | Hello"); !strings.HasPrefix(reply.Title, "Scheduled job 1") {
+		t.Fatalf("Expected a job to be scheduled, got %+v", reply)
+	}
+	if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, conversation, commandtest.User, ScheduleCommandTrigger, "@every 2h | echo kumusta"); !strings.HasPrefix(reply.Title, "Scheduled job 2") {
+		t.Fatalf("Expected a job to be scheduled, got %+v", reply)
+	}
+
+	now := time.Now()
+	if err := scheduler.RunDue(now); err != nil {
+		t.Fatal(err)
+	}
+	if posted := popDescriptions(demoSender); len(posted) != 0 {
+		t.Errorf("Expected nothing to be due, got %v", posted)
+	}
+
+	// Jobs missed for a while run once.
+	later := now.Add(5 * time.Hour)
+	if err := scheduler.RunDue(later); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"Hello", "Scheduler: kumusta"}, popDescriptions(demoSender)); diff != "" {
+		t.Errorf("Unexpected posts: %s", diff)
+	}
+
+	if err := scheduler.RunDue(later); err != nil {
+		t.Fatal(err)
+	}
+	if posted := popDescriptions(demoSender); len(posted) != 0 {
+		t.Errorf("Expected jobs to run once, got %v", posted)
+	}
+
+	jobs, err := Jobs(*scheduler.Storage, conversation.Guild())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, job := range jobs {
+		if !job.Next.After(later) {
+			t.Errorf("Expected job %d to be rescheduled after %s, got %s", job.ID, later, job.Next)
+		}
+	}
+}
+
+func TestRunDueFiltered(t *testing.T) {
+	scheduler, demoSender := testScheduler()
+	scheduler.Filter = func(storage *storage.Storage, conversation service.Conversation, trigger string) error {
+		return fmt.Errorf("%s is disabled in this server", trigger)
+	}
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, conversation, commandtest.User, ScheduleCommandTrigger, "@every 1h | echo kumusta")
+	if err := scheduler.RunDue(time.Now().Add(2 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"Scheduled job 1 didn't run, as echo is disabled in this server."}
+	if diff := cmp.Diff(expected, popDescriptions(demoSender)); diff != "" {
+		t.Errorf("Expected the command not to run: %s", diff)
+	}
+}
+
+// countingStorage counts how many guild values are written.
+type countingStorage struct {
+	storage.Storage
+	guildWrites int
+}
+
+func (c *countingStorage) SetGuildValue(guild service.Guild, key string, value interface{}) error {
+	c.guildWrites++
+	return c.Storage.SetGuildValue(guild, key, value)
+}
+
+func TestRunDueBrokenJob(t *testing.T) {
+	scheduler, demoSender := testScheduler()
+	counter := &countingStorage{Storage: *scheduler.Storage}
+	var store storage.Storage = counter
+	scheduler.Storage = &store
+	conversation := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	jobs := []Job{{ID: 1, ConversationID: "0", When: "@every 1h", Timezone: "Nowhere/Unknown", Post: "Hello"}}
+	if err := SetJobs(store, conversation.Guild(), jobs); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := scheduler.RunDue(now.Add(time.Duration(i) * time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if posted := popDescriptions(demoSender); len(posted) != 0 {
+		t.Errorf("Expected a broken job not to run, got %v", posted)
+	}
+
+	if counter.guildWrites != 2 {
+		t.Errorf("Expected jobs to be saved once after being added, then once when flagged, got %d writes", counter.guildWrites)
+	}
+
+	jobs, err := Jobs(store, conversation.Guild())
+	if err != nil || len(jobs) != 1 || jobs[0].Broken == "" {
+		t.Fatalf("Expected the job to be kept and flagged, got %+v %s", jobs, err)
+	}
+
+	if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, conversation, commandtest.User, SchedulesTrigger); !strings.Contains(reply.Fields[0].Value, "can't be scheduled") {
+		t.Errorf("Expected the broken job to be listed as broken, got %+v", reply)
+	}
+}
+
+func TestScheduleCommands(t *testing.T) {
+	scheduler, _ := testScheduler()
+	admin := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+	user := admin
+	user.Admin = false
+
+	for _, trigger := range []string{ScheduleTrigger, ScheduleCommandTrigger, UnscheduleTrigger} {
+		var input interface{} = "@daily | Hello"
+		if trigger == UnscheduleTrigger {
+			input = 1
+		}
+
+		if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, user, commandtest.User, trigger, input); !strings.Contains(reply.Description, "Only admins") {
+			t.Errorf("Expected %s to require an admin, got %+v", trigger, reply)
+		}
+	}
+
+	invalid := map[string]string{
+		ScheduleTrigger:        "@sometimes | Hello",
+		ScheduleCommandTrigger: "@daily | missing command",
+	}
+	for trigger, input := range invalid {
+		if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, admin, commandtest.User, trigger, input); reply.Title != "Error" {
+			t.Errorf("Expected %q to be rejected, got %+v", input, reply)
+		}
+	}
+
+	commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, admin, commandtest.User, ScheduleTrigger, "TZ=Asia/Manila 0 9 * * * | Good morning")
+	expect := service.Message{
+		Title:  "Scheduled jobs",
+		Fields: []service.MessageField{{Field: "1: TZ=Asia/Manila 0 9 * * *", Value: "Posts: Good morning"}},
+	}
+	if diff := cmp.Diff(expect, commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, user, commandtest.User, SchedulesTrigger)); diff != "" {
+		t.Errorf("Unexpected list: %s", diff)
+	}
+
+	other := admin
+	other.ConversationID = "1"
+	if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, other, commandtest.User, UnscheduleTrigger, 1); !strings.Contains(reply.Description, "no scheduled job") {
+		t.Errorf("Expected jobs of other channels not to be deleted, got %+v", reply)
+	}
+
+	commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, admin, commandtest.User, UnscheduleTrigger, 1)
+	if reply := commandtest.Reply(t, scheduler.Commands(), scheduler.Storage, admin, commandtest.User, SchedulesTrigger); len(reply.Fields) != 0 {
+		t.Errorf("Expected the job to be deleted, got %+v", reply)
+	}
+}
@@ -5,3 +5,42 @@ type Sender interface {
 	SendMessage(destination Conversation, msg Message) error
 	ID() string // Identify what service this is.
 }
+
+// A DirectSender can also send messages privately to a user.
+type DirectSender interface {
+	Sender
+	SendDirectMessage(user User, msg Message) error
+}
+
+// A Router sends messages with the Senders of each conversation's service.
+type Router struct {
+	senders []Sender
+}
+
+// AddSender will append a sender that messages are routed to.
+func (r *Router) AddSender(sender Sender) {
+	r.senders = append(r.senders, sender)
+}
+
+// Route sends msg to conversation with each Sender that has the same ID() as conversation.ServiceID.
+func (r *Router) Route(conversation Conversation, msg Message) error {
+	for _, sender := range r.senders {
+		if sender.ID() == conversation.ServiceID {
+			if err := sender.SendMessage(conversation, msg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RouteDirect sends msg privately to user if the Sender of their service is a DirectSender,
+// otherwise it's sent to conversation like Route.
+func (r *Router) RouteDirect(user User, conversation Conversation, msg Message) error {
+	for _, sender := range r.senders {
+		if direct, ok := sender.(DirectSender); ok && sender.ID() == user.ServiceID {
+			return direct.SendDirectMessage(user, msg)
+		}
+	}
+	return r.Route(conversation, msg)
+}
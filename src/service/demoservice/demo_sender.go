@@ -11,6 +11,8 @@ import (
 // PopMessage.
 type DemoSender struct {
 	ServiceID     string
+	Pages         *service.PageStore // When set, paginated messages are sent a page at a time, see PageStore.Fallback.
+	Prefix        string             // Written before the next command in the footers of pages.
 	messages      []service.Message
 	conversations []service.Conversation
 	mutex         sync.Mutex // Lock when calling any public function.
@@ -18,6 +20,10 @@ type DemoSender struct {
 
 // SendMessage saves messages to this object that can be retrieved using PopMessage.
 func (d *DemoSender) SendMessage(destination service.Conversation, message service.Message) error {
+	if d.Pages != nil {
+		message = d.Pages.Fallback(destination, message, d.Prefix)
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 	d.messages = append(d.messages, message)
@@ -25,6 +31,11 @@ func (d *DemoSender) SendMessage(destination service.Conversation, message servi
 	return nil
 }
 
+// SendDirectMessage saves messages like SendMessage, as if sent to a conversation with the ID of the user's name.
+func (d *DemoSender) SendDirectMessage(user service.User, message service.Message) error {
+	return d.SendMessage(service.Conversation{ServiceID: d.ServiceID, ConversationID: user.Name}, message)
+}
+
 // IsEmpty returns true if there are no more messages to receive.
 func (d *DemoSender) IsEmpty() bool {
 	d.mutex.Lock()
@@ -2,8 +2,10 @@ package demoservice
 
 import (
 	"strings"
+	"time"
 
 	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/session"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
 )
 
@@ -19,6 +21,7 @@ type DemoService struct {
 	commands       map[string]func(service.Conversation, service.User, []interface{}, *storage.Storage, func(service.Conversation, service.Message) error) error
 	commandTypes   map[string][]string
 	commandRouters map[string]func(service.Conversation, service.Message) error
+	continues      map[string]session.Handler
 }
 
 // Register will register an observer that will receive messages.
@@ -35,6 +38,20 @@ func (d *DemoService) Register(trigger string, commandTypes []string, exec func(
 	return nil
 }
 
+// RegisterContinue will register the handler that continues the sessions of a trigger registered using Register.
+func (d *DemoService) RegisterContinue(trigger string, handler session.Handler) {
+	if d.continues == nil {
+		d.continues = make(map[string]session.Handler)
+	}
+	d.continues[trigger] = handler
+}
+
+// continueHandler returns the handler registered for the sessions of trigger.
+func (d *DemoService) continueHandler(trigger string) (session.Handler, bool) {
+	handler, ok := d.continues[trigger]
+	return handler, ok
+}
+
 // ID returns the ID of a DemoService.
 func (d *DemoService) ID() string {
 	return d.ServiceID
@@ -55,6 +72,19 @@ func (d *DemoService) Run() {
 		user := d.users[i]
 		conversation := d.conversations[i]
 
+		// A user's session is continued by their next message, even if it starts with a trigger.
+		if active, ok := session.Get(*d.Storage, conversation, user, time.Now()); ok {
+			if router, ok := d.commandRouters[active.Trigger]; ok {
+				handled, err := session.Route(d.continueHandler, conversation, user, msg, d.Storage, router)
+				if err != nil {
+					panic(err)
+				}
+				if handled {
+					continue
+				}
+			}
+		}
+
 		tokens := strings.Split(msg, " ")
 		if len(tokens) == 0 {
 			break
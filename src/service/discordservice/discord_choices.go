@@ -0,0 +1,105 @@
+package discordservice
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/bwmarrin/discordgo"
+)
+
+// choiceButtonPrefix starts the custom ID of every button made for a choice, which is followed by the choice.
+const choiceButtonPrefix = "choice:"
+
+// Discord shows at most 5 rows of 5 buttons, labels of at most 80 characters, and custom IDs of at most 100 characters.
+const (
+	maxButtonsPerRow = 5
+	maxButtonRows    = 5
+	maxButtonLabel   = 80
+	maxCustomID      = 100
+)
+
+// choiceButtons returns a button for each choice, which is sent as the user's next message when pressed.
+// Choices that don't fit are left out, as they can still be typed. Repeated choices get one button,
+// as Discord doesn't allow buttons with the same custom ID.
+func choiceButtons(choices []string) []discordgo.MessageComponent {
+	buttons := []discordgo.MessageComponent{}
+	seen := map[string]bool{}
+	for _, choice := range choices {
+		if seen[choice] || utf8.RuneCountInString(choice) > maxButtonLabel || len(choiceButtonPrefix+choice) > maxCustomID {
+			continue
+		}
+		seen[choice] = true
+
+		buttons = append(buttons, discordgo.Button{
+			Label:    choice,
+			Style:    discordgo.PrimaryButton,
+			CustomID: choiceButtonPrefix + choice,
+		})
+	}
+
+	rows := []discordgo.MessageComponent{}
+	for row := range slices.Chunk(buttons, maxButtonsPerRow) {
+		if len(rows) == maxButtonRows {
+			break
+		}
+		rows = append(rows, discordgo.ActionsRow{Components: row})
+	}
+	return rows
+}
+
+// onChoiceButton sends the choice of a button made by choiceButtons to the session of whoever pressed it.
+func (d *DiscordSubject) onChoiceButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		slog.Error("unable to respond to choice", "error", err)
+	}
+
+	memberRoles := []string{}
+	discordUser := i.User
+	if i.Member != nil {
+		memberRoles = i.Member.Roles
+		discordUser = i.Member.User
+	}
+
+	conversation := service.Conversation{
+		ServiceID:      d.ID(),
+		ConversationID: i.ChannelID,
+		GuildID:        i.GuildID,
+		Admin:          d.isAdmin(s, discordUser.ID, i.GuildID, memberRoles),
+	}
+
+	user := service.User{
+		Name:      discordUser.ID,
+		ServiceID: d.ID(),
+	}
+
+	sink := func(destination service.Conversation, msg service.Message) error {
+		sends, err := msgToSends(msg)
+		if err != nil {
+			return err
+		}
+
+		for _, send := range sends {
+			if _, err := s.ChannelMessageSendComplex(destination.ConversationID, send); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	choice := strings.TrimPrefix(i.MessageComponentData().CustomID, choiceButtonPrefix)
+	handled, err := command.RouteToSession(d.commands(), d.sessionFilter, conversation, user, choice, d.storage, sink)
+	if err == nil && !handled {
+		err = sink(conversation, service.Message{Description: "Nothing is waiting for your answer, it may have expired."})
+	}
+
+	if err != nil {
+		slog.Error("unable to continue session", "user", user.Name, "choice", choice, "error", err)
+	}
+}
@@ -3,9 +3,10 @@ package discordservice
 import (
 	"encoding/json"
 	"errors"
-	"log"
+	"log/slog"
 	"os"
 
+	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -14,8 +15,8 @@ const ServiceID = "Discord"
 
 // DiscordConfig has data required for discord to work (e.g. Token).
 type DiscordConfig struct {
-	Token                      string
-	ChannelIDsToReportErrorsTo []string
+	Token                      string   // Token of the bot's Discord application.
+	ChannelIDsToReportErrorsTo []string // Channels that errors are sent to.
 }
 
 // getConfig reads a local json file, and returns a configuration object to load discord.
@@ -27,41 +28,41 @@ func getConfig(filepath string) (*DiscordConfig, error) {
 		example := &DiscordConfig{Token: tokenDefault}
 		bytes, err := json.Marshal(example)
 		if err != nil {
-			log.Printf("Unable to create an example json (haven't even tried creating a file yet).")
+			slog.Error("unable to create an example json (haven't even tried creating a file yet)", "error", err)
 			return nil, err
 		}
 
 		file, err := os.Create(filepath)
 		if err != nil {
-			log.Printf("Unable to create file: %s", filepath)
+			slog.Error("unable to create file", "filepath", filepath, "error", err)
 			return nil, err
 		}
 		defer file.Close()
 
 		_, err = file.Write(bytes)
 		if err != nil {
-			log.Printf("Unable to write to file: %s", filepath)
+			slog.Error("unable to write to file", "filepath", filepath, "error", err)
 			return nil, err
 		}
-		log.Printf("Wrote an example to %s", filepath)
+		slog.Info("wrote an example config", "filepath", filepath)
 		return nil, errors.New("did not exist")
 	}
 
 	bytes, err := os.ReadFile(filepath)
 	if err != nil {
-		log.Printf("Unable to read file: %s", filepath)
+		slog.Error("unable to read file", "filepath", filepath, "error", err)
 		return nil, err
 	}
 
 	var config DiscordConfig
 	err = json.Unmarshal(bytes, &config)
 	if err != nil {
-		log.Printf("Unable to unmarshal file: %s", filepath)
+		slog.Error("unable to unmarshal file", "filepath", filepath, "error", err)
 		return nil, err
 	}
 
 	if config.Token == tokenDefault {
-		log.Printf("Demo JSON has not been updated to have a valid token! A user should edit: %s", filepath)
+		slog.Error("demo json has not been updated to have a valid token, a user should edit it", "filepath", filepath)
 		return nil, errors.New("default file used")
 	}
 
@@ -76,12 +77,27 @@ func NewDiscords(filepath string) (*DiscordSubject, *DiscordSender, *discordgo.S
 		return nil, nil, nil, err
 	}
 
+	return NewDiscordsWithConfig(*config)
+}
+
+// NewDiscordsWithConfig creates subject and sender service adapters for discord,
+// using a configuration that has already been read.
+func NewDiscordsWithConfig(config DiscordConfig) (*DiscordSubject, *DiscordSender, *discordgo.Session, error) {
+	if config.Token == "" {
+		return nil, nil, nil, errors.New("discord config is missing a token")
+	}
+
 	discord, err := discordgo.New("Bot " + config.Token)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	err = discord.Open()
+	return newDiscords(discord, config)
+}
+
+// newDiscords opens discord, then creates subject and sender service adapters that use it.
+func newDiscords(discord *discordgo.Session, config DiscordConfig) (*DiscordSubject, *DiscordSender, *discordgo.Session, error) {
+	err := discord.Open()
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -89,6 +105,7 @@ func NewDiscords(filepath string) (*DiscordSubject, *DiscordSender, *discordgo.S
 	discordSubject := DiscordSubject{
 		discord:                    discord,
 		channelIDsToReportErrorsTo: config.ChannelIDsToReportErrorsTo,
+		pages:                      service.NewPageStore(service.PageExpiry),
 	}
 
 	// Register the messageCreate func as a callback for MessageCreate events.
@@ -100,5 +117,5 @@ func NewDiscords(filepath string) (*DiscordSubject, *DiscordSender, *discordgo.S
 		return nil, nil, nil, err
 	}
 
-	return &discordSubject, &DiscordSender{discord: discord}, discord, nil
+	return &discordSubject, &DiscordSender{discord: discord, subject: &discordSubject}, discord, nil
 }
@@ -0,0 +1,105 @@
+package discordservice
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/bwmarrin/discordgo"
+)
+
+// pageButtonPrefix starts the custom ID of every button that changes pages.
+const pageButtonPrefix = "page:"
+
+// pageKeyPrefix is added to the key of each message with pages, so buttons from
+// before the bot last started don't show another message's pages.
+var pageKeyPrefix = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// sendPages sends the first of pages to channelID, with buttons to change the page.
+// Pages without a footer are given footer. Pages are shown in a single embed, so their
+// images and attachments aren't sent.
+func (d *DiscordSubject) sendPages(s *discordgo.Session, channelID string, pages []service.Message, footer string) error {
+	withFooters := make([]service.Message, 0, len(pages))
+	for _, page := range pages {
+		if page.Footer == "" {
+			page.Footer = footer
+		}
+		withFooters = append(withFooters, page)
+	}
+
+	key := fmt.Sprintf("%s-%d", pageKeyPrefix, d.pageKeys.Add(1))
+	d.pages.Add(key, withFooters)
+	page, number, count, _ := d.pages.Turn(key, 0)
+
+	embed := pageEmbed(page, number, count)
+	_, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{&embed},
+		Components: pageButtons(key, number, count),
+	})
+	return err
+}
+
+// onPageButton shows another page when a button made by pageButtons is pressed.
+// If the pages have expired, the buttons are removed.
+func (d *DiscordSubject) onPageButton(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	customID := strings.TrimPrefix(i.MessageComponentData().CustomID, pageButtonPrefix)
+	key, direction, _ := strings.Cut(customID, ":")
+	offset := 1
+	if direction == "prev" {
+		offset = -1
+	}
+
+	data := &discordgo.InteractionResponseData{Components: []discordgo.MessageComponent{}}
+	page, number, count, ok := d.pages.Turn(key, offset)
+	if ok {
+		embed := pageEmbed(page, number, count)
+		data.Embeds = []*discordgo.MessageEmbed{&embed}
+		data.Components = pageButtons(key, number, count)
+	} else {
+		data.Content = "These pages have expired, send the command again to see them."
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: data,
+	})
+	if err != nil {
+		slog.Error("unable to change page", "error", err)
+	}
+}
+
+// pageEmbed returns an embed of a page, with a footer saying which page it is.
+// A page that is too long for an embed is cut short.
+func pageEmbed(page service.Message, number int, count int) discordgo.MessageEmbed {
+	label := service.PageLabel(number, count)
+	if page.Footer != "" {
+		label += ". " + page.Footer
+	}
+	page.Footer = label
+	return toEmbed(split(page)[0])
+}
+
+// pageButtons returns buttons that show the previous and next page of the message with key.
+func pageButtons(key string, number int, count int) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Prev",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageButtonPrefix + key + ":prev",
+					Disabled: number == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.SecondaryButton,
+					CustomID: pageButtonPrefix + key + ":next",
+					Disabled: number == count-1,
+				},
+			},
+		},
+	}
+}
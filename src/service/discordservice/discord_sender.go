@@ -8,13 +8,35 @@ import (
 // DiscordSender adheres to the Sender interface for discord.
 type DiscordSender struct {
 	discord *discordgo.Session
+	subject *DiscordSubject // Changes the pages of messages with pages when their buttons are pressed.
 }
 
-// SendMessage sends a message using discord.
+// SendMessage sends a message using discord. A message with pages is sent with buttons to change the page.
 func (d *DiscordSender) SendMessage(destination service.Conversation, msg service.Message) error {
-	embed := MsgToEmbed(msg)
-	_, err := d.discord.ChannelMessageSendEmbed(destination.ConversationID, &embed)
-	return err
+	if len(msg.Pages) > 1 {
+		return d.subject.sendPages(d.discord, destination.ConversationID, msg.Pages, "")
+	}
+
+	sends, err := msgToSends(msg)
+	if err != nil {
+		return err
+	}
+
+	for _, send := range sends {
+		if _, err := d.discord.ChannelMessageSendComplex(destination.ConversationID, send); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDirectMessage sends a message to a user privately, using discord.
+func (d *DiscordSender) SendDirectMessage(user service.User, msg service.Message) error {
+	channel, err := d.discord.UserChannelCreate(user.Name)
+	if err != nil {
+		return err
+	}
+	return d.SendMessage(service.Conversation{ServiceID: d.ID(), ConversationID: channel.ID}, msg)
 }
 
 // ID returns the identifier for this sender object.
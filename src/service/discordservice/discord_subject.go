@@ -1,20 +1,22 @@
 package discordservice
 
 import (
-	"bytes"
 	"context"
 	"fmt"
-	"image"
-	"image/png"
-	"log"
+	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/logging"
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
 	"github.com/bwmarrin/discordgo"
@@ -24,8 +26,14 @@ import (
 type DiscordSubject struct {
 	discord                    *discordgo.Session
 	observers                  []command.Command
+	listeners                  []command.Listener       // Receive messages that don't start with a trigger.
+	guildCommanders            []command.GuildCommander // Have commands that only some guilds have.
+	filters                    []command.Filter         // Decide whether a command can be used in a conversation.
 	storage                    *storage.Storage
 	channelIDsToReportErrorsTo []string
+	mutex                      sync.RWMutex       // Lock when reading or replacing observers or listeners.
+	pages                      *service.PageStore // Pages of messages sent with buttons to change page.
+	pageKeys                   atomic.Int64       // Used to make a key for each message with pages.
 }
 
 // SetStorage sets an object to use for storage/retrieval purposes.
@@ -40,41 +48,80 @@ func (d *DiscordSubject) updateGuildCommandsForAll() {
 	}
 }
 
-// updateGuildCommands will add the bot's commands as slash commands for a guild.
+// updateGuildCommands will make a guild's slash commands match the bot's commands.
+// Only commands that have changed are edited, new commands are created and
+// commands the bot no longer has are deleted.
 // If no guildID is provided, the slash commands are registered globally.
 func (d *DiscordSubject) updateGuildCommands(guildID string) {
 	appID := d.discord.State.User.ID
-	cmds, err := d.discord.ApplicationCommands(appID, guildID)
+	existingCmds, err := d.discord.ApplicationCommands(appID, guildID)
 	if err != nil {
-		log.Printf("Error with slash commands: %s", err)
+		slog.Error("unable to retrieve slash commands", "guild", guildID, "error", err)
+		return
+	}
+
+	existingByName := make(map[string]*discordgo.ApplicationCommand)
+	for _, existingCmd := range existingCmds {
+		existingByName[existingCmd.Name] = existingCmd
 	}
-	for _, cmd := range d.observers {
+
+	wanted := make(map[string]bool)
+	for _, cmd := range slices.Concat(d.commands(), d.guildCommands(guildID, true)) {
 		command := commandToApplicationCommand(cmd)
-		found := false
-		for _, existingCmd := range cmds {
-			if existingCmd.Name == cmd.Trigger {
-				_, err := d.discord.ApplicationCommandEdit(
-					existingCmd.ApplicationID,
-					guildID,
-					existingCmd.ID,
-					&command,
-				)
-				if err == nil {
-					found = true
-					break
-				} else {
-					log.Printf("Error with slash command for guild '%s': %s", guildID, err)
-				}
-			}
+		if wanted[command.Name] {
+			continue // A guild's command can't replace the bot's command.
 		}
+		wanted[command.Name] = true
 
+		existingCmd, found := existingByName[command.Name]
 		if !found {
 			_, err := d.discord.ApplicationCommandCreate(appID, guildID, &command)
 			if err != nil {
-				log.Printf("Error with slash command for guild '%s': %s", guildID, err)
+				slog.Error("unable to create slash command", "guild", guildID, "trigger", cmd.Trigger, "error", err)
 			}
+			continue
+		}
+
+		if applicationCommandsEqual(existingCmd, &command) {
+			continue
+		}
+
+		_, err := d.discord.ApplicationCommandEdit(existingCmd.ApplicationID, guildID, existingCmd.ID, &command)
+		if err != nil {
+			slog.Error("unable to edit slash command", "guild", guildID, "trigger", cmd.Trigger, "error", err)
+		}
+	}
+
+	for _, existingCmd := range existingCmds {
+		if wanted[existingCmd.Name] {
+			continue
+		}
+
+		err := d.discord.ApplicationCommandDelete(existingCmd.ApplicationID, guildID, existingCmd.ID)
+		if err != nil {
+			slog.Error("unable to delete slash command", "guild", guildID, "trigger", existingCmd.Name, "error", err)
+		}
+	}
+}
+
+// applicationCommandsEqual returns true if two slash commands would appear the same to a user.
+func applicationCommandsEqual(a *discordgo.ApplicationCommand, b *discordgo.ApplicationCommand) bool {
+	if a.Name != b.Name || a.Description != b.Description || len(a.Options) != len(b.Options) {
+		return false
+	}
+
+	for i := range a.Options {
+		optionA := a.Options[i]
+		optionB := b.Options[i]
+		if optionA.Type != optionB.Type ||
+			optionA.Name != optionB.Name ||
+			optionA.Description != optionB.Description ||
+			optionA.Required != optionB.Required {
+			return false
 		}
 	}
+
+	return true
 }
 
 // guildCreate executes upon joining a guild.
@@ -87,42 +134,41 @@ func (d *DiscordSubject) Load() error {
 	d.discord.AddHandler(d.guildCreate)
 	d.discord.AddHandler(d.onSlashCommand)
 
-	d.Register(
-		command.Command{
-			Trigger: "help",
-			Help:    "Provides information on how to use the bot.",
-			Exec:    d.helpExec,
-		},
-	)
+	d.Register(d.helpCommand())
 
 	d.updateGuildCommandsForAll()
 	d.updateGuildCommands("") // Global slash commands.
 	return nil
 }
 
-// UnloadUselessCommands will unload slash commands that aren't present in the bot currently.
-func (d *DiscordSubject) UnloadUselessCommands() {
-	appID := d.discord.State.User.ID
-	cmds, err := d.discord.ApplicationCommands(appID, "")
-	if err != nil {
-		log.Fatalf("Error when retrieving application commands: %s", err)
-	}
+// SetCommands atomically replaces the commands of this DiscordSubject, keeping the help command.
+// Slash commands are then updated for every guild, and globally.
+func (d *DiscordSubject) SetCommands(cmds []command.Command) {
+	observers := make([]command.Command, 0, len(cmds)+1)
+	observers = append(observers, cmds...)
+	observers = append(observers, d.helpCommand())
 
-	for _, cmd := range cmds {
-		found := false
-		for _, observer := range d.observers {
-			found = observer.Trigger == cmd.Name
-			if found {
-				break
-			}
-		}
+	d.mutex.Lock()
+	d.observers = observers
+	d.mutex.Unlock()
 
-		if !found {
-			err := d.discord.ApplicationCommandDelete(cmd.ApplicationID, "", cmd.ID)
-			if err != nil {
-				log.Fatalf("Error when deleting application command. %s", err)
-			}
-		}
+	d.updateGuildCommandsForAll()
+	d.updateGuildCommands("")
+}
+
+// commands returns the current commands, which are safe to use while commands are replaced.
+func (d *DiscordSubject) commands() []command.Command {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return d.observers
+}
+
+// helpCommand returns a command that lists every other command.
+func (d *DiscordSubject) helpCommand() command.Command {
+	return command.Command{
+		Trigger: "help",
+		Help:    "Provides information on how to use the bot.",
+		Exec:    d.helpExec,
 	}
 }
 
@@ -161,9 +207,86 @@ func commandToApplicationCommand(cmd command.Command) discordgo.ApplicationComma
 
 // Register will add an observer that will handle discord messages being received.
 func (d *DiscordSubject) Register(cmd command.Command) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
 	d.observers = append(d.observers, cmd)
 }
 
+// AddGuildCommander adds commands that only some guilds have, such as commands added by a guild's admins.
+// Use before Load, so that they're added as slash commands.
+func (d *DiscordSubject) AddGuildCommander(guildCommander command.GuildCommander) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.guildCommanders = append(d.guildCommanders, guildCommander)
+}
+
+// guildCommands returns the commands of guildID from every GuildCommander, or only those that are slash commands.
+// Direct messages and global slash commands, which have no guild, have none.
+func (d *DiscordSubject) guildCommands(guildID string, slash bool) []command.Command {
+	if guildID == "" || d.storage == nil {
+		return nil
+	}
+
+	d.mutex.RLock()
+	guildCommanders := d.guildCommanders
+	d.mutex.RUnlock()
+
+	guild := service.Guild{ServiceID: d.ID(), GuildID: guildID}
+	commands := []command.Command{}
+	for _, guildCommander := range guildCommanders {
+		if slash {
+			commands = append(commands, guildCommander.SlashCommands(d.storage, guild)...)
+		} else {
+			commands = append(commands, guildCommander.GuildCommands(d.storage, guild)...)
+		}
+	}
+	return commands
+}
+
+// UpdateGuild makes the slash commands of guild match the bot's commands and the guild's commands,
+// such as after the guild's commands change. Guilds of other services are ignored.
+func (d *DiscordSubject) UpdateGuild(guild service.Guild) {
+	if guild.ServiceID == d.ID() && guild.GuildID != "" {
+		d.updateGuildCommands(guild.GuildID)
+	}
+}
+
+// AddFilter adds a filter that decides whether a command can be used in a conversation. A command is only
+// executed, and listed by help, if every filter allows it.
+func (d *DiscordSubject) AddFilter(filter command.Filter) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.filters = append(d.filters, filter)
+}
+
+// filter returns an error that explains why the command with trigger can't be used in conversation,
+// or nil if every filter allows it.
+func (d *DiscordSubject) filter(conversation service.Conversation, trigger string) error {
+	d.mutex.RLock()
+	filters := d.filters
+	d.mutex.RUnlock()
+
+	for _, filter := range filters {
+		if err := filter(d.storage, conversation, trigger); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sessionFilter is a command.Filter that uses the filters added with AddFilter, so that a session
+// doesn't continue once its command can't be used.
+func (d *DiscordSubject) sessionFilter(_ *storage.Storage, conversation service.Conversation, trigger string) error {
+	return d.filter(conversation, trigger)
+}
+
+// AddListener adds a listener that receives messages that don't start with the trigger of a command.
+func (d *DiscordSubject) AddListener(listener command.Listener) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.listeners = append(d.listeners, listener)
+}
+
 // ID returns the discord service ID, this is the same for all DiscordSubject objects.
 func (*DiscordSubject) ID() string {
 	return ServiceID
@@ -175,7 +298,11 @@ func (d *DiscordSubject) Close() {
 }
 
 func (d *DiscordSubject) messageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate) {
-	d.onMessage(s, m.Message)
+	// Updates that don't change the content, such as when links are unfurled, are ignored.
+	if m.BeforeUpdate != nil && m.BeforeUpdate.Content == m.Content {
+		return
+	}
+	d.onMessage(s, m.Message, true)
 }
 
 func (d *DiscordSubject) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -185,10 +312,24 @@ func (d *DiscordSubject) messageCreate(s *discordgo.Session, m *discordgo.Messag
 			m.Content = strings.Join([]string{m.Content, msg.Content}, " ")
 		}
 	}
-	d.onMessage(s, m.Message)
+	d.onMessage(s, m.Message, false)
 }
 
 func (d *DiscordSubject) onSlashCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, pageButtonPrefix) {
+		d.onPageButton(s, i)
+		return
+	}
+
+	if i.Type == discordgo.InteractionMessageComponent && strings.HasPrefix(i.MessageComponentData().CustomID, choiceButtonPrefix) {
+		d.onChoiceButton(s, i)
+		return
+	}
+
+	if i.Type != discordgo.InteractionApplicationCommand {
+		return
+	}
+
 	memberRoles := []string{}
 	if i.Member != nil {
 		memberRoles = i.Member.Roles
@@ -235,6 +376,7 @@ func (d *DiscordSubject) onSlashCommand(s *discordgo.Session, i *discordgo.Inter
 	}
 
 	embeds := &([]*discordgo.MessageEmbed{})
+	responseLength := 0
 
 	sink := func(conversation service.Conversation, msg service.Message) error {
 		_, spanSend := tracer.Start(ctx, "SendMessage",
@@ -246,45 +388,86 @@ func (d *DiscordSubject) onSlashCommand(s *discordgo.Session, i *discordgo.Inter
 		)
 		defer spanSend.End()
 
-		embed := MsgToEmbed(msg)
-		embed.Footer = &discordgo.MessageEmbedFooter{Text: footerText}
-		currEmbeds := append(*embeds, &embed)
-		embeds = &currEmbeds
-		whitespace := " "
-		response := discordgo.WebhookEdit{
-			Content: &whitespace,
-			Embeds:  embeds,
+		// A response can't be changed by its buttons, so pages follow it.
+		if len(msg.Pages) > 1 {
+			if err := d.sendPages(s, i.ChannelID, msg.Pages, footerText); err != nil {
+				d.handleInteractionError(i, "error when sending pages", err)
+			}
+			return nil
 		}
 
-		_, err := s.InteractionResponseEdit(i.Interaction, &response)
-		if err != nil {
-			d.handleInteractionError(i, "error when editing", err)
-			return nil
+		if msg.Footer == "" {
+			msg.Footer = footerText
 		}
 
-		if msg.Image != nil {
-			err := d.SendImage(msg.Image, i.ChannelID, s, &discordgo.MessageEmbed{})
+		for _, part := range split(msg) {
+			// The response has the limits of a message, so parts that don't fit follow it.
+			if len(*embeds) == maxEmbeds || responseLength+part.Length() > Limits.Total {
+				sends, err := partToSends(part)
+				if err != nil {
+					d.handleInteractionError(i, "error when encoding files", err)
+					return nil
+				}
+
+				for _, send := range sends {
+					if _, err := s.ChannelMessageSendComplex(i.ChannelID, send); err != nil {
+						d.handleInteractionError(i, "error when sending message", err)
+					}
+				}
+				continue
+			}
+
+			embed := toEmbed(part)
+			currEmbeds := append(*embeds, &embed)
+			embeds = &currEmbeds
+			responseLength += part.Length()
+			whitespace := " "
+			response := discordgo.WebhookEdit{
+				Content: &whitespace,
+				Embeds:  embeds,
+			}
+			if len(msg.Choices) > 0 {
+				components := choiceButtons(msg.Choices)
+				response.Components = &components
+			}
 
+			_, err := s.InteractionResponseEdit(i.Interaction, &response)
 			if err != nil {
-				d.handleInteractionError(i, "error when sending image", err)
+				d.handleInteractionError(i, "error when editing", err)
+				return nil
 			}
 
-			return nil
-		}
+			// Files can't be added to the response once it is deferred, so they follow it.
+			imageEmbeds, files, err := msgToFiles(part)
+			if err != nil {
+				d.handleInteractionError(i, "error when encoding files", err)
+				return nil
+			}
 
+			if len(files) > 0 {
+				_, err := s.ChannelMessageSendComplex(i.ChannelID, &discordgo.MessageSend{Embeds: imageEmbeds, Files: files})
+				if err != nil {
+					d.handleInteractionError(i, "error when sending files", err)
+				}
+			}
+		}
 		return nil
 	}
 
-	for j := range d.observers {
-		if d.observers[j].Trigger == target {
-			_, spanCmd := tracer.Start(ctx, "SlashCommandExec",
+	// The bot's commands are first, so a guild's command with the same trigger is ignored.
+	observers := slices.Concat(d.commands(), d.guildCommands(i.GuildID, true))
+	for j := range observers {
+		if observers[j].Trigger == target {
+			cmdCtx, spanCmd := tracer.Start(ctx, "SlashCommandExec",
 				trace.WithAttributes(
-					attribute.String("command", d.observers[j].Trigger),
+					attribute.String("command", observers[j].Trigger),
 					attribute.String("user.id", user.Name),
 					attribute.String("guild.id", conversation.GuildID),
 				),
 			)
 			defer spanCmd.End()
+			logger := logging.CommandLogger(cmdCtx, slog.Default(), conversation, user, observers[j].Trigger)
+			start := time.Now()
 
 			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 				Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
@@ -293,7 +476,14 @@ func (d *DiscordSubject) onSlashCommand(s *discordgo.Session, i *discordgo.Inter
 				d.handleInteractionError(i, "responding to interaction", err)
 			}
 
-			err = d.observers[j].Exec(conversation, user, input, d.storage, sink)
+			// A command that can't be used here is explained instead of executed.
+			if reason := d.filter(conversation, target); reason != nil {
+				logger.Info("command not allowed", "reason", reason)
+				err = sink(conversation, service.Message{Description: reason.Error() + "."})
+			} else {
+				err = observers[j].Exec(conversation, user, input, d.storage, sink)
+				logCommand(logger, start, err)
+			}
 			if err != nil {
 				d.handleInteractionError(i, "executing command", err)
 			}
@@ -309,41 +499,9 @@ func (d *DiscordSubject) onSlashCommand(s *discordgo.Session, i *discordgo.Inter
 	}
 }
 
-// SendImage sends an embed with an image to channelID.“
-func (d *DiscordSubject) SendImage(image image.Image, channelID string, s *discordgo.Session, embed *discordgo.MessageEmbed) error {
-	var buffer bytes.Buffer
-	err := png.Encode(&buffer, image)
-	if err != nil {
-		return fmt.Errorf("Error when encoding png: %s", err)
-	}
-
-	filename := "filename.png"
-	embed.Image = &discordgo.MessageEmbedImage{
-		URL: "attachment://" + filename,
-	}
-
-	_, err = s.ChannelMessageSendComplex(
-		channelID,
-		&discordgo.MessageSend{
-			Embed: embed,
-			Files: []*discordgo.File{
-				{
-					Name:        filename,
-					Reader:      &buffer,
-					ContentType: "image/png",
-				},
-			},
-		},
-	)
-
-	if err != nil {
-		return fmt.Errorf("Error when sending message with image: %s", err)
-	}
-
-	return nil
-}
-
-func (d *DiscordSubject) onMessage(s *discordgo.Session, m *discordgo.Message) {
+// onMessage runs the command that m starts with. Otherwise m continues its author's session, or is
+// given to listeners, unless it was edited, so that an edit isn't taken as an answer.
+func (d *DiscordSubject) onMessage(s *discordgo.Session, m *discordgo.Message, edited bool) {
 	if m.Author == nil || m.Author.ID == s.State.User.ID {
 		return
 	}
@@ -376,49 +534,31 @@ func (d *DiscordSubject) onMessage(s *discordgo.Session, m *discordgo.Message) {
 		)
 		defer spanSend.End()
 
-		fields := make([]*discordgo.MessageEmbedField, 0)
-		for _, field := range msg.Fields {
-			value := field.Value
-			if field.URL != "" {
-				value += fmt.Sprintf("\nRead more at: %s", field.URL)
+		footerText := "Requested by " + m.Author.Username + ": " + m.Content
+		if len(msg.Pages) > 1 {
+			if err := d.sendPages(s, destination.ConversationID, msg.Pages, footerText); err != nil {
+				d.handleMessageError(m, "error when sending pages", err)
 			}
-			fields = append(
-				fields,
-				&discordgo.MessageEmbedField{
-					Name:   field.Field,
-					Value:  value,
-					Inline: field.Inline,
-				})
-		}
-
-		desc := msg.Description
-		if msg.URL != "" {
-			desc += fmt.Sprintf("\nRead more at: %s", msg.URL)
+			return nil
 		}
 
-		embed := discordgo.MessageEmbed{
-			URL:         msg.URL,
-			Title:       msg.Title,
-			Description: desc,
-			Fields:      fields,
+		if msg.Footer == "" {
+			msg.Footer = footerText
 		}
 
-		embed.Footer = &discordgo.MessageEmbedFooter{
-			Text: "Requested by " + m.Author.Username + ": " + m.Content,
+		sends, err := msgToSends(msg)
+		if err != nil {
+			d.handleMessageError(m, "error when encoding files", err)
+			return nil
 		}
 
-		if msg.Image != nil {
-			err := d.SendImage(msg.Image, destination.ConversationID, s, &embed)
+		for _, send := range sends {
+			_, err = d.discord.ChannelMessageSendComplex(destination.ConversationID, send)
 			if err != nil {
-				d.handleMessageError(m, "error when sending image", err)
+				d.handleMessageError(m, "error when sending message response", err)
 			}
 		}
 
-		_, err := d.discord.ChannelMessageSendEmbed(destination.ConversationID, &embed)
-		if err != nil {
-			d.handleMessageError(m, "error when sending message response", err)
-		}
-
 		return nil
 	}
 
@@ -427,38 +567,109 @@ func (d *DiscordSubject) onMessage(s *discordgo.Session, m *discordgo.Message) {
 
 	prefix, ok := (*d.storage).GetGuildValue(conversation.Guild(), "prefix")
 	if !ok {
-		d.handleMessageError(m, "guild prefix was not found, nor was a default, exiting", nil)
+		d.handleMessageError(m, "guild prefix was not found, nor was a default", nil)
 		return
 	}
 
-	for j := range d.observers {
-		trigger := fmt.Sprintf("%s%s", prefix, d.observers[j].Trigger)
-		if trigger == target {
-			_, spanCmd := tracer.Start(context.Background(), "CommandExec",
-				trace.WithAttributes(
-					attribute.String("command", d.observers[j].Trigger),
-				),
-			)
-			parsers := parserDiscord()
-			parameters := []string{}
-			for _, parameter := range d.observers[j].Parameters {
-				parameters = append(parameters, parameter.Type)
+	// A user's session is continued by their next message, even if it starts with a trigger.
+	if !edited {
+		handled, err := command.RouteToSession(d.commands(), d.sessionFilter, conversation, user, m.Content, d.storage, sink)
+		if err != nil {
+			slog.Error("unable to continue session", "user", user.Name, "error", err)
+			if err := sink(conversation, service.Message{Title: "Error", Description: "Your reply couldn't be used, try again."}); err != nil {
+				slog.Error("unable to report session error", "user", user.Name, "error", err)
 			}
+		}
+		if handled {
+			return
+		}
+	}
 
-			input, err := service.ParseInput(parsers, inputSplit[1:], parameters)
-			if err != nil {
-				d.handleMessageError(m, "error when parsing input", err)
+	execute := func(cmd command.Command) {
+		ctx, spanCmd := tracer.Start(context.Background(), "CommandExec",
+			trace.WithAttributes(
+				attribute.String("command", cmd.Trigger),
+			),
+		)
+		defer spanCmd.End()
+		logger := logging.CommandLogger(ctx, slog.Default(), conversation, user, cmd.Trigger)
+		// A command that can't be used here is explained instead of executed.
+		if reason := d.filter(conversation, cmd.Trigger); reason != nil {
+			logger.Info("command not allowed", "reason", reason)
+			if err := sink(conversation, service.Message{Description: reason.Error() + "."}); err != nil {
+				d.handleMessageError(m, "error when explaining why a command isn't allowed", err)
 			}
+			return
+		}
 
-			err = d.observers[j].Exec(conversation, user, input, d.storage, sink)
-			if err != nil {
-				d.handleMessageError(m, "error when executing command", err)
+		start := time.Now()
+		parsers := parserDiscord()
+		parameters := []string{}
+		for _, parameter := range cmd.Parameters {
+			parameters = append(parameters, parameter.Type)
+		}
+
+		input, err := service.ParseInput(parsers, inputSplit[1:], parameters)
+		if err != nil {
+			// Input that doesn't suit the command's parameters is answered with how to use it.
+			logger.Info("unable to parse input", "error", err)
+			usage := strings.TrimSpace(fmt.Sprintf("%s%s %s", prefix, cmd.Trigger, cmd.HelpInput))
+			if err := sink(conversation, service.Message{Title: "Error", Description: "Usage: " + usage}); err != nil {
+				d.handleMessageError(m, "error when explaining usage", err)
 			}
-			spanCmd.End()
+			return
+		}
+
+		err = cmd.Exec(conversation, user, input, d.storage, sink)
+		logCommand(logger, start, err)
+		if err != nil {
+			d.handleMessageError(m, "error when executing command", err)
+		}
+	}
+
+	matched := false
+	for _, cmd := range d.commands() {
+		if fmt.Sprintf("%s%s", prefix, cmd.Trigger) == target {
+			matched = true
+			execute(cmd)
+		}
+	}
+
+	if matched {
+		return
+	}
+
+	// A guild's commands are matched after the bot's commands, so they can't replace them.
+	for _, cmd := range d.guildCommands(m.GuildID, false) {
+		if fmt.Sprintf("%s%s", prefix, cmd.Trigger) == target {
+			execute(cmd)
+			return
+		}
+	}
+
+	if edited {
+		return
+	}
+
+	d.mutex.RLock()
+	listeners := d.listeners
+	d.mutex.RUnlock()
+	for _, listener := range listeners {
+		if err := listener(conversation, user, m.Content, d.storage, sink); err != nil {
+			d.handleMessageError(m, "error when listening to message", err)
 		}
 	}
 }
 
+// logCommand logs the outcome of executing a command that started at start.
+func logCommand(logger *slog.Logger, start time.Time, err error) {
+	if err != nil {
+		logger.Error("command failed", "duration", time.Since(start), "error", err)
+		return
+	}
+	logger.Info("command executed", "duration", time.Since(start))
+}
+
 func parserDiscord() service.Parser {
 	parser := service.ParserBasic()
 	snipID := func(input string) (interface{}, error) {
@@ -479,9 +690,14 @@ func (d *DiscordSubject) isAdmin(s *discordgo.Session, authorID string, guildID
 		GuildID:   "",
 	}
 
+	// Without the guild, only admins in storage are known.
+	var guildRoles []*discordgo.Role
 	discordGuild, err := s.Guild(guildID)
-	if err == nil && discordGuild.OwnerID == authorID {
-		return true
+	if err == nil {
+		if discordGuild.OwnerID == authorID {
+			return true
+		}
+		guildRoles = discordGuild.Roles
 	}
 
 	guild.GuildID = guildID
@@ -491,7 +707,7 @@ func (d *DiscordSubject) isAdmin(s *discordgo.Session, authorID string, guildID
 	}
 
 	for _, role := range roles {
-		for _, guildRole := range discordGuild.Roles {
+		for _, guildRole := range guildRoles {
 			if role != guildRole.ID {
 				continue
 			}
@@ -525,11 +741,16 @@ func (d *DiscordSubject) helpExec(conversation service.Conversation, user servic
 		prefix = ""
 	}
 
-	for i, command := range d.observers {
+	for _, command := range d.commands() {
+		// Commands that can't be used here, such as those disabled by a guild, are hidden.
+		if d.filter(conversation, command.Trigger) != nil {
+			continue
+		}
+
 		fields = append(fields, service.MessageField{
 			Field: fmt.Sprintf(
 				"%s. %s%s %s",
-				strconv.Itoa(i+1),
+				strconv.Itoa(len(fields)+1),
 				prefix,
 				command.Trigger,
 				command.HelpInput,
@@ -543,23 +764,14 @@ func (d *DiscordSubject) helpExec(conversation service.Conversation, user servic
 		Value: command.Repo,
 	})
 
-	const batchSize = 25
-	for i := 0; i < len(fields); i += batchSize {
-		batch := fields[i:min(i+batchSize, len(fields))]
-		err := sink(
-			conversation,
-			service.Message{
-				Title:  "Help",
-				Fields: batch,
-			},
-		)
-
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	// Messages that are too long are split by the sink.
+	return sink(
+		conversation,
+		service.Message{
+			Title:  "Help",
+			Fields: fields,
+		},
+	)
 }
 
 func (d *DiscordSubject) handleMessageError(m *discordgo.Message, event string, err error) {
@@ -577,14 +789,12 @@ func (d *DiscordSubject) handleInteractionError(i *discordgo.InteractionCreate,
 
 func (d *DiscordSubject) handleError(username string, fullMessage string, event string, err error) {
 	report := fmt.Sprintf("Error when executing discord message: %s. User was: %s. Error was: %s. Error occured when: %s", fullMessage, username, err, event)
-	log.Println(report)
+	slog.Error("error when executing discord message", "message", fullMessage, "user", username, "event", event, "error", err)
 
 	for _, channelID := range d.channelIDsToReportErrorsTo {
 		_, err := d.discord.ChannelMessageSend(channelID, report)
 		if err != nil {
-			log.Printf("Error when reporting error to channel %s: %s", channelID, report)
+			slog.Error("unable to report error to channel", "channel", channelID, "report", report, "error", err)
 		}
 	}
-
-	log.Fatal(report)
 }
@@ -0,0 +1,932 @@
+package discordservice
+
+import (
+	"fmt"
+	"image"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/discordservice/discordtest"
+	"github.com/BKrajancic/boby/m/v2/src/session"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+const (
+	testGuildID   = "1"
+	testChannelID = "2"
+	ownerID       = "3"
+	timeout       = 2 * time.Second
+)
+
+// testGuild returns a guild with a role that can manage the server, and a role that can't.
+func testGuild() *discordgo.Guild {
+	return &discordgo.Guild{
+		ID:      testGuildID,
+		Name:    "guild",
+		OwnerID: ownerID,
+		Roles: []*discordgo.Role{
+			{ID: "10", Name: "manager", Permissions: discordgo.PermissionManageServer},
+			{ID: "11", Name: "member", Permissions: discordgo.PermissionSendMessages},
+		},
+	}
+}
+
+// member returns a member of the test guild with roles.
+func member(id string, roles ...string) *discordgo.Member {
+	return &discordgo.Member{
+		GuildID: testGuildID,
+		User:    &discordgo.User{ID: id, Username: "user" + id},
+		Nick:    "nick" + id,
+		Roles:   roles,
+	}
+}
+
+// echoCommand replies with its input, and whether the sender is an admin.
+func echoCommand() command.Command {
+	return command.Command{
+		Trigger:    "echo",
+		Parameters: []command.Parameter{{Type: "string", Name: "text", Description: "Text to echo."}},
+		Help:       "Replies with text.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return sink(sender, service.Message{
+				Title:       "Echo",
+				Description: fmt.Sprint(msg...),
+				Fields:      []service.MessageField{{Field: "admin", Value: fmt.Sprint(sender.Admin)}},
+			})
+		},
+	}
+}
+
+// silentCommand never replies.
+func silentCommand() command.Command {
+	return command.Command{
+		Trigger: "silent",
+		Help:    "Does nothing.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return nil
+		},
+	}
+}
+
+// imageCommand replies with an image.
+func imageCommand() command.Command {
+	return command.Command{
+		Trigger: "image",
+		Help:    "Replies with an image.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return sink(sender, service.Message{
+				Title:       "Image",
+				Footer:      "Footer",
+				Image:       image.NewRGBA(image.Rect(0, 0, 2, 2)),
+				Attachments: []service.Attachment{{Name: "notes.txt", ContentType: "text/plain", Data: []byte("notes")}},
+			})
+		},
+	}
+}
+
+// pagesCommand replies with three pages.
+func pagesCommand() command.Command {
+	return command.Command{
+		Trigger: "pages",
+		Help:    "Replies with pages.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return sink(sender, service.Paginate([]service.Message{{Title: "One"}, {Title: "Two"}, {Title: "Three", Footer: "Last"}}))
+		},
+	}
+}
+
+// askCommand asks for a color, and replies with the user's next message, or fails if it's "fail".
+func askCommand() command.Command {
+	return command.Command{
+		Trigger: "ask",
+		Help:    "Asks for a color.",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			if err := session.Start(*storage, sender, user, session.Session{Trigger: "ask"}); err != nil {
+				return err
+			}
+			return sink(sender, service.Message{Title: "Pick a color", Choices: []string{"red", "blue"}})
+		},
+		Continue: func(_ session.Session, sender service.Conversation, user service.User, text string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			if text == "fail" {
+				return fmt.Errorf("unable to pick")
+			}
+			return sink(sender, service.Message{Title: "Picked", Description: text})
+		},
+	}
+}
+
+// guildCommander gives the test guild a "faq" command that is also a slash command, and an "echo"
+// command that should be ignored as the bot has one.
+type guildCommander struct{}
+
+func (guildCommander) GuildCommands(store *storage.Storage, guild service.Guild) []command.Command {
+	if guild.GuildID != testGuildID {
+		return nil
+	}
+
+	echo := echoCommand()
+	echo.Exec = func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return sink(sender, service.Message{Title: "Guild echo"})
+	}
+	return append(guildCommander{}.SlashCommands(store, guild), echo)
+}
+
+func (guildCommander) SlashCommands(storage *storage.Storage, guild service.Guild) []command.Command {
+	if guild.GuildID != testGuildID {
+		return nil
+	}
+
+	faq := echoCommand()
+	faq.Trigger, faq.Parameters, faq.Help = "faq", nil, "Replies with the FAQ."
+	return []command.Command{faq}
+}
+
+// startBot connects a DiscordSubject with commands to a fake Discord.
+func startBot(t *testing.T, server *discordtest.Server, commands ...command.Command) (*DiscordSubject, *storage.Storage) {
+	session, err := server.Session("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Events are handled in order, so once the reply to a message is sent, earlier messages have been handled.
+	session.SyncEvents = true
+
+	discordSubject, _, _, err := newDiscords(session, DiscordConfig{Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(discordSubject.Close)
+
+	tempStorage := storage.GetTempStorage()
+	var _storage storage.Storage = &tempStorage
+	if err := _storage.SetDefaultGuildValue("prefix", "!"); err != nil {
+		t.Fatal(err)
+	}
+	discordSubject.SetStorage(&_storage)
+
+	for _, cmd := range commands {
+		discordSubject.Register(cmd)
+	}
+
+	if err := discordSubject.Load(); err != nil {
+		t.Fatal(err)
+	}
+	return discordSubject, &_storage
+}
+
+func newServer(t *testing.T) *discordtest.Server {
+	server := discordtest.NewServer(testGuild())
+	t.Cleanup(server.Close)
+	return server
+}
+
+// commandNames returns the sorted names of application commands.
+func commandNames(commands []*discordgo.ApplicationCommand) []string {
+	names := []string{}
+	for _, cmd := range commands {
+		names = append(names, cmd.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// waitForSent waits until count messages have been sent, then returns them.
+func waitForSent(t *testing.T, server *discordtest.Server, count int) []discordtest.SentMessage {
+	if !discordtest.WaitFor(timeout, func() bool { return len(server.Sent()) >= count }) {
+		t.Fatalf("Expected %d sent messages, got %d", count, len(server.Sent()))
+	}
+	return server.Sent()
+}
+
+func TestSlashCommandsSynced(t *testing.T) {
+	server := newServer(t)
+	unchanged := commandToApplicationCommand(silentCommand())
+	server.SetCommands(testGuildID, []*discordgo.ApplicationCommand{
+		{Name: "stale", Description: "No longer a command."},
+		&unchanged,
+		{Name: "echo", Description: "An old description."},
+	})
+
+	startBot(t, server, echoCommand(), silentCommand())
+
+	expect := []string{"echo", "help", "silent"}
+	if diff := cmp.Diff(expect, commandNames(server.Commands(testGuildID))); diff != "" {
+		t.Errorf("Unexpected guild commands: %s", diff)
+	}
+
+	if diff := cmp.Diff(expect, commandNames(server.Commands(""))); diff != "" {
+		t.Errorf("Unexpected global commands: %s", diff)
+	}
+
+	for _, cmd := range server.Commands(testGuildID) {
+		if cmd.Name == "echo" && cmd.Description != "Replies with text." {
+			t.Errorf("Expected echo to be edited, got %q", cmd.Description)
+		}
+	}
+
+	for _, request := range server.Requests() {
+		if request.Method == "PATCH" && strings.HasSuffix(request.Path, unchanged.ID) {
+			t.Errorf("Expected an unchanged command not to be edited")
+		}
+	}
+}
+
+func TestSetCommands(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, echoCommand())
+
+	discordSubject.SetCommands([]command.Command{silentCommand()})
+	if diff := cmp.Diff([]string{"help", "silent"}, commandNames(server.Commands(testGuildID))); diff != "" {
+		t.Errorf("Unexpected guild commands: %s", diff)
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!help", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Title != "Help" {
+		t.Errorf("Expected a removed command to be ignored, got %+v", sent[0].Embeds[0])
+	}
+}
+
+func TestJoinGuildSyncsCommands(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	if err := server.JoinGuild(&discordgo.Guild{ID: "50", Name: "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !discordtest.WaitFor(timeout, func() bool { return len(server.Commands("50")) == 2 }) {
+		t.Errorf("Expected commands to be created in a joined guild, got %v", commandNames(server.Commands("50")))
+	}
+}
+
+func TestMessageCommand(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	embed := sent[0].Embeds[0]
+	if sent[0].ChannelID != testChannelID || embed.Title != "Echo" || embed.Description != "hello" {
+		t.Errorf("Unexpected reply %+v", embed)
+	}
+
+	if embed.Footer == nil || embed.Footer.Text != "Requested by user20: !echo hello" {
+		t.Errorf("Unexpected footer %+v", embed.Footer)
+	}
+}
+
+func TestMessageReply(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	original := server.AddMessage(testChannelID, "world")
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo", original); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Description != "world" {
+		t.Errorf("Expected the replied to message to be used as input, got %q", sent[0].Embeds[0].Description)
+	}
+}
+
+func TestMessageFromBotIgnored(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member(discordtest.BotID), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo after", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages are handled in order, so the bot's message was ignored if the first reply is to the user.
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Description != "after" {
+		t.Errorf("Expected only the user's message to get a reply, got %d replies", len(sent))
+	}
+}
+
+func TestMessageCommandError(t *testing.T) {
+	server := newServer(t)
+	failing := command.Command{
+		Trigger: "fail",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return fmt.Errorf("failed")
+		},
+	}
+	startBot(t, server, failing, echoCommand())
+
+	// A command that fails is logged, and the bot keeps running.
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!fail", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo after", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Description != "after" {
+		t.Errorf("Expected the next command to get a reply, got %+v", sent[0].Embeds[0])
+	}
+}
+
+func TestMessageCommandUsage(t *testing.T) {
+	server := newServer(t)
+	cancel := command.Command{
+		Trigger:    "cancel",
+		Parameters: []command.Parameter{{Type: "int"}},
+		HelpInput:  "<id>",
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			return sink(sender, service.Message{Description: fmt.Sprint(msg[0].(int))})
+		},
+	}
+	startBot(t, server, cancel)
+
+	for i, input := range []string{"!cancel abc", "!cancel"} {
+		if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), input, nil); err != nil {
+			t.Fatal(err)
+		}
+		sent := waitForSent(t, server, i+1)
+		if embed := sent[i].Embeds[0]; embed.Title != "Error" || embed.Description != "Usage: !cancel <id>" {
+			t.Errorf("Expected %q to be answered with the command's usage, got %+v", input, embed)
+		}
+	}
+}
+
+func TestMessageListener(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, echoCommand())
+	discordSubject.AddListener(func(sender service.Conversation, user service.User, msg string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return sink(sender, service.Message{Title: "Heard", Description: msg})
+	})
+
+	for _, content := range []string{"!echo command", "just chatting"} {
+		if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), content, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Messages are handled in order, so the command would have been heard before the second reply.
+	replies := []string{}
+	for _, sent := range waitForSent(t, server, 2)[:2] {
+		replies = append(replies, sent.Embeds[0].Title+": "+sent.Embeds[0].Description)
+	}
+	if diff := cmp.Diff([]string{"Echo: command", "Heard: just chatting"}, replies); diff != "" {
+		t.Errorf("Expected only the message that isn't a command to be heard (-want +got):\n%s", diff)
+	}
+}
+
+func TestMessageSession(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand(), askCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!ask", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 1)
+	if diff := cmp.Diff(map[string]bool{"red": false, "blue": false}, buttons(sent[0].Components)); diff != "" {
+		t.Errorf("Unexpected buttons: %s", diff)
+	}
+
+	// The next message is an answer, even if it starts with a trigger.
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo green", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent = waitForSent(t, server, 2)
+	if sent[1].Embeds[0].Title != "Picked" || sent[1].Embeds[0].Description != "!echo green" {
+		t.Errorf("Expected the message to be sent to the session, got %+v", sent[1].Embeds[0])
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent = waitForSent(t, server, 3)
+	if sent[2].Embeds[0].Title != "Echo" {
+		t.Errorf("Expected the session to have ended, got %+v", sent[2].Embeds[0])
+	}
+}
+
+func TestEditedMessageSession(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, askCommand())
+
+	ask, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!ask", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForSent(t, server, 1)
+
+	// An edit isn't an answer, so the session receives the next message.
+	if _, err := server.EditMessage(ask.ID, "red"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "blue", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 2)
+	if sent[1].Embeds[0].Description != "blue" {
+		t.Errorf("Expected the new message to be the answer, got %+v", sent[1].Embeds[0])
+	}
+}
+
+func TestMessageSessionError(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, askCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!ask", nil); err != nil {
+		t.Fatal(err)
+	}
+	waitForSent(t, server, 1)
+
+	// A session that fails is reported to the user, and the bot keeps running.
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "fail", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 2)
+	if sent[1].Embeds[0].Title != "Error" {
+		t.Errorf("Expected the error to be reported, got %+v", sent[1].Embeds[0])
+	}
+}
+
+func TestChoiceButton(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, askCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!ask", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 1)
+
+	if _, err := server.PressButton(testGuildID, member("20"), sent[0].ID, customID(sent[0].Components, "blue")); err != nil {
+		t.Fatal(err)
+	}
+	sent = waitForSent(t, server, 2)
+	if sent[1].Embeds[0].Title != "Picked" || sent[1].Embeds[0].Description != "blue" {
+		t.Errorf("Expected the choice to be sent to the session, got %+v", sent[1].Embeds[0])
+	}
+
+	// Another press has nothing to answer, as the session has ended.
+	if _, err := server.PressButton(testGuildID, member("20"), sent[0].ID, customID(sent[0].Components, "red")); err != nil {
+		t.Fatal(err)
+	}
+	sent = waitForSent(t, server, 3)
+	if !strings.Contains(sent[2].Embeds[0].Description, "expired") {
+		t.Errorf("Expected a press without a session to be explained, got %+v", sent[2].Embeds[0])
+	}
+}
+
+func TestGuildCommands(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, echoCommand())
+	discordSubject.AddGuildCommander(guildCommander{})
+	discordSubject.UpdateGuild(service.Guild{ServiceID: discordSubject.ID(), GuildID: testGuildID})
+
+	if diff := cmp.Diff([]string{"echo", "faq", "help"}, commandNames(server.Commands(testGuildID))); diff != "" {
+		t.Errorf("Unexpected guild commands: %s", diff)
+	}
+	if diff := cmp.Diff([]string{"echo", "help"}, commandNames(server.Commands(""))); diff != "" {
+		t.Errorf("Expected a guild's commands not to be global: %s", diff)
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!faq", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Title != "Echo" {
+		t.Errorf("Expected the guild's command to reply, got %+v", sent[0].Embeds[0])
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo done", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages are handled in order, so a reply from the guild's command would come before "done".
+	sent = waitForSent(t, server, 3)
+	if sent[1].Embeds[0].Description != "hello" || sent[2].Embeds[0].Description != "done" {
+		t.Errorf("Expected the bot's command to be used instead of the guild's, got %+v", sent[1].Embeds[0])
+	}
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "faq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !discordtest.WaitFor(timeout, func() bool { return server.Interaction(token).Edits > 0 }) {
+		t.Fatalf("Expected the guild's slash command to reply")
+	}
+}
+
+func TestMessageWithImage(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, imageCommand(), echoCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!image", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	files := sent[0].Files
+	if len(files) != 2 || files[0].ContentType != "image/png" || len(files[0].Data) == 0 {
+		t.Fatalf("Expected a png to be attached, got %+v", files)
+	}
+
+	if files[1].Name != "notes.txt" || string(files[1].Data) != "notes" {
+		t.Errorf("Expected the attachment to be sent, got %+v", files[1])
+	}
+
+	embed := sent[0].Embeds[0]
+	if embed.Image == nil || embed.Image.URL != "attachment://"+files[0].Name {
+		t.Errorf("Expected the embed to show the attachment")
+	}
+
+	if embed.Footer.Text != "Footer" {
+		t.Errorf("Expected the message's footer to be used, got %q", embed.Footer.Text)
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo done", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Messages are handled in order, so another message for the image would come before "done".
+	if sent = waitForSent(t, server, 2); sent[1].Embeds[0].Description != "done" {
+		t.Errorf("Expected a single message, got %+v", sent[1].Embeds[0])
+	}
+}
+
+func TestSlashCommandWithImage(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, imageCommand())
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "image")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if len(sent[0].Files) != 2 || sent[0].Embeds[0].Image.URL != "attachment://"+sent[0].Files[0].Name {
+		t.Errorf("Expected files to follow the response, got %+v", sent[0])
+	}
+
+	interaction := server.Interaction(token)
+	if len(interaction.Embeds) != 1 || interaction.Embeds[0].Title != "Image" || interaction.Embeds[0].Image != nil {
+		t.Errorf("Expected the response to have the embed without its image, got %+v", interaction.Embeds)
+	}
+}
+
+func TestSlashCommand(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !discordtest.WaitFor(timeout, func() bool { return server.Interaction(token).Edits > 0 }) {
+		t.Fatalf("Expected the response to be edited")
+	}
+
+	interaction := server.Interaction(token)
+	if len(interaction.Callbacks) != 1 || interaction.Callbacks[0].Type != discordgo.InteractionResponseDeferredChannelMessageWithSource {
+		t.Errorf("Expected the response to be deferred, got %+v", interaction.Callbacks)
+	}
+
+	embed := interaction.Embeds[0]
+	if embed.Description != "hello" || embed.Footer.Text != "Requested by nick20: /echo hello" {
+		t.Errorf("Unexpected response %+v %+v", embed, embed.Footer)
+	}
+}
+
+func TestSlashCommandWithoutReply(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, silentCommand())
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "silent")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !discordtest.WaitFor(timeout, func() bool { return server.Interaction(token).Deleted }) {
+		t.Errorf("Expected the deferred response to be deleted")
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	server := newServer(t)
+	_, _storage := startBot(t, server, echoCommand())
+
+	guild := service.Guild{ServiceID: ServiceID, GuildID: testGuildID}
+	if err := (*_storage).SetAdmin(guild, "<@!30>"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (*_storage).SetAdmin(guild, "<@&12>"); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name   string
+		member *discordgo.Member
+		admin  bool
+	}{
+		{"owner", member(ownerID), true},
+		{"role with permissions", member("21", "10"), true},
+		{"role without permissions", member("22", "11"), false},
+		{"no roles", member("23"), false},
+		{"admin user", member("30"), true},
+		{"admin role", member("24", "12"), true},
+	}
+
+	for i, testCase := range cases {
+		if _, err := server.SendMessage(testGuildID, testChannelID, testCase.member, "!echo "+testCase.name, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		sent := waitForSent(t, server, i+1)
+		reply := sent[i].Embeds[0]
+		if reply.Description != testCase.name {
+			t.Fatalf("Unexpected reply %q for %s", reply.Description, testCase.name)
+		}
+
+		if reply.Fields[0].Value != fmt.Sprint(testCase.admin) {
+			t.Errorf("%s: expected admin to be %t", testCase.name, testCase.admin)
+		}
+	}
+}
+
+func TestIsAdminUnknownGuild(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, echoCommand())
+
+	unknown := member("21", "10")
+	unknown.GuildID = "99"
+	if _, err := server.SendMessage("99", testChannelID, unknown, "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Fields[0].Value != "false" {
+		t.Errorf("Expected a member of an unknown guild not to be an admin")
+	}
+}
+
+func TestHelpBatches(t *testing.T) {
+	server := newServer(t)
+	commands := []command.Command{}
+	for i := 0; i < 30; i++ {
+		cmd := silentCommand()
+		cmd.Trigger = fmt.Sprintf("silent%d", i)
+		commands = append(commands, cmd)
+	}
+	startBot(t, server, commands...)
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!help", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// 30 commands, help and a link to the repository.
+	sent := waitForSent(t, server, 2)
+	if len(sent[0].Embeds[0].Fields) != 25 || len(sent[1].Embeds[0].Fields) != 7 {
+		t.Errorf("Expected fields to be split into messages of 25, got %d and %d", len(sent[0].Embeds[0].Fields), len(sent[1].Embeds[0].Fields))
+	}
+
+	if sent[1].Embeds[0].Title != "Help"+service.Continued {
+		t.Errorf("Unexpected title %q", sent[1].Embeds[0].Title)
+	}
+
+	if sent[0].Embeds[0].Fields[0].Name != "1. !silent0 " {
+		t.Errorf("Unexpected first field %q", sent[0].Embeds[0].Fields[0].Name)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, echoCommand(), silentCommand())
+	discordSubject.AddFilter(func(storage *storage.Storage, conversation service.Conversation, trigger string) error {
+		if trigger == "echo" && conversation.ConversationID == testChannelID {
+			return fmt.Errorf("echo can't be used here")
+		}
+		return nil
+	})
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Description != "echo can't be used here." {
+		t.Errorf("Expected the command not to be used, got %+v", sent[0].Embeds[0])
+	}
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!help", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent = waitForSent(t, server, 2)
+	fields := []string{}
+	for _, field := range sent[1].Embeds[0].Fields {
+		fields = append(fields, field.Name)
+	}
+	if diff := cmp.Diff([]string{"1. !silent ", "2. !help ", "Contribute to this project at: "}, fields); diff != "" {
+		t.Errorf("Expected help to hide the command (-want +got):\n%s", diff)
+	}
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "echo", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !discordtest.WaitFor(timeout, func() bool { return server.Interaction(token).Edits > 0 }) {
+		t.Fatalf("Expected the response to be edited")
+	}
+	if embed := server.Interaction(token).Embeds[0]; embed.Description != "echo can't be used here." {
+		t.Errorf("Expected the slash command not to be used, got %+v", embed)
+	}
+}
+
+func TestFilterSession(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, echoCommand(), askCommand())
+	disabled := atomic.Bool{}
+	discordSubject.AddFilter(func(storage *storage.Storage, conversation service.Conversation, trigger string) error {
+		if trigger == "ask" && disabled.Load() {
+			return fmt.Errorf("ask is disabled in this server")
+		}
+		return nil
+	})
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!ask", nil); err != nil {
+		t.Fatal(err)
+	}
+	waitForSent(t, server, 1)
+
+	// Once its command is disabled, a session doesn't receive the next message.
+	disabled.Store(true)
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!echo hello", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 2)
+	if sent[1].Embeds[0].Title != "Echo" {
+		t.Errorf("Expected the message not to be sent to the session, got %+v", sent[1].Embeds[0])
+	}
+}
+
+// buttons returns the custom IDs of the buttons of a message, and whether each is disabled.
+func buttons(components []discordgo.MessageComponent) map[string]bool {
+	found := map[string]bool{}
+	for _, component := range components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+
+		for _, rowComponent := range row.Components {
+			if button, ok := rowComponent.(*discordgo.Button); ok {
+				found[button.Label] = button.Disabled
+			}
+		}
+	}
+	return found
+}
+
+// customID returns the custom ID of the button with label.
+func customID(components []discordgo.MessageComponent, label string) string {
+	for _, component := range components {
+		for _, rowComponent := range component.(*discordgo.ActionsRow).Components {
+			if button := rowComponent.(*discordgo.Button); button.Label == label {
+				return button.CustomID
+			}
+		}
+	}
+	return ""
+}
+
+// pressAndWait presses a button on a message, then waits for the message to be updated.
+func pressAndWait(t *testing.T, server *discordtest.Server, messageID string, label string) discordgo.Message {
+	message, _ := server.Message(messageID)
+	token, err := server.PressButton(testGuildID, member("20"), messageID, customID(message.Components, label))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !discordtest.WaitFor(timeout, func() bool { return len(server.Interaction(token).Callbacks) > 0 }) {
+		t.Fatalf("Expected pressing %s to update the message", label)
+	}
+
+	message, _ = server.Message(messageID)
+	return message
+}
+
+func TestPages(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, pagesCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!pages", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Title != "One" || sent[0].Embeds[0].Footer.Text != "Page 1 of 3. Requested by user20: !pages" {
+		t.Errorf("Unexpected first page %+v", sent[0].Embeds[0])
+	}
+
+	if diff := cmp.Diff(map[string]bool{"Prev": true, "Next": false}, buttons(sent[0].Components)); diff != "" {
+		t.Errorf("Unexpected buttons: %s", diff)
+	}
+
+	message := pressAndWait(t, server, sent[0].ID, "Next")
+	message = pressAndWait(t, server, sent[0].ID, "Next")
+	if message.Embeds[0].Title != "Three" || message.Embeds[0].Footer.Text != "Page 3 of 3. Last" {
+		t.Errorf("Unexpected last page %+v", message.Embeds[0])
+	}
+
+	if diff := cmp.Diff(map[string]bool{"Prev": false, "Next": true}, buttons(message.Components)); diff != "" {
+		t.Errorf("Unexpected buttons: %s", diff)
+	}
+
+	message = pressAndWait(t, server, sent[0].ID, "Prev")
+	if message.Embeds[0].Title != "Two" {
+		t.Errorf("Expected the previous page, got %q", message.Embeds[0].Title)
+	}
+
+	if len(server.Sent()) != 1 {
+		t.Errorf("Expected pages to be shown by editing the message")
+	}
+}
+
+func TestSenderPages(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server)
+	sender := DiscordSender{discord: discordSubject.discord, subject: discordSubject}
+
+	// Messages sent without a command, such as scheduled posts, can also have pages.
+	conversation := service.Conversation{ServiceID: ServiceID, ConversationID: testChannelID, GuildID: testGuildID}
+	if err := sender.SendMessage(conversation, service.Paginate([]service.Message{{Title: "One"}, {Title: "Two"}})); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Title != "One" || sent[0].Embeds[0].Footer.Text != "Page 1 of 2" {
+		t.Errorf("Unexpected first page %+v", sent[0].Embeds[0])
+	}
+
+	if message := pressAndWait(t, server, sent[0].ID, "Next"); message.Embeds[0].Title != "Two" {
+		t.Errorf("Expected the next page, got %q", message.Embeds[0].Title)
+	}
+}
+
+func TestPagesExpired(t *testing.T) {
+	server := newServer(t)
+	discordSubject, _ := startBot(t, server, pagesCommand())
+
+	if _, err := server.SendMessage(testGuildID, testChannelID, member("20"), "!pages", nil); err != nil {
+		t.Fatal(err)
+	}
+	sent := waitForSent(t, server, 1)
+
+	discordSubject.pages = service.NewPageStore(service.PageExpiry) // Forgets every page.
+	message := pressAndWait(t, server, sent[0].ID, "Next")
+	if len(message.Components) != 0 || !strings.Contains(message.Content, "expired") {
+		t.Errorf("Expected the buttons to be removed, got %+v", message)
+	}
+
+	if message.Embeds[0].Title != "One" {
+		t.Errorf("Expected the page to be kept")
+	}
+}
+
+func TestPagesSlashCommand(t *testing.T) {
+	server := newServer(t)
+	startBot(t, server, pagesCommand())
+
+	token, err := server.SendSlashCommand(testGuildID, testChannelID, member("20"), "pages")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := waitForSent(t, server, 1)
+	if sent[0].Embeds[0].Footer.Text != "Page 1 of 3. Requested by nick20: /pages" || len(sent[0].Components) != 1 {
+		t.Errorf("Expected pages to be sent to the channel, got %+v", sent[0])
+	}
+
+	if !discordtest.WaitFor(timeout, func() bool { return server.Interaction(token).Deleted }) {
+		t.Errorf("Expected the empty response to be deleted")
+	}
+}
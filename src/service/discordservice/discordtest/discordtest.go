@@ -0,0 +1,736 @@
+// Package discordtest is a fake Discord, serving the parts of the REST API and gateway
+// that discordservice uses. Sessions made with Server.Session talk to it instead of Discord,
+// so tests can send messages and interactions to a bot, and check what the bot did.
+package discordtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// BotID is the ID of the bot's user, and of its application.
+const BotID = "1000"
+
+// apiPrefix is the start of the path of every REST request.
+var apiPrefix = "/api/v" + discordgo.APIVersion + "/"
+
+// A Request is a REST request that was made to a Server.
+type Request struct {
+	Method string
+	Path   string // Path without the API prefix, such as "channels/1/messages".
+}
+
+// A SentMessage is a message that was sent to a channel.
+type SentMessage struct {
+	ID         string
+	ChannelID  string
+	Content    string
+	Embeds     []*discordgo.MessageEmbed
+	Components []discordgo.MessageComponent
+	Files      []File
+}
+
+// A File was attached to a SentMessage.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// An Interaction records how the bot responded to an interaction.
+type Interaction struct {
+	Callbacks []discordgo.InteractionResponse // Initial responses, such as deferring.
+	Embeds    []*discordgo.MessageEmbed       // Embeds of the response, after the latest edit.
+	Edits     int                             // How many times the response was edited.
+	Deleted   bool                            // Whether the response was deleted.
+	messageID string                          // The message with the button that was pressed, if any.
+}
+
+// messageData is the part of a message or interaction response the bot can send.
+// Components are decoded using decodeComponents, as discordgo can't decode them by itself.
+type messageData struct {
+	Content    string                    `json:"content"`
+	Embeds     []*discordgo.MessageEmbed `json:"embeds"`
+	Components json.RawMessage           `json:"components"`
+}
+
+// decodeComponents decodes the components of a message.
+func decodeComponents(raw json.RawMessage) ([]discordgo.MessageComponent, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	// A message can decode its own components.
+	var message discordgo.Message
+	if err := json.Unmarshal([]byte(`{"components":`+string(raw)+`}`), &message); err != nil {
+		return nil, err
+	}
+	return message.Components, nil
+}
+
+// A Server is a fake Discord. Use NewServer to make one.
+type Server struct {
+	server   *httptest.Server
+	upgrader websocket.Upgrader
+
+	mutex        sync.Mutex // Lock when reading or writing anything below.
+	conns        []*gatewayConn
+	sequence     int64
+	nextID       int
+	guilds       []*discordgo.Guild
+	messages     map[string]*discordgo.Message // Messages that can be retrieved, by ID.
+	sent         []SentMessage
+	commands     map[string][]*discordgo.ApplicationCommand // Application commands by guild ID, "" is global.
+	interactions map[string]*Interaction                    // Responses to interactions, by token.
+	requests     []Request
+}
+
+// gatewayConn is a connection to the gateway.
+type gatewayConn struct {
+	conn  *websocket.Conn
+	mutex sync.Mutex // Lock when writing.
+}
+
+// writeJSON sends a payload through the connection.
+func (g *gatewayConn) writeJSON(payload interface{}) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.conn.WriteJSON(payload)
+}
+
+// gatewayPayload is a message sent through the gateway.
+type gatewayPayload struct {
+	Op       int         `json:"op"`
+	Data     interface{} `json:"d"`
+	Sequence int64       `json:"s,omitempty"`
+	Type     string      `json:"t,omitempty"`
+}
+
+// NewServer starts a fake Discord with guilds, which the bot is a member of.
+// Close must be called once the server is no longer needed.
+func NewServer(guilds ...*discordgo.Guild) *Server {
+	s := &Server{
+		guilds:       guilds,
+		messages:     make(map[string]*discordgo.Message),
+		commands:     make(map[string][]*discordgo.ApplicationCommand),
+		interactions: make(map[string]*Interaction),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/gateway/", s.serveGateway)
+	mux.HandleFunc(apiPrefix, s.serveAPI)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Close stops the server, closing connections to the gateway.
+func (s *Server) Close() {
+	s.mutex.Lock()
+	for _, conn := range s.conns {
+		conn.conn.Close()
+	}
+	s.mutex.Unlock()
+	s.server.Close()
+}
+
+// Session returns a discordgo session that uses this server instead of Discord.
+// It isn't open yet.
+func (s *Server) Session(token string) (*discordgo.Session, error) {
+	session, err := discordgo.New("Bot " + token)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := url.Parse(s.server.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Client = &http.Client{Transport: rewriteTransport{target: target}, Timeout: 5 * time.Second}
+	session.ShouldReconnectOnError = false
+	return session, nil
+}
+
+// rewriteTransport sends every request to a target host, rather than the requested host.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+// RoundTrip sends req to the target host.
+func (r rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	req.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newID returns a new snowflake.
+func (s *Server) newID() string {
+	s.nextID++
+	return strconv.Itoa(2000 + s.nextID)
+}
+
+// Dispatch sends an event, such as "MESSAGE_CREATE", to every connection to the gateway.
+func (s *Server) Dispatch(eventType string, data interface{}) error {
+	s.mutex.Lock()
+	s.sequence++
+	payload := gatewayPayload{Op: 0, Type: eventType, Sequence: s.sequence, Data: data}
+	conns := append([]*gatewayConn{}, s.conns...)
+	s.mutex.Unlock()
+
+	for _, conn := range conns {
+		if err := conn.writeJSON(payload); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendMessage sends a message from member to a channel, as if a user wrote it.
+// If reference isn't nil, the message is a reply to it.
+func (s *Server) SendMessage(guildID string, channelID string, member *discordgo.Member, content string, reference *discordgo.Message) (*discordgo.Message, error) {
+	s.mutex.Lock()
+	message := &discordgo.Message{
+		ID:        s.newID(),
+		ChannelID: channelID,
+		GuildID:   guildID,
+		Content:   content,
+		Author:    member.User,
+		Member:    member,
+		Timestamp: time.Now(),
+	}
+	if reference != nil {
+		message.MessageReference = reference.Reference()
+	}
+	s.messages[message.ID] = message
+	s.mutex.Unlock()
+
+	return message, s.Dispatch("MESSAGE_CREATE", message)
+}
+
+// EditMessage changes the content of a message, as if its author edited it.
+func (s *Server) EditMessage(messageID string, content string) (*discordgo.Message, error) {
+	s.mutex.Lock()
+	message, ok := s.messages[messageID]
+	if !ok {
+		s.mutex.Unlock()
+		return nil, fmt.Errorf("there is no message %s", messageID)
+	}
+	edited := *message
+	edited.Content = content
+	s.messages[messageID] = &edited
+	s.mutex.Unlock()
+
+	return &edited, s.Dispatch("MESSAGE_UPDATE", &edited)
+}
+
+// AddMessage makes a message retrievable, without sending it to the bot.
+// This is useful for messages that are replied to.
+func (s *Server) AddMessage(channelID string, content string) *discordgo.Message {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	message := &discordgo.Message{ID: s.newID(), ChannelID: channelID, Content: content}
+	s.messages[message.ID] = message
+	return message
+}
+
+// SendSlashCommand sends a slash command interaction from member, returning the interaction's token.
+// Values of options are given in order, and are named "option0", "option1" and so on.
+func (s *Server) SendSlashCommand(guildID string, channelID string, member *discordgo.Member, name string, options ...interface{}) (string, error) {
+	s.mutex.Lock()
+	id := s.newID()
+	token := "token" + id
+	s.interactions[token] = &Interaction{}
+	s.mutex.Unlock()
+
+	optionData := []map[string]interface{}{}
+	for i, value := range options {
+		optionType := discordgo.ApplicationCommandOptionString
+		switch value.(type) {
+		case bool:
+			optionType = discordgo.ApplicationCommandOptionBoolean
+		case int, float64:
+			optionType = discordgo.ApplicationCommandOptionInteger
+		}
+
+		optionData = append(optionData, map[string]interface{}{
+			"name":  fmt.Sprintf("option%d", i),
+			"type":  optionType,
+			"value": value,
+		})
+	}
+
+	interaction := map[string]interface{}{
+		"id":             id,
+		"application_id": BotID,
+		"type":           discordgo.InteractionApplicationCommand,
+		"guild_id":       guildID,
+		"channel_id":     channelID,
+		"member":         member,
+		"token":          token,
+		"version":        1,
+		"data": map[string]interface{}{
+			"id":      s.commandID(guildID, name),
+			"name":    name,
+			"type":    discordgo.ChatApplicationCommand,
+			"options": optionData,
+		},
+	}
+	return token, s.Dispatch("INTERACTION_CREATE", interaction)
+}
+
+// PressButton presses a button with customID on a message the bot sent, returning the interaction's token.
+func (s *Server) PressButton(guildID string, member *discordgo.Member, messageID string, customID string) (string, error) {
+	s.mutex.Lock()
+	message, ok := s.messages[messageID]
+	if !ok {
+		s.mutex.Unlock()
+		return "", fmt.Errorf("no message has the ID %s", messageID)
+	}
+
+	id := s.newID()
+	token := "token" + id
+	s.interactions[token] = &Interaction{messageID: messageID}
+	interaction := map[string]interface{}{
+		"id":             id,
+		"application_id": BotID,
+		"type":           discordgo.InteractionMessageComponent,
+		"guild_id":       guildID,
+		"channel_id":     message.ChannelID,
+		"member":         member,
+		"message":        message,
+		"token":          token,
+		"version":        1,
+		"data": map[string]interface{}{
+			"custom_id":      customID,
+			"component_type": discordgo.ButtonComponent,
+		},
+	}
+	s.mutex.Unlock()
+
+	return token, s.Dispatch("INTERACTION_CREATE", interaction)
+}
+
+// Message returns a message that was sent, as it is now, after any edits.
+func (s *Server) Message(messageID string) (discordgo.Message, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	message, ok := s.messages[messageID]
+	if !ok {
+		return discordgo.Message{}, false
+	}
+	return *message, true
+}
+
+// commandID returns the ID of an application command, if it exists.
+func (s *Server) commandID(guildID string, name string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, cmd := range append(s.commands[guildID], s.commands[""]...) {
+		if cmd.Name == name {
+			return cmd.ID
+		}
+	}
+	return ""
+}
+
+// JoinGuild makes the bot a member of guild, sending a GUILD_CREATE event.
+func (s *Server) JoinGuild(guild *discordgo.Guild) error {
+	s.mutex.Lock()
+	s.guilds = append(s.guilds, guild)
+	s.mutex.Unlock()
+	return s.Dispatch("GUILD_CREATE", guild)
+}
+
+// Sent returns the messages the bot has sent to channels, in order.
+func (s *Server) Sent() []SentMessage {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SentMessage{}, s.sent...)
+}
+
+// Commands returns the application commands of a guild, or the global commands if guildID is "".
+func (s *Server) Commands(guildID string) []*discordgo.ApplicationCommand {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]*discordgo.ApplicationCommand{}, s.commands[guildID]...)
+}
+
+// SetCommands replaces the application commands of a guild, or the global commands if guildID is "".
+func (s *Server) SetCommands(guildID string, commands []*discordgo.ApplicationCommand) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, cmd := range commands {
+		if cmd.ID == "" {
+			cmd.ID = s.newID()
+		}
+		cmd.ApplicationID = BotID
+		cmd.GuildID = guildID
+	}
+	s.commands[guildID] = commands
+}
+
+// Interaction returns how the bot responded to the interaction with token.
+func (s *Server) Interaction(token string) Interaction {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if interaction, ok := s.interactions[token]; ok {
+		return *interaction
+	}
+	return Interaction{}
+}
+
+// Requests returns every REST request made to the server, in order.
+func (s *Server) Requests() []Request {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// ClearRequests forgets the requests that have been made.
+func (s *Server) ClearRequests() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.requests = nil
+}
+
+// WaitFor checks condition until it is true, returning false if it's still false after timeout.
+// Events are handled asynchronously, so use this to wait for the bot to respond.
+func WaitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		if condition() {
+			return true
+		}
+
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// serveGateway accepts a websocket connection, then says hello and sends READY once identified.
+func (s *Server) serveGateway(w http.ResponseWriter, r *http.Request) {
+	wsConn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	conn := &gatewayConn{conn: wsConn}
+	defer wsConn.Close()
+
+	if err := conn.writeJSON(gatewayPayload{Op: 10, Data: map[string]interface{}{"heartbeat_interval": 45000}}); err != nil {
+		return
+	}
+
+	for {
+		var payload struct {
+			Op int `json:"op"`
+		}
+		if err := wsConn.ReadJSON(&payload); err != nil {
+			return
+		}
+
+		switch payload.Op {
+		case 1: // Heartbeat.
+			if err := conn.writeJSON(gatewayPayload{Op: 11}); err != nil {
+				return
+			}
+		case 2: // Identify.
+			s.mutex.Lock()
+			s.sequence++
+			ready := gatewayPayload{
+				Op:       0,
+				Type:     "READY",
+				Sequence: s.sequence,
+				Data: discordgo.Ready{
+					Version:   9,
+					SessionID: "session",
+					User:      &discordgo.User{ID: BotID, Username: "boby", Bot: true},
+					Guilds:    s.guilds,
+				},
+			}
+			s.conns = append(s.conns, conn)
+			s.mutex.Unlock()
+
+			if err := conn.writeJSON(ready); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// serveAPI handles REST requests.
+func (s *Server) serveAPI(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, apiPrefix)
+	parts := strings.Split(path, "/")
+
+	s.mutex.Lock()
+	s.requests = append(s.requests, Request{Method: r.Method, Path: path})
+	s.mutex.Unlock()
+
+	switch {
+	case path == "gateway":
+		writeJSON(w, map[string]string{"url": "ws://" + s.server.Listener.Addr().String() + "/gateway"})
+	case len(parts) == 2 && parts[0] == "guilds" && r.Method == http.MethodGet:
+		s.serveGuild(w, parts[1])
+	case len(parts) == 3 && parts[0] == "channels" && parts[2] == "messages" && r.Method == http.MethodPost:
+		s.serveSendMessage(w, r, parts[1])
+	case len(parts) == 4 && parts[0] == "channels" && parts[2] == "messages" && r.Method == http.MethodGet:
+		s.serveMessage(w, parts[3])
+	case len(parts) >= 3 && parts[0] == "applications" && parts[2] == "commands":
+		s.serveCommands(w, r, "", parts[3:])
+	case len(parts) >= 5 && parts[0] == "applications" && parts[2] == "guilds" && parts[4] == "commands":
+		s.serveCommands(w, r, parts[3], parts[5:])
+	case len(parts) == 4 && parts[0] == "interactions" && parts[3] == "callback":
+		s.serveInteractionCallback(w, r, parts[2])
+	case len(parts) == 5 && parts[0] == "webhooks" && parts[3] == "messages" && parts[4] == "@original":
+		s.serveInteractionResponse(w, r, parts[2])
+	default:
+		http.Error(w, `{"message": "unknown endpoint", "code": 0}`, http.StatusNotFound)
+	}
+}
+
+// serveGuild responds with a guild the bot is a member of.
+func (s *Server) serveGuild(w http.ResponseWriter, guildID string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, guild := range s.guilds {
+		if guild.ID == guildID {
+			writeJSON(w, guild)
+			return
+		}
+	}
+	http.Error(w, `{"message": "Unknown Guild", "code": 10004}`, http.StatusNotFound)
+}
+
+// serveMessage responds with a message.
+func (s *Server) serveMessage(w http.ResponseWriter, messageID string) {
+	s.mutex.Lock()
+	message, ok := s.messages[messageID]
+	s.mutex.Unlock()
+	if !ok {
+		http.Error(w, `{"message": "Unknown Message", "code": 10008}`, http.StatusNotFound)
+		return
+	}
+	writeJSON(w, message)
+}
+
+// serveSendMessage records a message sent by the bot, which may have attached files.
+func (s *Server) serveSendMessage(w http.ResponseWriter, r *http.Request, channelID string) {
+	var data messageData
+	files := []File{}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			content, err := io.ReadAll(part)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if part.FormName() == "payload_json" {
+				if err := json.Unmarshal(content, &data); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				continue
+			}
+			files = append(files, File{Name: part.FileName(), ContentType: part.Header.Get("Content-Type"), Data: content})
+		}
+	} else if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	components, err := decodeComponents(data.Components)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	message := &discordgo.Message{
+		ID:         s.newID(),
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: components,
+		Author:     &discordgo.User{ID: BotID, Username: "boby", Bot: true},
+	}
+	s.messages[message.ID] = message
+	s.sent = append(s.sent, SentMessage{
+		ID:         message.ID,
+		ChannelID:  channelID,
+		Content:    data.Content,
+		Embeds:     data.Embeds,
+		Components: components,
+		Files:      files,
+	})
+	s.mutex.Unlock()
+
+	writeJSON(w, message)
+}
+
+// serveCommands lists, creates, edits and deletes application commands.
+// rest is the path following "commands", which is empty or the ID of a command.
+func (s *Server) serveCommands(w http.ResponseWriter, r *http.Request, guildID string, rest []string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(rest) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			commands := s.commands[guildID]
+			if commands == nil {
+				commands = []*discordgo.ApplicationCommand{}
+			}
+			writeJSON(w, commands)
+		case http.MethodPost:
+			var cmd discordgo.ApplicationCommand
+			if err := json.NewDecoder(r.Body).Decode(&cmd); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			cmd.ID = s.newID()
+			cmd.ApplicationID = BotID
+			cmd.GuildID = guildID
+			s.commands[guildID] = append(s.commands[guildID], &cmd)
+			writeJSON(w, cmd)
+		default:
+			http.Error(w, "", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	commands := s.commands[guildID]
+	for i, cmd := range commands {
+		if cmd.ID != rest[0] {
+			continue
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			var edit discordgo.ApplicationCommand
+			if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			edit.ID = cmd.ID
+			edit.ApplicationID = BotID
+			edit.GuildID = guildID
+			commands[i] = &edit
+			writeJSON(w, edit)
+		case http.MethodDelete:
+			s.commands[guildID] = append(commands[:i:i], commands[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.Error(w, "", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	http.Error(w, `{"message": "Unknown application command", "code": 10063}`, http.StatusNotFound)
+}
+
+// serveInteractionCallback records the initial response to an interaction.
+// A response that updates a message edits the message with the button that was pressed.
+func (s *Server) serveInteractionCallback(w http.ResponseWriter, r *http.Request, token string) {
+	var body struct {
+		Type discordgo.InteractionResponseType `json:"type"`
+		Data *messageData                      `json:"data"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := discordgo.InteractionResponse{Type: body.Type}
+	if body.Data != nil {
+		components, err := decodeComponents(body.Data.Components)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		response.Data = &discordgo.InteractionResponseData{Content: body.Data.Content, Embeds: body.Data.Embeds, Components: components}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	interaction, ok := s.interactions[token]
+	if !ok {
+		http.Error(w, `{"message": "Unknown interaction", "code": 10062}`, http.StatusNotFound)
+		return
+	}
+	interaction.Callbacks = append(interaction.Callbacks, response)
+
+	if message, ok := s.messages[interaction.messageID]; ok && response.Type == discordgo.InteractionResponseUpdateMessage && response.Data != nil {
+		message.Content = response.Data.Content
+		if response.Data.Embeds != nil {
+			message.Embeds = response.Data.Embeds
+		}
+		message.Components = response.Data.Components
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// serveInteractionResponse edits or deletes the response to an interaction.
+func (s *Server) serveInteractionResponse(w http.ResponseWriter, r *http.Request, token string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	interaction, ok := s.interactions[token]
+	if !ok {
+		http.Error(w, `{"message": "Unknown Webhook", "code": 10015}`, http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPatch:
+		var edit struct {
+			Content *string                   `json:"content"`
+			Embeds  []*discordgo.MessageEmbed `json:"embeds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&edit); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		interaction.Edits++
+		interaction.Embeds = edit.Embeds
+		writeJSON(w, discordgo.Message{ID: s.newID(), Embeds: edit.Embeds})
+	case http.MethodDelete:
+		interaction.Deleted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "", http.StatusMethodNotAllowed)
+	}
+}
+
+// writeJSON responds with value as JSON.
+func writeJSON(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -1,40 +1,197 @@
 package discordservice
 
 import (
+	"bytes"
 	"fmt"
+	"image/png"
+	"time"
 
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/bwmarrin/discordgo"
 )
 
+// Limits are the limits of a Discord embed.
+var Limits = service.Limits{
+	Title:       256,
+	Description: 4096,
+	Fields:      25,
+	FieldName:   256,
+	FieldValue:  1024,
+	Footer:      2048,
+	Author:      256,
+	Total:       6000,
+}
+
+// maxEmbeds is how many embeds a Discord message can have.
+const maxEmbeds = 10
+
+// maxFiles is how many files a Discord message can have.
+const maxFiles = 10
+
 // MsgToEmbed converts a service.msg to a discordgo message embed.
+// Images and attachments are sent as files, so they aren't included, see msgToFiles.
+// The embed may be larger than Discord allows, use split to make it fit.
 func MsgToEmbed(msg service.Message) discordgo.MessageEmbed {
-	fields := make([]*discordgo.MessageEmbedField, 0)
+	return toEmbed(withLinks(msg))
+}
+
+// withLinks adds a link to the description of msg and to each field with a URL.
+// Links are added before a message is split, so they count towards Limits.
+func withLinks(msg service.Message) service.Message {
+	if msg.URL != "" {
+		msg.Description += fmt.Sprintf("\nRead more at: %s", msg.URL)
+	}
+
+	fields := make([]service.MessageField, 0, len(msg.Fields))
 	for _, field := range msg.Fields {
-		value := field.Value
 		if field.URL != "" {
-			value += fmt.Sprintf("\nRead more at: %s", field.URL)
+			field.Value += fmt.Sprintf("\nRead more at: %s", field.URL)
+			field.URL = ""
 		}
+		fields = append(fields, field)
+	}
+	msg.Fields = fields
+	return msg
+}
+
+// split divides msg into messages that each fit in an embed.
+func split(msg service.Message) []service.Message {
+	return Limits.Split(withLinks(msg))
+}
+
+// toEmbed converts a service.msg, which already has links added by withLinks, to a discordgo message embed.
+func toEmbed(msg service.Message) discordgo.MessageEmbed {
+	fields := make([]*discordgo.MessageEmbedField, 0)
+	for _, field := range msg.Fields {
 		fields = append(
 			fields,
 			&discordgo.MessageEmbedField{
 				Name:   field.Field,
-				Value:  value,
+				Value:  field.Value,
 				Inline: field.Inline,
 			})
 	}
 
-	desc := msg.Description
-	if msg.URL != "" {
-		desc += fmt.Sprintf("\nRead more at: %s", msg.URL)
-	}
-
 	embed := discordgo.MessageEmbed{
 		URL:         msg.URL,
 		Title:       msg.Title,
-		Description: desc,
+		Description: msg.Description,
 		Fields:      fields,
+		Color:       msg.Color,
+	}
+
+	if msg.Footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: msg.Footer}
+	}
+
+	if msg.Author.Name != "" {
+		embed.Author = &discordgo.MessageEmbedAuthor{
+			Name:    msg.Author.Name,
+			URL:     msg.Author.URL,
+			IconURL: msg.Author.IconURL,
+		}
+	}
+
+	if msg.ThumbnailURL != "" {
+		embed.Thumbnail = &discordgo.MessageEmbedThumbnail{URL: msg.ThumbnailURL}
+	}
+
+	if !msg.Timestamp.IsZero() {
+		embed.Timestamp = msg.Timestamp.Format(time.RFC3339)
 	}
 
 	return embed
 }
+
+// msgToFiles encodes the images and attachments of msg as files.
+// An embed is returned for each image, as an embed can only show one image.
+func msgToFiles(msg service.Message) ([]*discordgo.MessageEmbed, []*discordgo.File, error) {
+	embeds := []*discordgo.MessageEmbed{}
+	files := []*discordgo.File{}
+	for i, img := range msg.AllImages() {
+		var buffer bytes.Buffer
+		if err := png.Encode(&buffer, img); err != nil {
+			return nil, nil, fmt.Errorf("error when encoding png: %s", err)
+		}
+
+		filename := fmt.Sprintf("image%d.png", i+1)
+		embeds = append(embeds, &discordgo.MessageEmbed{
+			Image: &discordgo.MessageEmbedImage{URL: "attachment://" + filename},
+		})
+		files = append(files, &discordgo.File{
+			Name:        filename,
+			ContentType: "image/png",
+			Reader:      &buffer,
+		})
+	}
+
+	for _, attachment := range msg.Attachments {
+		files = append(files, &discordgo.File{
+			Name:        attachment.Name,
+			ContentType: attachment.ContentType,
+			Reader:      bytes.NewReader(attachment.Data),
+		})
+	}
+	return embeds, files, nil
+}
+
+// msgToSends converts a service.msg to discordgo messages, splitting it so that each fits.
+// Buttons for the choices of msg are added to the last message.
+func msgToSends(msg service.Message) ([]*discordgo.MessageSend, error) {
+	sends := []*discordgo.MessageSend{}
+	for _, part := range split(msg) {
+		partSends, err := partToSends(part)
+		if err != nil {
+			return nil, err
+		}
+		sends = append(sends, partSends...)
+	}
+
+	if len(msg.Choices) > 0 && len(sends) > 0 {
+		sends[len(sends)-1].Components = choiceButtons(msg.Choices)
+	}
+	return sends, nil
+}
+
+// partToSends converts part of a split message to discordgo messages.
+// The first image of a message is shown in its embed, and any other images in embeds that
+// follow it, which are sent in messages of their own if there are too many.
+// Attachments follow the images, in messages of their own once a message has too many files.
+func partToSends(part service.Message) ([]*discordgo.MessageSend, error) {
+	embed := toEmbed(part)
+	imageEmbeds, files, err := msgToFiles(part)
+	if err != nil {
+		return nil, err
+	}
+
+	// The file of each image has the same index as its embed, and attachments follow them.
+	imageFiles, attachments := files[:len(imageEmbeds):len(imageEmbeds)], files[len(imageEmbeds):]
+	if len(imageEmbeds) > 0 {
+		embed.Image = imageEmbeds[0].Image
+		imageEmbeds = imageEmbeds[1:]
+	}
+
+	// An embed must be sent with its image.
+	sends := []*discordgo.MessageSend{}
+	embeds := append([]*discordgo.MessageEmbed{&embed}, imageEmbeds...)
+	for start := 0; start < len(embeds); start += maxEmbeds {
+		end := min(start+maxEmbeds, len(embeds))
+		sends = append(sends, &discordgo.MessageSend{
+			Embeds: embeds[start:end],
+			Files:  imageFiles[min(start, len(imageFiles)):min(end, len(imageFiles))],
+		})
+	}
+
+	last := sends[len(sends)-1]
+	for len(attachments) > 0 {
+		if len(last.Files) == maxFiles {
+			last = &discordgo.MessageSend{}
+			sends = append(sends, last)
+		}
+
+		count := min(maxFiles-len(last.Files), len(attachments))
+		last.Files = append(last.Files, attachments[:count]...)
+		attachments = attachments[count:]
+	}
+	return sends, nil
+}
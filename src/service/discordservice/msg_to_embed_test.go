@@ -0,0 +1,207 @@
+package discordservice
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+func TestMsgToEmbed(t *testing.T) {
+	timestamp := time.Date(2021, 5, 4, 3, 2, 1, 0, time.UTC)
+	embed := MsgToEmbed(service.Message{
+		Title:        "Title",
+		Description:  "Description",
+		URL:          "https://example.com",
+		Fields:       []service.MessageField{{Field: "Field", Value: "Value", URL: "https://example.com/field", Inline: true}},
+		Footer:       "Footer",
+		Color:        0xFF0000,
+		Author:       service.MessageAuthor{Name: "Author", URL: "https://example.com/author", IconURL: "https://example.com/icon.png"},
+		ThumbnailURL: "https://example.com/thumbnail.png",
+		Timestamp:    timestamp,
+	})
+
+	expect := discordgo.MessageEmbed{
+		URL:         "https://example.com",
+		Title:       "Title",
+		Description: "Description\nRead more at: https://example.com",
+		Fields:      []*discordgo.MessageEmbedField{{Name: "Field", Value: "Value\nRead more at: https://example.com/field", Inline: true}},
+		Color:       0xFF0000,
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Footer"},
+		Author:      &discordgo.MessageEmbedAuthor{Name: "Author", URL: "https://example.com/author", IconURL: "https://example.com/icon.png"},
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: "https://example.com/thumbnail.png"},
+		Timestamp:   "2021-05-04T03:02:01Z",
+	}
+
+	if diff := cmp.Diff(expect, embed); diff != "" {
+		t.Errorf("Unexpected embed: %s", diff)
+	}
+}
+
+func TestMsgToEmbedEmpty(t *testing.T) {
+	embed := MsgToEmbed(service.Message{Title: "Title"})
+	if embed.Footer != nil || embed.Author != nil || embed.Thumbnail != nil || embed.Timestamp != "" {
+		t.Errorf("Expected missing parts of a message to be left out, got %+v", embed)
+	}
+}
+
+func TestMsgToSends(t *testing.T) {
+	sends, err := msgToSends(service.Message{
+		Title:       "Title",
+		Image:       image.NewRGBA(image.Rect(0, 0, 1, 1)),
+		Images:      []image.Image{image.NewRGBA(image.Rect(0, 0, 2, 2)), nil},
+		Attachments: []service.Attachment{{Name: "results.csv", ContentType: "text/csv", Data: []byte("a,b")}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sends) != 1 {
+		t.Fatalf("Expected a single message, got %d", len(sends))
+	}
+
+	send := sends[0]
+	if len(send.Embeds) != 2 || send.Embeds[0].Title != "Title" {
+		t.Fatalf("Expected an embed for each image, got %d", len(send.Embeds))
+	}
+
+	for i, expect := range []string{"attachment://image1.png", "attachment://image2.png"} {
+		if send.Embeds[i].Image == nil || send.Embeds[i].Image.URL != expect {
+			t.Errorf("Expected embed %d to show %s", i, expect)
+		}
+	}
+
+	names := []string{}
+	for _, file := range send.Files {
+		names = append(names, file.Name+" "+file.ContentType)
+	}
+	if diff := cmp.Diff([]string{"image1.png image/png", "image2.png image/png", "results.csv text/csv"}, names); diff != "" {
+		t.Errorf("Unexpected files: %s", diff)
+	}
+
+	data, err := io.ReadAll(send.Files[2].Reader)
+	if err != nil || string(data) != "a,b" {
+		t.Errorf("Unexpected attachment data %q", data)
+	}
+}
+
+func TestMsgToSendsSplits(t *testing.T) {
+	sends, err := msgToSends(service.Message{
+		Title:       "Title",
+		Description: strings.Repeat("line\n", 1000),
+		URL:         "https://example.com",
+		Image:       image.NewRGBA(image.Rect(0, 0, 1, 1)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sends) != 2 {
+		t.Fatalf("Expected the description to be split into 2 messages, got %d", len(sends))
+	}
+
+	if sends[0].Embeds[0].URL != "https://example.com" || len(sends[0].Files) != 1 || len(sends[1].Files) != 0 {
+		t.Errorf("Expected the link and image to only be in the first message")
+	}
+
+	if sends[1].Embeds[0].Title != "Title"+service.Continued {
+		t.Errorf("Unexpected title %q", sends[1].Embeds[0].Title)
+	}
+
+	if !strings.HasSuffix(sends[1].Embeds[0].Description, "Read more at: https://example.com") {
+		t.Errorf("Expected the link to be added before splitting")
+	}
+}
+
+func TestMsgToSendsManyImages(t *testing.T) {
+	images := []image.Image{}
+	for i := 0; i < 12; i++ {
+		images = append(images, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	}
+
+	sends, err := msgToSends(service.Message{Title: "Images", Images: images})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sends) != 2 || len(sends[0].Embeds) != maxEmbeds || len(sends[1].Embeds) != 2 {
+		t.Fatalf("Expected images to be split between messages")
+	}
+
+	for _, send := range sends {
+		for i, embed := range send.Embeds {
+			if embed.Image.URL != "attachment://"+send.Files[i].Name {
+				t.Errorf("Expected embed to be sent with its image, got %s", embed.Image.URL)
+			}
+		}
+	}
+}
+
+func TestMsgToSendsManyAttachments(t *testing.T) {
+	images := []image.Image{}
+	for i := 0; i < 3; i++ {
+		images = append(images, image.NewRGBA(image.Rect(0, 0, 1, 1)))
+	}
+
+	attachments := []service.Attachment{}
+	for i := 0; i < 15; i++ {
+		attachments = append(attachments, service.Attachment{Name: fmt.Sprintf("file%d.txt", i), ContentType: "text/plain", Data: []byte("data")})
+	}
+
+	sends, err := msgToSends(service.Message{Title: "Files", Images: images, Attachments: attachments})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sends) != 2 || len(sends[0].Files) != maxFiles || len(sends[1].Files) != 8 {
+		t.Fatalf("Expected files to be split between messages")
+	}
+
+	if len(sends[0].Embeds) != 3 || len(sends[1].Embeds) != 0 {
+		t.Errorf("Expected the embeds to only be in the first message")
+	}
+
+	for i, embed := range sends[0].Embeds {
+		if embed.Image.URL != "attachment://"+sends[0].Files[i].Name {
+			t.Errorf("Expected embed to be sent with its image, got %s", embed.Image.URL)
+		}
+	}
+}
+
+func TestMsgToSendsChoices(t *testing.T) {
+	choices := []string{strings.Repeat("a", maxCustomID), strings.Repeat("b", maxButtonLabel+1)}
+	for i := 0; i < 30; i++ {
+		choices = append(choices, fmt.Sprint(i), fmt.Sprint(i))
+	}
+
+	sends, err := msgToSends(service.Message{Title: "Pick", Description: strings.Repeat("line\n", 1000), Choices: choices})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sends) != 2 || len(sends[0].Components) != 0 {
+		t.Fatalf("Expected buttons to only be on the last message")
+	}
+
+	rows := sends[1].Components
+	if len(rows) != maxButtonRows {
+		t.Fatalf("Expected %d rows of buttons, got %+v", maxButtonRows, rows)
+	}
+
+	for i, row := range rows {
+		for j, component := range row.(discordgo.ActionsRow).Components {
+			// Choices that are too long, and repeated choices, are left out.
+			expected := fmt.Sprint(i*maxButtonsPerRow + j)
+			if button := component.(discordgo.Button); button.Label != expected || button.CustomID != choiceButtonPrefix+expected {
+				t.Errorf("Expected a button for %s, got %+v", expected, button)
+			}
+		}
+	}
+}
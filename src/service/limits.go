@@ -0,0 +1,143 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Continued is added to the title of every message that continues a message that was split.
+const Continued = " (continued)"
+
+// ellipsis ends text that was truncated.
+const ellipsis = "…"
+
+// Limits describes the largest message a service can send, in characters unless stated otherwise.
+// A limit of 0 means there is no limit.
+type Limits struct {
+	Title       int
+	Description int
+	Fields      int // How many fields a message can have.
+	FieldName   int
+	FieldValue  int
+	Footer      int
+	Author      int
+	Total       int // Across the title, description, fields, footer and author.
+}
+
+// Split divides msg into messages that fit within l.
+// A description that is too long is split between lines where possible, and fields that don't
+// fit are moved to the messages that follow. Those messages have the title of msg with Continued
+// added. Text that can't be split, such as a title or field, is truncated.
+// Only the first message has the URL, images, thumbnail, timestamp and attachments of msg.
+func (l Limits) Split(msg Message) []Message {
+	msg.Title = truncate(msg.Title, l.Title)
+	msg.Footer = truncate(msg.Footer, l.Footer)
+	msg.Author.Name = truncate(msg.Author.Name, l.Author)
+
+	fields := make([]MessageField, 0, len(msg.Fields))
+	for _, field := range msg.Fields {
+		field.Field = truncate(field.Field, l.FieldName)
+		field.Value = truncate(field.Value, l.FieldValue)
+		fields = append(fields, field)
+	}
+
+	continued := Message{
+		Title:  continuedTitle(msg.Title, l.Title),
+		Footer: msg.Footer,
+		Color:  msg.Color,
+		Author: msg.Author,
+	}
+
+	descriptionLimit := l.Description
+	if l.Total > 0 {
+		// Leave room for the longest title, so any message can hold a chunk.
+		remaining := l.Total - max(length(msg.Title), length(continued.Title)) - length(msg.Footer) - length(msg.Author.Name)
+		if descriptionLimit == 0 || remaining < descriptionLimit {
+			descriptionLimit = max(remaining, 1)
+		}
+	}
+
+	chunks := splitText(msg.Description, descriptionLimit)
+	first := msg
+	first.Description = chunks[0]
+	first.Fields = nil
+	messages := []Message{first}
+	for _, chunk := range chunks[1:] {
+		next := continued
+		next.Description = chunk
+		messages = append(messages, next)
+	}
+
+	for _, field := range fields {
+		last := &messages[len(messages)-1]
+		full := l.Fields > 0 && len(last.Fields) >= l.Fields
+		empty := last.Description == "" && len(last.Fields) == 0 // Moving a field from an empty message doesn't help.
+		tooLong := l.Total > 0 && !empty && last.Length()+length(field.Field)+length(field.Value) > l.Total
+		if full || tooLong {
+			messages = append(messages, continued)
+			last = &messages[len(messages)-1]
+		}
+		last.Fields = append(last.Fields, field)
+	}
+	return messages
+}
+
+// Length returns how many characters of m count towards Limits.Total.
+func (m Message) Length() int {
+	total := length(m.Title) + length(m.Description) + length(m.Footer) + length(m.Author.Name)
+	for _, field := range m.Fields {
+		total += length(field.Field) + length(field.Value)
+	}
+	return total
+}
+
+// length returns how many characters are in text.
+func length(text string) int {
+	return utf8.RuneCountInString(text)
+}
+
+// truncate shortens text to at most limit characters, ending it with an ellipsis if it was shortened.
+func truncate(text string, limit int) string {
+	if limit <= 0 || length(text) <= limit {
+		return text
+	}
+
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:limit-1])) + ellipsis
+}
+
+// continuedTitle returns title with Continued added, within limit characters.
+func continuedTitle(title string, limit int) string {
+	if limit > 0 {
+		title = truncate(title, limit-length(Continued))
+	}
+	return strings.TrimSpace(title + Continued)
+}
+
+// splitText divides text into chunks of at most limit characters.
+// Text is split at the last line break of a chunk, or failing that the last space.
+func splitText(text string, limit int) []string {
+	if limit <= 0 || length(text) <= limit {
+		return []string{text}
+	}
+
+	chunks := []string{}
+	runes := []rune(text)
+	for len(runes) > limit {
+		chunk := string(runes[:limit])
+		end := strings.LastIndex(chunk, "\n")
+		if end <= 0 {
+			end = strings.LastIndex(chunk, " ")
+		}
+
+		if end <= 0 {
+			chunks = append(chunks, chunk)
+			runes = runes[limit:]
+			continue
+		}
+
+		chunks = append(chunks, chunk[:end])
+		runes = []rune(strings.TrimLeft(string(runes)[end:], "\n "))
+	}
+	return append(chunks, string(runes))
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSplitFits(t *testing.T) {
+	msg := Message{Title: "Title", Description: "Description", Fields: []MessageField{{Field: "a", Value: "b"}}}
+	messages := Limits{Title: 10, Description: 20, Fields: 2, Total: 100}.Split(msg)
+	if diff := cmp.Diff([]Message{msg}, messages); diff != "" {
+		t.Errorf("Expected a message that fits to be unchanged: %s", diff)
+	}
+}
+
+func TestSplitNoLimits(t *testing.T) {
+	msg := Message{Title: strings.Repeat("a", 1000), Description: strings.Repeat("b", 10000)}
+	if messages := (Limits{}).Split(msg); len(messages) != 1 || messages[0].Description != msg.Description {
+		t.Errorf("Expected no limits to leave a message unchanged")
+	}
+}
+
+func TestSplitTruncates(t *testing.T) {
+	msg := Message{
+		Title:  "A long title",
+		Footer: "A long footer",
+		Author: MessageAuthor{Name: "A long author"},
+		Fields: []MessageField{{Field: "A long name", Value: "A long value"}},
+	}
+	messages := Limits{Title: 6, Footer: 6, Author: 6, FieldName: 6, FieldValue: 6}.Split(msg)
+
+	expect := Message{
+		Title:  "A lon…",
+		Footer: "A lon…",
+		Author: MessageAuthor{Name: "A lon…"},
+		Fields: []MessageField{{Field: "A lon…", Value: "A lon…"}},
+	}
+	if diff := cmp.Diff([]Message{expect}, messages); diff != "" {
+		t.Errorf("Unexpected messages: %s", diff)
+	}
+}
+
+func TestSplitDescription(t *testing.T) {
+	msg := Message{
+		Title:       "Title",
+		URL:         "https://example.com",
+		Color:       0xFF0000,
+		Footer:      "Footer",
+		Image:       image.NewRGBA(image.Rect(0, 0, 1, 1)),
+		Description: "first line\nsecond line\nthird words that are long",
+	}
+	messages := Limits{Description: 12}.Split(msg)
+
+	descriptions := []string{}
+	for _, message := range messages {
+		descriptions = append(descriptions, message.Description)
+	}
+	if diff := cmp.Diff([]string{"first line", "second line", "third words", "that are", "long"}, descriptions); diff != "" {
+		t.Errorf("Unexpected descriptions: %s", diff)
+	}
+
+	if messages[0].URL != msg.URL || messages[0].Image == nil || messages[0].Title != "Title" {
+		t.Errorf("Expected the first message to keep its link, image and title")
+	}
+
+	for _, message := range messages[1:] {
+		if message.Title != "Title"+Continued || message.URL != "" || message.Image != nil {
+			t.Errorf("Unexpected continued message %+v", message)
+		}
+
+		if message.Color != msg.Color || message.Footer != msg.Footer {
+			t.Errorf("Expected a continued message to look like the first")
+		}
+	}
+}
+
+func TestSplitWithoutSpaces(t *testing.T) {
+	messages := Limits{Description: 4}.Split(Message{Description: "abcdefghij"})
+	descriptions := []string{}
+	for _, message := range messages {
+		descriptions = append(descriptions, message.Description)
+	}
+
+	if diff := cmp.Diff([]string{"abcd", "efgh", "ij"}, descriptions); diff != "" {
+		t.Errorf("Unexpected descriptions: %s", diff)
+	}
+
+	if messages[1].Title != "(continued)" {
+		t.Errorf("Unexpected title %q for a message without a title", messages[1].Title)
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	fields := []MessageField{}
+	for i := 0; i < 7; i++ {
+		fields = append(fields, MessageField{Field: fmt.Sprint(i), Value: "value"})
+	}
+
+	messages := Limits{Fields: 3}.Split(Message{Title: "Help", Description: "Commands", Fields: fields})
+	if len(messages) != 3 {
+		t.Fatalf("Expected 3 messages, got %d", len(messages))
+	}
+
+	for i, count := range []int{3, 3, 1} {
+		if len(messages[i].Fields) != count {
+			t.Errorf("Expected message %d to have %d fields, got %d", i, count, len(messages[i].Fields))
+		}
+	}
+
+	if messages[2].Fields[0].Field != "6" || messages[2].Description != "" {
+		t.Errorf("Expected fields to stay in order, without repeating the description")
+	}
+}
+
+func TestSplitTotal(t *testing.T) {
+	msg := Message{
+		Title:       "Title",
+		Description: strings.Repeat("word ", 10),
+		Fields:      []MessageField{{Field: "name", Value: "value"}, {Field: "name", Value: "value"}},
+	}
+	limits := Limits{Total: 30}
+	messages := limits.Split(msg)
+
+	for i, message := range messages {
+		if message.Length() > limits.Total {
+			t.Errorf("Message %d has %d characters", i, message.Length())
+		}
+	}
+
+	if messages[len(messages)-1].Fields == nil {
+		t.Errorf("Expected the fields to be kept")
+	}
+}
+
+func TestSplitContinuedTitleFits(t *testing.T) {
+	messages := Limits{Title: 15, Description: 2}.Split(Message{Title: "A long title", Description: "ab cd"})
+	if messages[1].Title != "A…"+Continued {
+		t.Errorf("Unexpected title %q", messages[1].Title)
+	}
+}
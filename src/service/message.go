@@ -1,14 +1,27 @@
 package service
 
-import "image"
+import (
+	"image"
+	"time"
+)
 
 // A Message is sent using a Sender.
+// A Sender shows as much of a message as its service supports, and ignores the rest.
 type Message struct {
-	URL         string
-	Title       string
-	Description string
-	Fields      []MessageField
-	Image       image.Image
+	URL          string
+	Title        string
+	Description  string
+	Fields       []MessageField
+	Image        image.Image
+	Images       []image.Image // More images, shown after Image.
+	Footer       string        // Small text shown after everything else. When empty, a service may say who requested the message.
+	Color        int           // Color as RGB, such as 0xFF0000 for red. 0 uses the service's default.
+	Author       MessageAuthor // Who or what the message is from, shown before the title.
+	ThumbnailURL string        // A small image shown beside the message.
+	Timestamp    time.Time     // When the content of the message is from. Ignored when zero.
+	Attachments  []Attachment  // Files sent with the message.
+	Pages        []Message     // When set, pages are shown one at a time instead of this message, see Paginate.
+	Choices      []string      // Replies shown as buttons, where pressing one sends it as the user's next message. Users can also type a choice.
 }
 
 // A MessageField stores a field and value pair.
@@ -18,3 +31,28 @@ type MessageField struct {
 	URL    string
 	Inline bool
 }
+
+// A MessageAuthor is who or what a message is from.
+type MessageAuthor struct {
+	Name    string
+	URL     string // Where Name links to.
+	IconURL string // A small image shown beside Name.
+}
+
+// An Attachment is a file sent with a message.
+type Attachment struct {
+	Name        string // Filename, such as "results.csv".
+	ContentType string // MIME type, such as "text/csv".
+	Data        []byte
+}
+
+// AllImages returns Image followed by Images, skipping any that are nil.
+func (m Message) AllImages() []image.Image {
+	images := []image.Image{}
+	for _, img := range append([]image.Image{m.Image}, m.Images...) {
+		if img != nil {
+			images = append(images, img)
+		}
+	}
+	return images
+}
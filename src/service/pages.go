@@ -0,0 +1,136 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// PageExpiry is how long pages are remembered after they were last shown.
+const PageExpiry = 15 * time.Minute
+
+// NextPageTrigger is the trigger of the command that shows the next page of pages sent using Fallback.
+const NextPageTrigger = "next"
+
+// Paginate returns a message that shows pages one at a time. The message is the first page,
+// so a Sender that can't show pages still sends something useful.
+func Paginate(pages []Message) Message {
+	if len(pages) == 0 {
+		return Message{}
+	}
+
+	msg := pages[0]
+	msg.Pages = pages
+	return msg
+}
+
+// PageLabel describes which page is shown, where number counts from 0.
+func PageLabel(number int, count int) string {
+	return fmt.Sprintf("Page %d of %d", number+1, count)
+}
+
+// A PageStore remembers paginated messages until they expire, and which page of each is shown.
+// Use NewPageStore to make one.
+type PageStore struct {
+	expiry  time.Duration
+	now     func() time.Time
+	mutex   sync.Mutex // Lock when reading or writing entries.
+	entries map[string]*pagesEntry
+}
+
+// pagesEntry is a paginated message in a PageStore.
+type pagesEntry struct {
+	pages   []Message
+	current int
+	expires time.Time
+}
+
+// NewPageStore returns a PageStore that forgets pages once they haven't been shown for expiry.
+func NewPageStore(expiry time.Duration) *PageStore {
+	return &PageStore{
+		expiry:  expiry,
+		now:     time.Now,
+		entries: make(map[string]*pagesEntry),
+	}
+}
+
+// Add remembers pages by key, replacing any pages that key had. The first page is shown.
+func (p *PageStore) Add(key string, pages []Message) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	for entryKey, entry := range p.entries {
+		if now.After(entry.expires) {
+			delete(p.entries, entryKey)
+		}
+	}
+
+	p.entries[key] = &pagesEntry{pages: pages, expires: now.Add(p.expiry)}
+}
+
+// Turn moves the pages of key forward by offset, or back if offset is negative, stopping at the first
+// and last page. The page that is then shown is returned with its number (counting from 0) and how
+// many pages there are. ok is false if key has no pages, or they have expired.
+func (p *PageStore) Turn(key string, offset int) (page Message, number int, count int, ok bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	entry, found := p.entries[key]
+	if !found || p.now().After(entry.expires) {
+		delete(p.entries, key)
+		return Message{}, 0, 0, false
+	}
+
+	entry.current = min(max(entry.current+offset, 0), len(entry.pages)-1)
+	entry.expires = p.now().Add(p.expiry)
+	return entry.pages[entry.current], entry.current, len(entry.pages), true
+}
+
+// Fallback is for a Sender that can't show controls to change pages. If msg has pages, they're
+// remembered for the conversation, and the first page is returned with a footer saying that the
+// next command, written after prefix, shows the next page. Otherwise msg is returned unchanged.
+func (p *PageStore) Fallback(conversation Conversation, msg Message, prefix string) Message {
+	if len(msg.Pages) < 2 {
+		msg.Pages = nil
+		return msg
+	}
+
+	key := conversationKey(conversation)
+	p.Add(key, msg.Pages)
+	page, number, count, _ := p.Turn(key, 0)
+	return labelFallback(page, number, count, prefix)
+}
+
+// Next returns the next page of the pages most recently sent to a conversation using Fallback.
+// Its footer is labelled like the first page, with prefix written before the next command.
+// ok is false if there are no pages, or they have expired.
+func (p *PageStore) Next(conversation Conversation, prefix string) (page Message, ok bool) {
+	page, number, count, ok := p.Turn(conversationKey(conversation), 1)
+	if !ok {
+		return Message{}, false
+	}
+	return labelFallback(page, number, count, prefix), true
+}
+
+// conversationKey is the key of pages sent to a conversation.
+func conversationKey(conversation Conversation) string {
+	return conversation.ServiceID + "/" + conversation.ConversationID
+}
+
+// labelFallback adds to the footer of a page which page it is, and how to see the next page
+// with the next command written after prefix.
+func labelFallback(page Message, number int, count int, prefix string) Message {
+	label := PageLabel(number, count)
+	if number < count-1 {
+		label += fmt.Sprintf(", use %s%s to see the next page", prefix, NextPageTrigger)
+	}
+
+	if page.Footer != "" {
+		label += ". " + page.Footer
+	}
+
+	page.Footer = label
+	page.Pages = nil
+	return page
+}
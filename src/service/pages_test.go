@@ -0,0 +1,90 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPaginate(t *testing.T) {
+	msg := Paginate([]Message{{Title: "One"}, {Title: "Two"}})
+	if msg.Title != "One" || len(msg.Pages) != 2 {
+		t.Errorf("Expected the first page with every page, got %+v", msg)
+	}
+
+	if msg := Paginate(nil); msg.Title != "" || msg.Pages != nil {
+		t.Errorf("Expected no pages to be an empty message")
+	}
+}
+
+func TestPageStoreTurn(t *testing.T) {
+	store := NewPageStore(time.Minute)
+	store.Add("key", []Message{{Title: "One"}, {Title: "Two"}, {Title: "Three"}})
+
+	for _, step := range []struct {
+		offset int
+		title  string
+		number int
+	}{{0, "One", 0}, {-1, "One", 0}, {1, "Two", 1}, {5, "Three", 2}, {-1, "Two", 1}} {
+		page, number, count, ok := store.Turn("key", step.offset)
+		if !ok || page.Title != step.title || number != step.number || count != 3 {
+			t.Errorf("Turning by %d: expected %s, got %s (%d of %d)", step.offset, step.title, page.Title, number, count)
+		}
+	}
+
+	if _, _, _, ok := store.Turn("missing", 1); ok {
+		t.Errorf("Expected a missing key to have no pages")
+	}
+}
+
+func TestPageStoreExpiry(t *testing.T) {
+	now := time.Date(2021, 5, 4, 0, 0, 0, 0, time.UTC)
+	store := NewPageStore(time.Minute)
+	store.now = func() time.Time { return now }
+	store.Add("key", []Message{{Title: "One"}, {Title: "Two"}})
+
+	now = now.Add(50 * time.Second)
+	if _, _, _, ok := store.Turn("key", 1); !ok {
+		t.Fatalf("Expected pages to be remembered")
+	}
+
+	// Turning resets the expiry.
+	now = now.Add(50 * time.Second)
+	if _, _, _, ok := store.Turn("key", 1); !ok {
+		t.Fatalf("Expected pages to be remembered after they were shown")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, _, _, ok := store.Turn("key", 1); ok {
+		t.Errorf("Expected pages to expire")
+	}
+
+	store.Add("other", nil)
+	if len(store.entries) != 1 {
+		t.Errorf("Expected expired pages to be forgotten")
+	}
+}
+
+func TestPageStoreFallback(t *testing.T) {
+	store := NewPageStore(time.Minute)
+	conversation := Conversation{ServiceID: "Demo", ConversationID: "1"}
+	other := Conversation{ServiceID: "Demo", ConversationID: "2"}
+
+	msg := store.Fallback(conversation, Message{Title: "Single", Pages: []Message{{Title: "Single"}}}, "!")
+	if msg.Footer != "" || msg.Pages != nil {
+		t.Errorf("Expected a single page to be sent as it is, got %+v", msg)
+	}
+
+	msg = store.Fallback(conversation, Paginate([]Message{{Title: "One"}, {Title: "Two"}}), "!")
+	if msg.Title != "One" || msg.Footer != "Page 1 of 2, use !next to see the next page" {
+		t.Errorf("Unexpected first page %+v", msg)
+	}
+
+	if _, ok := store.Next(other, "!"); ok {
+		t.Errorf("Expected pages to belong to a conversation")
+	}
+
+	page, ok := store.Next(conversation, "!")
+	if !ok || page.Title != "Two" || page.Footer != "Page 2 of 2" {
+		t.Errorf("Unexpected next page %+v", page)
+	}
+}
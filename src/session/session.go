@@ -0,0 +1,100 @@
+// Package session lets a command continue over several messages, by sending a user's next message
+// in a conversation to the command instead of matching it to a trigger.
+package session
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// KeyPrefix starts the user storage key of a user's session in a conversation, which is followed by the conversation's ID.
+const KeyPrefix = "session_"
+
+// DefaultTimeout is how long a session waits for the user's next message, unless Session.Expires is set.
+const DefaultTimeout = 5 * time.Minute
+
+// CancelWord ends a user's session when it's sent as a message.
+const CancelWord = "cancel"
+
+// A Session sends the next message of a user in a conversation to a command, so the command can ask
+// a follow-up question. A session lasts for one message, so a command starts another to keep going.
+type Session struct {
+	Trigger string    // Trigger of the command that receives the next message.
+	Step    string    // Where the command is up to, such as which question was asked.
+	State   string    // Anything else the command keeps between messages, such as JSON.
+	Expires time.Time // When the session ends if the user hasn't replied.
+}
+
+// A Handler receives the next message of a user that has a session, as text. It's like the Exec of a command,
+// where the last parameter sends a reply.
+type Handler func(Session, service.Conversation, service.User, string, *storage.Storage, func(service.Conversation, service.Message) error) error
+
+// key returns the user storage key of a session in conversation.
+func key(conversation service.Conversation) string {
+	return KeyPrefix + conversation.ConversationID
+}
+
+// Start starts session for user in conversation, replacing any session they have there.
+// When Expires isn't set, the session ends after DefaultTimeout.
+func Start(store storage.Storage, conversation service.Conversation, user service.User, session Session) error {
+	if session.Expires.IsZero() {
+		session.Expires = time.Now().Add(DefaultTimeout)
+	}
+
+	return storage.SetUserJSON(store, user, key(conversation), session)
+}
+
+// Get returns the session of user in conversation at now. ok is false if they don't have one, or it has expired.
+func Get(store storage.Storage, conversation service.Conversation, user service.User, now time.Time) (session Session, ok bool) {
+	value, found := store.GetUserValue(user, key(conversation))
+	text, isText := value.(string)
+	if !found || !isText || text == "" {
+		return Session{}, false
+	}
+
+	if err := json.Unmarshal([]byte(text), &session); err != nil || !now.Before(session.Expires) {
+		return Session{}, false
+	}
+	return session, true
+}
+
+// End ends the session of user in conversation.
+func End(store storage.Storage, conversation service.Conversation, user service.User) error {
+	return store.SetUserValue(user, key(conversation), "")
+}
+
+// Route sends text from user to the handler of their session in conversation, using lookup to find the handler
+// of a trigger. The session ends before the handler runs, which can start another. If text is CancelWord, the
+// session ends instead. Returns true if text was for a session, otherwise it should be treated as a normal message.
+func Route(
+	lookup func(trigger string) (Handler, bool),
+	conversation service.Conversation,
+	user service.User,
+	text string,
+	storage *storage.Storage,
+	sink func(service.Conversation, service.Message) error,
+) (bool, error) {
+	session, ok := Get(*storage, conversation, user, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	if err := End(*storage, conversation, user); err != nil {
+		return true, err
+	}
+
+	if strings.EqualFold(strings.TrimSpace(text), CancelWord) {
+		return true, sink(conversation, service.Message{Description: "Cancelled."})
+	}
+
+	// A session of a command that no longer exists is forgotten.
+	handler, ok := lookup(session.Trigger)
+	if !ok {
+		return false, nil
+	}
+	return true, handler(session, conversation, user, text, storage, sink)
+}
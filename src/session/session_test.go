@@ -0,0 +1,102 @@
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// echoHandler replies with the step of the session and the text it received.
+func echoHandler(trigger string) (Handler, bool) {
+	if trigger != "ask" {
+		return nil, false
+	}
+	return func(session Session, sender service.Conversation, user service.User, text string, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+		return sink(sender, service.Message{Title: session.Step, Description: text})
+	}, true
+}
+
+func TestStartAndGet(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+
+	conversation := service.Conversation{ServiceID: "demo", ConversationID: "0"}
+	other := service.Conversation{ServiceID: "demo", ConversationID: "1"}
+	user := service.User{Name: "1", ServiceID: "demo"}
+	now := time.Now()
+
+	if err := Start(store, conversation, user, Session{Trigger: "ask", Step: "name"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if session, ok := Get(store, conversation, user, now); !ok || session.Step != "name" {
+		t.Errorf("Expected the session to be found, got %+v", session)
+	}
+	if _, ok := Get(store, other, user, now); ok {
+		t.Errorf("Expected a session to only be in its conversation")
+	}
+	if _, ok := Get(store, conversation, user, now.Add(DefaultTimeout+time.Second)); ok {
+		t.Errorf("Expected the session to expire")
+	}
+
+	if err := End(store, conversation, user); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Get(store, conversation, user, now); ok {
+		t.Errorf("Expected the session to have ended")
+	}
+}
+
+func TestRoute(t *testing.T) {
+	tempStorage := storage.GetTempStorage()
+	var store storage.Storage = &tempStorage
+
+	conversation := service.Conversation{ServiceID: "demo", ConversationID: "0"}
+	user := service.User{Name: "1", ServiceID: "demo"}
+	replies := []service.Message{}
+	sink := func(_ service.Conversation, msg service.Message) error {
+		replies = append(replies, msg)
+		return nil
+	}
+
+	if handled, err := Route(echoHandler, conversation, user, "hello", &store, sink); handled || err != nil {
+		t.Fatalf("Expected a message without a session not to be handled, got %v, %v", handled, err)
+	}
+
+	if err := Start(store, conversation, user, Session{Trigger: "ask", Step: "name"}); err != nil {
+		t.Fatal(err)
+	}
+	if handled, err := Route(echoHandler, conversation, user, "alice", &store, sink); !handled || err != nil {
+		t.Fatalf("Expected the message to be handled, got %v, %v", handled, err)
+	}
+	if len(replies) != 1 || replies[0].Title != "name" || replies[0].Description != "alice" {
+		t.Errorf("Expected the handler to reply, got %+v", replies)
+	}
+
+	// A session lasts for one message.
+	if handled, _ := Route(echoHandler, conversation, user, "again", &store, sink); handled {
+		t.Errorf("Expected the session to end after a message")
+	}
+
+	if err := Start(store, conversation, user, Session{Trigger: "ask"}); err != nil {
+		t.Fatal(err)
+	}
+	if handled, err := Route(echoHandler, conversation, user, " Cancel ", &store, sink); !handled || err != nil {
+		t.Fatalf("Expected cancelling to be handled, got %v, %v", handled, err)
+	}
+	if len(replies) != 2 || replies[1].Description != "Cancelled." {
+		t.Errorf("Expected the session to be cancelled, got %+v", replies)
+	}
+	if _, ok := Get(store, conversation, user, time.Now()); ok {
+		t.Errorf("Expected a cancelled session to have ended")
+	}
+
+	if err := Start(store, conversation, user, Session{Trigger: "removed"}); err != nil {
+		t.Fatal(err)
+	}
+	if handled, _ := Route(echoHandler, conversation, user, "hello", &store, sink); handled {
+		t.Errorf("Expected the session of a missing command not to be handled")
+	}
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+// GetGuildJSON reads the value of key for guild, stored as JSON by SetGuildJSON, into out.
+// out is unchanged if nothing was stored.
+func GetGuildJSON(store Storage, guild service.Guild, key string, out interface{}) error {
+	value, ok := store.GetGuildValue(guild, key)
+	return decodeJSON(value, ok, out)
+}
+
+// SetGuildJSON stores value as JSON for key, for guild.
+func SetGuildJSON(store Storage, guild service.Guild, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return store.SetGuildValue(guild, key, string(data))
+}
+
+// GetUserJSON reads the value of key for user, stored as JSON by SetUserJSON, into out.
+// out is unchanged if nothing was stored.
+func GetUserJSON(store Storage, user service.User, key string, out interface{}) error {
+	value, ok := store.GetUserValue(user, key)
+	return decodeJSON(value, ok, out)
+}
+
+// SetUserJSON stores value as JSON for key, for user.
+func SetUserJSON(store Storage, user service.User, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return store.SetUserValue(user, key, string(data))
+}
+
+// GetGlobalJSON reads the global value of key, stored as JSON by SetGlobalJSON, into out.
+// out is unchanged if nothing was stored.
+func GetGlobalJSON(store Storage, key string, out interface{}) error {
+	value, ok := store.GetGlobalValue(key)
+	return decodeJSON(value, ok, out)
+}
+
+// SetGlobalJSON stores value as JSON for the global key.
+func SetGlobalJSON(store Storage, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return store.SetGlobalValue(key, string(data))
+}
+
+// decodeJSON reads a stored value, which is JSON written as a string, into out.
+// out is unchanged if nothing was stored, which is when ok is false.
+func decodeJSON(value interface{}, ok bool, out interface{}) error {
+	if !ok {
+		return nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("stored value is a %T, not JSON", value)
+	}
+	return json.Unmarshal([]byte(text), out)
+}
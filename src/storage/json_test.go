@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+func TestGuildJSON(t *testing.T) {
+	tempStorage := GetTempStorage()
+	guild := service.Guild{ServiceID: "0", GuildID: "0"}
+
+	values := []string{"unchanged"}
+	if err := GetGuildJSON(&tempStorage, guild, "key", &values); err != nil || len(values) != 1 {
+		t.Errorf("Expected values to be unchanged when nothing was stored, got %v %s", values, err)
+	}
+
+	if err := SetGuildJSON(&tempStorage, guild, "key", []string{"a", "b"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := GetGuildJSON(&tempStorage, guild, "key", &values); err != nil || len(values) != 2 || values[1] != "b" {
+		t.Errorf("Expected the stored values, got %v %s", values, err)
+	}
+}
+
+func TestUserAndGlobalJSON(t *testing.T) {
+	tempStorage := GetTempStorage()
+	user := service.User{ServiceID: "0", Name: "user"}
+
+	if err := SetUserJSON(&tempStorage, user, "key", map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]int{}
+	if err := GetUserJSON(&tempStorage, user, "key", &values); err != nil || values["a"] != 1 {
+		t.Errorf("Expected the stored user value, got %v %s", values, err)
+	}
+
+	if err := SetGlobalJSON(&tempStorage, "key", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	number := 0
+	if err := GetGlobalJSON(&tempStorage, "key", &number); err != nil || number != 3 {
+		t.Errorf("Expected the stored global value, got %d %s", number, err)
+	}
+}
+
+func TestGetJSONNotText(t *testing.T) {
+	tempStorage := GetTempStorage()
+	if err := tempStorage.SetGlobalValue("key", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	number := 0
+	if err := GetGlobalJSON(&tempStorage, "key", &number); err == nil {
+		t.Errorf("Expected an error for a value that isn't JSON")
+	}
+}
@@ -0,0 +1,351 @@
+package tag
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// AddTrigger is a trigger to use for a command that adds a tag.
+const AddTrigger = "addtag"
+
+// EditTrigger is a trigger to use for a command that changes the response of a tag.
+const EditTrigger = "edittag"
+
+// DeleteTrigger is a trigger to use for a command that deletes a tag.
+const DeleteTrigger = "deletetag"
+
+// ListTrigger is a trigger to use for a command that lists a guild's tags.
+const ListTrigger = "tags"
+
+// SlashTrigger is a trigger to use for a command that sets whether a tag is also a slash command.
+const SlashTrigger = "slashtag"
+
+// Triggers are the triggers of the commands returned by Commands.
+var Triggers = []string{AddTrigger, EditTrigger, DeleteTrigger, ListTrigger, SlashTrigger}
+
+// tagsPerPage is how many tags are listed on each page.
+const tagsPerPage = 10
+
+// helpLength is how many characters of a tag's response are used as its help, which slash commands limit to 100.
+const helpLength = 100
+
+// Tagger has the commands that let admins add tags to their guild, and the tags of each guild as commands.
+type Tagger struct {
+	OnChange func(service.Guild) // Called after a slash command of a guild changes, such as to update the guild's slash commands.
+	triggers map[string]bool     // Triggers of the bot's commands, which tags can't use.
+	mutex    sync.Mutex          // Lock when reading and then writing tags, or when using triggers.
+}
+
+// SetCommands replaces the commands whose triggers can't be used as the names of tags.
+// Existing tags aren't checked, as the bot's commands are matched before a guild's.
+func (t *Tagger) SetCommands(commands []command.Command) {
+	triggers := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		triggers[cmd.Trigger] = true
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.triggers = triggers
+}
+
+// Commands returns commands that let admins add, edit, delete and list tags.
+func (t *Tagger) Commands() []command.Command {
+	tagParameter := command.Parameter{Type: "string", Name: "tag", Description: "A name, then | and the response"}
+	return []command.Command{
+		{
+			Trigger:    AddTrigger,
+			Parameters: []command.Parameter{tagParameter},
+			Exec:       t.add,
+			Help: "Add a command to this server that replies with a response. In the response, {user} mentions who used it, " +
+				"{args} is what they wrote after it, {1} is its first word and {choice:a|b} is a or b at random.",
+			HelpInput: "<name> | <response>",
+		},
+		{
+			Trigger:    EditTrigger,
+			Parameters: []command.Parameter{tagParameter},
+			Exec:       t.edit,
+			Help:       "Change the response of a tag.",
+			HelpInput:  "<name> | <response>",
+		},
+		{
+			Trigger: DeleteTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "name", Description: "Name of the tag to delete"},
+			},
+			Exec:      t.delete,
+			Help:      "Delete a tag.",
+			HelpInput: "<name>",
+		},
+		{
+			Trigger: ListTrigger,
+			Exec:    t.list,
+			Help:    "List the tags of this server.",
+		},
+		{
+			Trigger: SlashTrigger,
+			Parameters: []command.Parameter{
+				{Type: "string", Name: "name", Description: "Name of the tag"},
+				{Type: "bool", Name: "slash", Description: "Whether the tag is also a slash command"},
+			},
+			Exec:      t.setSlash,
+			Help:      "Set whether a tag is also a slash command.",
+			HelpInput: "<name> <true|false>",
+		},
+	}
+}
+
+// GuildCommands returns a command for each tag of guild.
+func (t *Tagger) GuildCommands(storage *storage.Storage, guild service.Guild) []command.Command {
+	return t.commands(storage, guild, false)
+}
+
+// SlashCommands returns a command for each tag of guild that is also a slash command.
+func (t *Tagger) SlashCommands(storage *storage.Storage, guild service.Guild) []command.Command {
+	return t.commands(storage, guild, true)
+}
+
+// commands returns a command for each tag of guild, or only those that are slash commands.
+func (t *Tagger) commands(storage *storage.Storage, guild service.Guild, slash bool) []command.Command {
+	// This doesn't lock, as it's used by OnChange while a tag is being changed.
+	tags, err := Tags(*storage, guild)
+	if err != nil {
+		return nil
+	}
+
+	commands := []command.Command{}
+	for _, tag := range tags {
+		if tag.Slash || !slash {
+			commands = append(commands, tagCommand(tag))
+		}
+	}
+	return commands
+}
+
+// tagCommand returns a command that replies with the response of tag.
+func tagCommand(tag Tag) command.Command {
+	cmd := command.Command{
+		Trigger: tag.Name,
+		Help:    truncate(tag.Response, helpLength),
+		Exec: func(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+			args := ""
+			if len(msg) > 0 {
+				args, _ = msg[0].(string)
+			}
+			return sink(sender, service.Message{Description: Render(tag.Response, user, args)})
+		},
+	}
+
+	if usesArgs(tag.Response) {
+		cmd.Parameters = []command.Parameter{{Type: "string", Name: "text", Description: "Text used by the tag"}}
+		cmd.HelpInput = "<text>"
+	}
+	return cmd
+}
+
+// parseTag returns the name and response of a tag written as a name, then | and the response.
+func parseTag(text string) (string, string, error) {
+	name, response, _ := strings.Cut(text, "|")
+	name, response = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(response)
+	if err := validName(name); err != nil {
+		return "", "", err
+	}
+
+	if response == "" {
+		return "", "", fmt.Errorf("write the response after |")
+	}
+	if len([]rune(response)) > MaxResponseLength {
+		return "", "", fmt.Errorf("a response can't be longer than %d characters", MaxResponseLength)
+	}
+	return name, response, nil
+}
+
+// add adds a tag to the guild of sender.
+func (t *Tagger) add(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	return t.save(sender, user, msg[0].(string), false, storage, sink)
+}
+
+// edit changes the response of a tag of the guild of sender.
+func (t *Tagger) edit(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	return t.save(sender, user, msg[0].(string), true, storage, sink)
+}
+
+// save adds a tag written as text to the guild of sender, or when editing, changes the response of an existing tag.
+func (t *Tagger) save(sender service.Conversation, user service.User, text string, editing bool, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can change tags."})
+	}
+
+	if sender.GuildID == "" {
+		return sink(sender, service.Message{Title: "Error", Description: "Tags can only be changed in a server."})
+	}
+
+	name, response, err := parseTag(text)
+	if err != nil {
+		return sink(sender, service.Message{Title: "Error", Description: err.Error()})
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.triggers[name] {
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s is already a command.", name)})
+	}
+
+	tags, err := Tags(*storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	index := find(tags, name)
+	switch {
+	case editing && index == -1:
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("There's no tag named %s, add it with %s.", name, AddTrigger)})
+	case !editing && index != -1:
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("%s is already a tag, change it with %s.", name, EditTrigger)})
+	case !editing && len(tags) >= MaxTags:
+		return sink(sender, service.Message{Description: fmt.Sprintf("A server can't have more than %d tags.", MaxTags)})
+	case editing:
+		tags[index].Response, tags[index].Author = response, user.Name
+	default:
+		tags = append(tags, Tag{Name: name, Response: response, Author: user.Name})
+		index = len(tags) - 1
+	}
+
+	slash := tags[index].Slash
+	if err := SetTags(*storage, sender.Guild(), tags); err != nil {
+		return err
+	}
+
+	if slash {
+		t.changed(sender.Guild())
+	}
+	return sink(sender, service.Message{Title: fmt.Sprintf("Saved %s", name), Description: response})
+}
+
+// delete deletes a tag of the guild of sender.
+func (t *Tagger) delete(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can change tags."})
+	}
+
+	if sender.GuildID == "" {
+		return sink(sender, service.Message{Title: "Error", Description: "Tags can only be changed in a server."})
+	}
+
+	name := strings.ToLower(strings.TrimSpace(msg[0].(string)))
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tags, err := Tags(*storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	index := find(tags, name)
+	if index == -1 {
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("There's no tag named %s.", name)})
+	}
+
+	slash := tags[index].Slash
+	tags = append(tags[:index], tags[index+1:]...)
+	if err := SetTags(*storage, sender.Guild(), tags); err != nil {
+		return err
+	}
+
+	if slash {
+		t.changed(sender.Guild())
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("Deleted %s.", name)})
+}
+
+// setSlash sets whether a tag of the guild of sender is also a slash command.
+func (t *Tagger) setSlash(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	if !sender.Admin {
+		return sink(sender, service.Message{Description: "Only admins can change tags."})
+	}
+
+	if sender.GuildID == "" {
+		return sink(sender, service.Message{Title: "Error", Description: "Tags can only be changed in a server."})
+	}
+
+	name, slash := strings.ToLower(msg[0].(string)), msg[1].(bool)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	tags, err := Tags(*storage, sender.Guild())
+	if err != nil {
+		return err
+	}
+
+	index := find(tags, name)
+	if index == -1 {
+		return sink(sender, service.Message{Title: "Error", Description: fmt.Sprintf("There's no tag named %s.", name)})
+	}
+
+	tags[index].Slash = slash
+	if err := SetTags(*storage, sender.Guild(), tags); err != nil {
+		return err
+	}
+	t.changed(sender.Guild())
+
+	if slash {
+		return sink(sender, service.Message{Description: fmt.Sprintf("%s is now also a slash command.", name)})
+	}
+	return sink(sender, service.Message{Description: fmt.Sprintf("%s is no longer a slash command.", name)})
+}
+
+// list lists the tags of the guild of sender.
+func (t *Tagger) list(sender service.Conversation, user service.User, msg []interface{}, storage *storage.Storage, sink func(service.Conversation, service.Message) error) error {
+	t.mutex.Lock()
+	tags, err := Tags(*storage, sender.Guild())
+	t.mutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return sink(sender, service.Message{Title: "Tags", Description: fmt.Sprintf("This server has no tags, admins can add them with %s.", AddTrigger)})
+	}
+
+	pages := []service.Message{}
+	for start := 0; start < len(tags); start += tagsPerPage {
+		page := service.Message{Title: "Tags", Description: fmt.Sprintf("%d tags.", len(tags))}
+		for _, tag := range tags[start:min(start+tagsPerPage, len(tags))] {
+			field := tag.Name
+			if tag.Slash {
+				field += " (slash command)"
+			}
+			page.Fields = append(page.Fields, service.MessageField{Field: field, Value: truncate(tag.Response, 200)})
+		}
+		pages = append(pages, page)
+	}
+
+	if len(pages) == 1 {
+		return sink(sender, pages[0])
+	}
+	return sink(sender, service.Paginate(pages))
+}
+
+// changed calls OnChange, if it's set, after a slash command of guild changes.
+func (t *Tagger) changed(guild service.Guild) {
+	if t.OnChange != nil {
+		t.OnChange(guild)
+	}
+}
+
+// truncate shortens text to at most limit characters.
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
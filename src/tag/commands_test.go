@@ -0,0 +1,109 @@
+package tag
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/command"
+	"github.com/BKrajancic/boby/m/v2/src/command/commandtest"
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// testTagger returns a Tagger whose tags can't use the trigger "define", storage for its commands,
+// and the guilds it reported as changed.
+func testTagger() (*Tagger, *storage.Storage, *[]service.Guild) {
+	changed := &[]service.Guild{}
+	tagger := &Tagger{OnChange: func(guild service.Guild) { *changed = append(*changed, guild) }}
+	tagger.SetCommands([]command.Command{{Trigger: "define"}})
+	return tagger, commandtest.Storage(), changed
+}
+
+func TestTags(t *testing.T) {
+	tagger, store, _ := testTagger()
+	admin := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+	member := admin
+	member.Admin = false
+	otherGuild := admin
+	otherGuild.GuildID = "1"
+
+	if reply := commandtest.Reply(t, tagger.Commands(), store, member, commandtest.User, AddTrigger, "rules | Be nice"); !strings.Contains(reply.Description, "Only admins") {
+		t.Errorf("Expected only admins to add tags, got %+v", reply)
+	}
+
+	direct := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "2", Admin: true}
+	for _, trigger := range []string{AddTrigger, EditTrigger, DeleteTrigger} {
+		if reply := commandtest.Reply(t, tagger.Commands(), store, direct, commandtest.User, trigger, "rules | Be nice"); !strings.Contains(reply.Description, "only be changed in a server") {
+			t.Errorf("Expected %s to be refused in direct messages, got %+v", trigger, reply)
+		}
+	}
+	if reply := commandtest.Reply(t, tagger.Commands(), store, direct, commandtest.User, SlashTrigger, "rules", true); !strings.Contains(reply.Description, "only be changed in a server") {
+		t.Errorf("Expected %s to be refused in direct messages, got %+v", SlashTrigger, reply)
+	}
+
+	for _, text := range []string{"define | A word", "no spaces | Be nice", "rules |", "rules"} {
+		if reply := commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, text); reply.Title != "Error" {
+			t.Errorf("Expected %q to be refused, got %+v", text, reply)
+		}
+	}
+
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, "Rules | Be nice")
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, "greet | Hello {1}, from {user}")
+	if reply := commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, "rules | Be kind"); reply.Title != "Error" {
+		t.Errorf("Expected a tag not to be added twice, got %+v", reply)
+	}
+	if reply := commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, EditTrigger, "missing | Be kind"); reply.Title != "Error" {
+		t.Errorf("Expected a missing tag not to be edited, got %+v", reply)
+	}
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, EditTrigger, "rules | Be kind")
+
+	guildCommands := tagger.GuildCommands(store, admin.Guild())
+	if len(guildCommands) != 2 || len(tagger.GuildCommands(store, otherGuild.Guild())) != 0 {
+		t.Fatalf("Expected only the guild to have its tags, got %+v", guildCommands)
+	}
+
+	if reply := commandtest.Reply(t, guildCommands, store, member, commandtest.User, "rules"); reply.Description != "Be kind" {
+		t.Errorf("Expected the edited response, got %+v", reply)
+	}
+	if reply := commandtest.Reply(t, guildCommands, store, member, commandtest.User, "greet", "alice"); reply.Description != "Hello alice, from <@Test_User>" {
+		t.Errorf("Expected the response to be rendered, got %+v", reply)
+	}
+	if len(guildCommands[0].Parameters) != 1 || len(guildCommands[1].Parameters) != 0 {
+		t.Errorf("Expected only a tag that uses text to take it, got %+v", guildCommands)
+	}
+
+	if reply := commandtest.Reply(t, tagger.Commands(), store, member, commandtest.User, ListTrigger); len(reply.Fields) != 2 || reply.Fields[0].Field != "greet" {
+		t.Errorf("Expected the tags to be listed by name, got %+v", reply)
+	}
+
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, DeleteTrigger, "RULES")
+	if reply := commandtest.Reply(t, tagger.Commands(), store, member, commandtest.User, ListTrigger); len(reply.Fields) != 1 {
+		t.Errorf("Expected the tag to be deleted, got %+v", reply)
+	}
+}
+
+func TestSlashTags(t *testing.T) {
+	tagger, store, changed := testTagger()
+	admin := service.Conversation{ServiceID: demoservice.ServiceID, ConversationID: "0", GuildID: "0", Admin: true}
+
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, "rules | Be nice")
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, AddTrigger, "faq | Read the FAQ")
+	if len(*changed) != 0 || len(tagger.SlashCommands(store, admin.Guild())) != 0 {
+		t.Fatalf("Expected tags not to be slash commands unless they're set to be")
+	}
+
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, SlashTrigger, "rules", true)
+	slashCommands := tagger.SlashCommands(store, admin.Guild())
+	if len(slashCommands) != 1 || slashCommands[0].Trigger != "rules" || slashCommands[0].Help != "Be nice" {
+		t.Errorf("Expected the tag to be a slash command, got %+v", slashCommands)
+	}
+
+	// Only changes to slash commands are reported.
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, EditTrigger, "faq | Read the FAQ first")
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, EditTrigger, "rules | Be kind")
+	commandtest.Reply(t, tagger.Commands(), store, admin, commandtest.User, DeleteTrigger, "rules")
+	if len(*changed) != 3 || (*changed)[0] != admin.Guild() {
+		t.Errorf("Expected each change to a slash command to be reported, got %v", *changed)
+	}
+}
@@ -0,0 +1,111 @@
+// Package tag lets admins add commands to their guild while the bot runs, which reply with a template.
+package tag
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+	"github.com/BKrajancic/boby/m/v2/src/storage"
+)
+
+// TagsKey is the guild storage key of a guild's tags, stored as JSON.
+const TagsKey = "tags"
+
+// MaxTags is how many tags a guild can have.
+const MaxTags = 50
+
+// MaxResponseLength is how many characters the response of a tag can have.
+const MaxResponseLength = 2000
+
+// A Tag is a command added by a guild's admins, which replies with Response.
+type Tag struct {
+	Name     string // Trigger of the tag.
+	Response string // Template of the reply, see Render.
+	Slash    bool   // Whether the tag is also a slash command.
+	Author   string // Name of the user that last changed the tag.
+}
+
+// namePattern matches names that can also be used as slash commands.
+var namePattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// placeholderPattern matches a placeholder of a template, such as {user}.
+var placeholderPattern = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// choicePrefix starts a placeholder that is replaced by one of its options, which are separated by |.
+const choicePrefix = "choice:"
+
+// validName returns an error if name can't be the name of a tag.
+func validName(name string) error {
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("a tag's name must be 1 to 32 lowercase letters, numbers, - or _")
+	}
+	return nil
+}
+
+// Render returns response with its placeholders replaced, for user who wrote args after the tag's trigger.
+//   - {user} mentions user.
+//   - {args} is args, and {1}, {2} and so on are its words.
+//   - {choice:a|b|c} is one of a, b or c at random.
+//
+// Other text in braces is left as it is.
+func Render(response string, user service.User, args string) string {
+	words := strings.Fields(args)
+	return placeholderPattern.ReplaceAllStringFunc(response, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if name == "user" {
+			return fmt.Sprintf("<@%s>", user.Name)
+		}
+
+		if name == "args" {
+			return strings.TrimSpace(args)
+		}
+
+		if options, ok := strings.CutPrefix(name, choicePrefix); ok {
+			choices := strings.Split(options, "|")
+			return choices[rand.Intn(len(choices))]
+		}
+
+		if index, err := strconv.Atoi(name); err == nil && index > 0 {
+			if index > len(words) {
+				return ""
+			}
+			return words[index-1]
+		}
+		return placeholder
+	})
+}
+
+// usesArgs returns true if response has a placeholder for what's written after the tag's trigger.
+func usesArgs(response string) bool {
+	for _, match := range placeholderPattern.FindAllStringSubmatch(response, -1) {
+		if index, err := strconv.Atoi(match[1]); match[1] == "args" || (err == nil && index > 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the index of the tag with name, or -1 if there isn't one.
+func find(tags []Tag, name string) int {
+	return slices.IndexFunc(tags, func(tag Tag) bool { return tag.Name == name })
+}
+
+// Tags returns the tags of guild, sorted by name.
+func Tags(store storage.Storage, guild service.Guild) ([]Tag, error) {
+	tags := []Tag{}
+	if err := storage.GetGuildJSON(store, guild, TagsKey, &tags); err != nil {
+		return nil, fmt.Errorf("tags of %s: %w", guild.GuildID, err)
+	}
+	return tags, nil
+}
+
+// SetTags replaces the tags of guild.
+func SetTags(store storage.Storage, guild service.Guild, tags []Tag) error {
+	slices.SortFunc(tags, func(a Tag, b Tag) int { return strings.Compare(a.Name, b.Name) })
+	return storage.SetGuildJSON(store, guild, TagsKey, tags)
+}
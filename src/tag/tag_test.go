@@ -0,0 +1,49 @@
+package tag
+
+import (
+	"testing"
+
+	"github.com/BKrajancic/boby/m/v2/src/service"
+)
+
+func TestRender(t *testing.T) {
+	user := service.User{Name: "1", ServiceID: "demo"}
+	for _, test := range []struct {
+		response string
+		args     string
+		expected string
+	}{
+		{"Welcome {user}!", "", "Welcome <@1>!"},
+		{"You said: {args}", " hello there ", "You said: hello there"},
+		{"{2} {1} {3}", "hello there", "there hello "},
+		{"{choice:yes}", "", "yes"},
+		{"{unknown} {0} {}", "a", "{unknown} {0} {}"},
+	} {
+		if rendered := Render(test.response, user, test.args); rendered != test.expected {
+			t.Errorf("Expected %q with %q to be %q, got %q", test.response, test.args, test.expected, rendered)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		seen[Render("{choice:heads|tails}", user, "")] = true
+	}
+	if len(seen) != 2 || !seen["heads"] || !seen["tails"] {
+		t.Errorf("Expected either choice, got %v", seen)
+	}
+}
+
+func TestUsesArgs(t *testing.T) {
+	for response, expected := range map[string]bool{
+		"Hello {user}":  false,
+		"{choice:a|b}":  false,
+		"Hello {args}":  true,
+		"Hello {1}":     true,
+		"Hello {0}":     false,
+		"Hello, world!": false,
+	} {
+		if usesArgs(response) != expected {
+			t.Errorf("Expected usesArgs(%q) to be %v", response, expected)
+		}
+	}
+}
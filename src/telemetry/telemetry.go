@@ -0,0 +1,201 @@
+// Package telemetry sets up OpenTelemetry tracing as configured by config.TelemetryConfig.
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/BKrajancic/boby/m/v2/src/config"
+)
+
+// redacted replaces the value of a redacted span attribute.
+const redacted = "[REDACTED]"
+
+// InitTracer sets up OpenTelemetry tracing using an exporter chosen by cfg.
+// If the exporter is "none", no tracer provider is installed and the returned
+// shutdown function does nothing.
+func InitTracer(ctx context.Context, serviceName string, cfg config.TelemetryConfig) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	exporter, closeExporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return noop, err
+	}
+
+	if exporter == nil {
+		return noop, nil
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+		),
+	)
+	if err != nil {
+		return noop, err
+	}
+
+	options := append(providerOptions(exporter, cfg), trace.WithResource(res))
+	provider := trace.NewTracerProvider(options...)
+	otel.SetTracerProvider(provider)
+
+	// Set global propagator to TraceContext + Baggage
+	otel.SetTextMapPropagator(
+		propagation.NewCompositeTextMapPropagator(
+			propagation.TraceContext{},
+			propagation.Baggage{},
+		),
+	)
+
+	shutdown := func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeErr := closeExporter(); err == nil {
+			err = closeErr
+		}
+		return err
+	}
+
+	return shutdown, nil
+}
+
+// providerOptions returns the options of a tracer provider that samples as configured by cfg,
+// and redacts spans before sending them to exporter.
+func providerOptions(exporter trace.SpanExporter, cfg config.TelemetryConfig) []trace.TracerProviderOption {
+	options := []trace.TracerProviderOption{
+		trace.WithBatcher(newRedactingExporter(exporter, cfg.ResolvedRedactAttributes())),
+	}
+
+	if cfg.SampleRatio > 0 {
+		options = append(options, trace.WithSampler(trace.ParentBased(trace.TraceIDRatioBased(cfg.SampleRatio))))
+	}
+	return options
+}
+
+// newExporter makes the exporter described by cfg.
+// A nil exporter is returned if telemetry is disabled. The returned function closes
+// any resources the exporter writes to, and must be called after the exporter shuts down.
+func newExporter(ctx context.Context, cfg config.TelemetryConfig) (trace.SpanExporter, func() error, error) {
+	noop := func() error { return nil }
+
+	switch cfg.ResolvedExporter() {
+	case config.ExporterNone:
+		return nil, noop, nil
+
+	case config.ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, noop, err
+
+	case config.ExporterFile:
+		if cfg.Filepath == "" {
+			return nil, noop, fmt.Errorf("telemetry exporter %q requires a Filepath", config.ExporterFile)
+		}
+
+		file, err := os.OpenFile(cfg.Filepath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, noop, err
+		}
+
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			file.Close()
+			return nil, noop, err
+		}
+		return exporter, file.Close, nil
+
+	case config.ExporterOTLPGRPC:
+		options := []otlptracegrpc.Option{}
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+		}
+		if cfg.ResolvedInsecure() {
+			options = append(options,
+				otlptracegrpc.WithInsecure(),
+				otlptracegrpc.WithDialOption(
+					grpc.WithTransportCredentials(insecure.NewCredentials()),
+				),
+			)
+		}
+		exporter, err := otlptracegrpc.New(ctx, options...)
+		return exporter, noop, err
+
+	case config.ExporterOTLPHTTP:
+		options := []otlptracehttp.Option{}
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		if cfg.ResolvedInsecure() {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		return exporter, noop, err
+	}
+
+	return nil, noop, fmt.Errorf("unknown telemetry exporter %q", cfg.Exporter)
+}
+
+// redactingExporter replaces the values of some attributes before passing spans on.
+type redactingExporter struct {
+	trace.SpanExporter
+	keys map[attribute.Key]bool
+}
+
+// newRedactingExporter wraps exporter so the values of attributes in keys are never exported.
+func newRedactingExporter(exporter trace.SpanExporter, keys []string) trace.SpanExporter {
+	if len(keys) == 0 {
+		return exporter
+	}
+
+	redactingExporter := redactingExporter{
+		SpanExporter: exporter,
+		keys:         make(map[attribute.Key]bool),
+	}
+	for _, key := range keys {
+		redactingExporter.keys[attribute.Key(key)] = true
+	}
+	return redactingExporter
+}
+
+// ExportSpans redacts attributes of spans, then exports them.
+func (r redactingExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	redactedSpans := make([]trace.ReadOnlySpan, len(spans))
+	for i, span := range spans {
+		redactedSpans[i] = redactedSpan{ReadOnlySpan: span, attributes: r.redact(span.Attributes())}
+	}
+	return r.SpanExporter.ExportSpans(ctx, redactedSpans)
+}
+
+// redact returns a copy of attributes with redacted values.
+func (r redactingExporter) redact(attributes []attribute.KeyValue) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(attributes))
+	for i, attr := range attributes {
+		if r.keys[attr.Key] {
+			attr = attr.Key.String(redacted)
+		}
+		out[i] = attr
+	}
+	return out
+}
+
+// redactedSpan is a span with its attributes replaced.
+type redactedSpan struct {
+	trace.ReadOnlySpan
+	attributes []attribute.KeyValue
+}
+
+// Attributes returns the redacted attributes of a span.
+func (r redactedSpan) Attributes() []attribute.KeyValue {
+	return r.attributes
+}
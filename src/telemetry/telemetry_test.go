@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/BKrajancic/boby/m/v2/src/config"
+)
+
+func TestNewExporter(t *testing.T) {
+	ctx := context.Background()
+	secure := false
+	filepath := path.Join(t.TempDir(), "spans.json")
+
+	for _, test := range []struct {
+		cfg      config.TelemetryConfig
+		exporter bool
+		err      bool
+	}{
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterNone}},
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterStdout}, exporter: true},
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterFile}, err: true},
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterFile, Filepath: filepath}, exporter: true},
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterOTLPGRPC, Endpoint: "http://localhost:4317"}, exporter: true},
+		{cfg: config.TelemetryConfig{Exporter: config.ExporterOTLPHTTP, Endpoint: "https://localhost:4318", Insecure: &secure}, exporter: true},
+		{cfg: config.TelemetryConfig{Exporter: "zipkin"}, err: true},
+	} {
+		exporter, closeExporter, err := newExporter(ctx, test.cfg)
+		if (err != nil) != test.err || (exporter != nil) != test.exporter {
+			t.Errorf("Unexpected exporter for %+v: %v, %v", test.cfg, exporter, err)
+		}
+
+		if exporter != nil {
+			if err := exporter.Shutdown(ctx); err != nil {
+				t.Errorf("Unable to shut down the exporter for %+v: %v", test.cfg, err)
+			}
+		}
+		if err := closeExporter(); err != nil {
+			t.Error(err)
+		}
+	}
+
+	if _, err := os.Stat(filepath); err != nil {
+		t.Errorf("Expected the file exporter to create its file, got %v", err)
+	}
+}
+
+func TestRedactingExporter(t *testing.T) {
+	recorder := tracetest.NewInMemoryExporter()
+	provider := trace.NewTracerProvider(trace.WithSyncer(newRedactingExporter(recorder, []string{"msg.title"})))
+
+	_, span := provider.Tracer("test").Start(context.Background(), "SendMessage")
+	span.SetAttributes(attribute.String("msg.title", "secret"), attribute.String("command", "define"))
+	span.End()
+
+	spans := recorder.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("Expected a span to be exported, got %d", len(spans))
+	}
+
+	attributes := map[string]string{}
+	for _, attr := range spans[0].Attributes {
+		attributes[string(attr.Key)] = attr.Value.AsString()
+	}
+	if attributes["msg.title"] != redacted || attributes["command"] != "define" {
+		t.Errorf("Expected only msg.title to be redacted, got %v", attributes)
+	}
+
+	if exporter := newRedactingExporter(recorder, nil); exporter != recorder {
+		t.Errorf("Expected an exporter without keys to be used as it is")
+	}
+}
+
+func TestSampling(t *testing.T) {
+	for _, test := range []struct {
+		ratio    float64
+		expected int
+	}{{0, 20}, {1, 20}, {0.000000001, 0}} {
+		recorder := tracetest.NewInMemoryExporter()
+		provider := trace.NewTracerProvider(providerOptions(recorder, config.TelemetryConfig{SampleRatio: test.ratio})...)
+		for i := 0; i < 20; i++ {
+			_, span := provider.Tracer("test").Start(context.Background(), "onMessage")
+			span.End()
+		}
+
+		if err := provider.ForceFlush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if spans := recorder.GetSpans(); len(spans) != test.expected {
+			t.Errorf("Expected %d spans with a sample ratio of %v, got %d", test.expected, test.ratio, len(spans))
+		}
+	}
+}
+
+func TestResolvedInsecure(t *testing.T) {
+	secure, insecure := false, true
+	for _, test := range []struct {
+		insecure *bool
+		expected bool
+	}{{nil, true}, {&insecure, true}, {&secure, false}} {
+		if resolved := (config.TelemetryConfig{Insecure: test.insecure}).ResolvedInsecure(); resolved != test.expected {
+			t.Errorf("Expected %v, got %v", test.expected, resolved)
+		}
+	}
+}
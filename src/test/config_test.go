@@ -4,18 +4,35 @@ package test
 
 import (
 	"encoding/json"
+	"flag"
 	"log"
 	"os"
 	"path"
 	"testing"
 
 	"github.com/BKrajancic/boby/m/v2/src/config"
+	"github.com/BKrajancic/boby/m/v2/src/fixture"
 	"github.com/BKrajancic/boby/m/v2/src/service"
 	"github.com/BKrajancic/boby/m/v2/src/service/demoservice"
 	"github.com/BKrajancic/boby/m/v2/src/storage"
 	"github.com/google/go-cmp/cmp"
 )
 
+// fixtureMode is "record" to save responses to a "fixtures" folder in the configuration directory,
+// or "replay" to use those responses instead of accessing websites.
+var fixtureMode = flag.String("fixtures", "", "\"record\" or \"replay\" responses using <dir>/fixtures")
+
+// getters returns what commands use to access websites, depending on fixtureMode.
+func getters(configDir string) (config.Getters, error) {
+	mode, err := fixture.ParseMode(*fixtureMode)
+	if err != nil || mode == fixture.Live {
+		return config.DefaultGetters, err
+	}
+
+	store := fixture.Store{Dir: path.Join(configDir, "fixtures"), Mode: mode}
+	return config.Getters{HTML: store.HTMLGetter, JSON: store.JSONGetter}, nil
+}
+
 type ConfigTest struct {
 	Input  string
 	Expect [][]service.Message
@@ -74,7 +91,12 @@ func TestConfig(t *testing.T) {
 			t.Log("Configuration file was used for this test.")
 			tempStorage := storage.GetTempStorage()
 			var _storage storage.Storage = &tempStorage
-			commands, err := config.ConfiguredBot(configDir, &_storage)
+			getters, err := getters(configDir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			commands, err := config.ConfiguredBotWithGetters(configDir, &_storage, getters)
 			if err != nil {
 				t.Fail()
 			}
@@ -155,7 +177,9 @@ func TestConfig(t *testing.T) {
 			"program (relative to " + here + ")." +
 			"If a file '" + configTests + "' is present, it can be " +
 			"used to ensure that all the configuration files are valid, " +
-			"and produce the expected output.")
+			"and produce the expected output. Add '-fixtures=record' before " +
+			"<dir> to save responses from websites, and '-fixtures=replay' " +
+			"to test offline using saved responses.")
 
 		// TODO add information on the format of config_tests.
 
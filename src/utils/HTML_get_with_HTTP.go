@@ -4,15 +4,28 @@ package utils
 
 import (
 	"io"
+	"log/slog"
 	"net/http"
+	"time"
 )
 
 // HTMLGetWithHTTP retrieves a HTML page from a URL.
 func HTMLGetWithHTTP(url string) (redirect string, out io.ReadCloser, err error) {
+	start := time.Now()
 	resp, err := http.Get(url)
+	logUpstream(url, resp, err, time.Since(start))
 	if err == nil {
 		out = resp.Body
 		redirect = resp.Request.URL.String()
 	}
 	return redirect, out, err
 }
+
+// logUpstream logs the outcome of a request made to a website or API.
+func logUpstream(url string, resp *http.Response, err error, duration time.Duration) {
+	if err != nil {
+		slog.Warn("upstream request failed", "url", url, "duration", duration, "error", err)
+		return
+	}
+	slog.Info("upstream request", "url", url, "status", resp.StatusCode, "duration", duration)
+}
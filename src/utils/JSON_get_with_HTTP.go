@@ -5,11 +5,14 @@ package utils
 import (
 	"io"
 	"net/http"
+	"time"
 )
 
 // JSONGetWithHTTP retrieves a JSON from a URL.
 func JSONGetWithHTTP(url string) (out io.ReadCloser, err error) {
+	start := time.Now()
 	resp, err := http.Get(url)
+	logUpstream(url, resp, err, time.Since(start))
 	if err == nil {
 		out = resp.Body
 	}